@@ -0,0 +1,619 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/cu-library/ezproxy-config-lint/internal/linter"
+)
+
+// commands returns the names of the subcommands.
+func commands() []string {
+	return []string{"snapshot", "check", "explain", "grep", "affects", "match", "starting-points", "https-report", "source-report", "drift-report", "gate", "revert"}
+}
+
+// recordSnapshot writes the issues the linter found to the snapshot file.
+func recordSnapshot(l *linter.Linter, path string, exitCodeError int) {
+	if err := linter.WriteSnapshot(path, l.Report.Findings); err != nil {
+		log.Printf("Error writing snapshot: %v", err)
+		exit(exitCodeError)
+	}
+	if len(l.Report.Findings) == 1 {
+		fmt.Printf("Recorded 1 issue in %v.\n", path)
+	} else {
+		fmt.Printf("Recorded %v issues in %v.\n", len(l.Report.Findings), path)
+	}
+}
+
+// checkSnapshot compares the issues the linter found to the snapshot file,
+// printing the new issues and the issues which were fixed.
+// If showSuppressed is true, the issues which are not reported because they are in the snapshot are printed too.
+// If there are new issues, the program exits with exitCodeIssues.
+func checkSnapshot(l *linter.Linter, path string, showSuppressed bool, exitCodeIssues, exitCodeError int) {
+	s, err := linter.ReadSnapshot(path)
+	if err != nil {
+		log.Printf("Error reading snapshot: %v", err)
+		exit(exitCodeError)
+	}
+	added, suppressed, fixed := linter.CompareSnapshot(s, l.Report.Findings)
+	for _, f := range added {
+		fmt.Printf("New: %v\n", formatFinding(f))
+	}
+	for _, f := range fixed {
+		fmt.Printf("Fixed: %v\n", formatFinding(f))
+	}
+	if showSuppressed {
+		for _, f := range suppressed {
+			fmt.Printf("Suppressed by snapshot %v: %v\n", path, formatFinding(f))
+		}
+		fmt.Printf("\n%v new, %v fixed, %v suppressed, compared to %v.\n", len(added), len(fixed), len(suppressed), path)
+	} else {
+		fmt.Printf("\n%v new, %v fixed, compared to %v.\n", len(added), len(fixed), path)
+	}
+	if len(added) > 0 {
+		exit(exitCodeIssues)
+	}
+}
+
+// runGate compares the issues the linter found to the baseline snapshot, and prints a JSON summary for deploy scripts.
+// An empty baseline path compares against no issues, so every issue is new.
+// If the new issues exceed the thresholds, the program exits with exitCodeIssues.
+func runGate(l *linter.Linter, baseline string, thresholds linter.GateThresholds, exitCodeIssues, exitCodeError int) {
+	var s linter.Snapshot
+	if baseline != "" {
+		var err error
+		s, err = linter.ReadSnapshot(baseline)
+		if err != nil {
+			log.Printf("Error reading snapshot: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	result := linter.Gate(baseline, s, l.Report.Findings, thresholds)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Printf("Error writing report: %v", err)
+		exit(exitCodeError)
+	}
+	if !result.Passed {
+		exit(exitCodeIssues)
+	}
+}
+
+// formatFinding formats a finding like the text output format.
+func formatFinding(f linter.Finding) string {
+	text := f.Text
+	if text == "" {
+		text = "↑"
+	}
+	return fmt.Sprintf("%v:%v: %v ← %v", f.File, f.Line, text, f.Message)
+}
+
+// explainStanza prints the stanza in filePath at the line number or with the title in target,
+// with each line annotated with the directive it maps to.
+func explainStanza(filePath, target, includeFileDirectory string, exitCodeError int) {
+	lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+	if err != nil {
+		log.Printf("Error processing file: %v", err)
+		exit(exitCodeError)
+	}
+	stanza, err := linter.FindStanza(linter.ResolvedStanzas(lines), filePath, target)
+	if err != nil {
+		log.Print(err)
+		exit(exitCodeError)
+	}
+	for _, line := range stanza {
+		fmt.Println(line.Explain())
+	}
+}
+
+// searchFiles prints the lines in the files, and the files they include, which have the directive
+// and an argument matching the glob pattern. If no lines match, the program exits with exitCodeIssues.
+func searchFiles(label, pattern string, filePaths []string, includeFileDirectory, format string, exitCodeIssues, exitCodeError int) {
+	directive, ok := linter.LabelDirective(label)
+	if !ok {
+		log.Printf("Unknown directive %q", label)
+		exit(exitCodeError)
+	}
+	re, err := linter.GlobRegexp(pattern)
+	if err != nil {
+		log.Printf("Invalid pattern %q: %v", pattern, err)
+		exit(exitCodeError)
+	}
+	matches := []linter.Match{}
+	for _, filePath := range filePaths {
+		lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		matches = append(matches, linter.Search(lines, directive, re)...)
+	}
+	if format == linter.FormatText {
+		for _, match := range matches {
+			fmt.Printf("%v:%v: %v\n", match.File, match.Line, match.Text)
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(matches); err != nil {
+			log.Printf("Error writing matches: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if len(matches) == 0 {
+		exit(exitCodeIssues)
+	}
+}
+
+// reportAffectedStanzas prints the lines in the stanzas in the files, and the files they include, whose URL, Host,
+// HostJavaScript, Domain, or DomainJavaScript directives match the hostname. If no lines match, the program exits with exitCodeIssues.
+func reportAffectedStanzas(hostname string, filePaths []string, includeFileDirectory, format string, exitCodeIssues, exitCodeError int) {
+	found := []linter.AffectedLine{}
+	for _, filePath := range filePaths {
+		lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		found = append(found, linter.Affects(linter.ResolvedStanzas(lines), hostname)...)
+	}
+	if format == linter.FormatText {
+		for _, a := range found {
+			fmt.Printf("%v:%v: %q %v\n", a.File, a.Line, a.Title, a.Text)
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(found); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if len(found) == 0 {
+		exit(exitCodeIssues)
+	}
+}
+
+// matchURL prints the line of the stanza which would proxy the URL, walking the stanzas in the files,
+// and the files they include, in the order EZproxy does. If the URL would not be proxied, the program exits with exitCodeIssues.
+func matchURL(rawURL string, filePaths []string, includeFileDirectory, format string, exitCodeIssues, exitCodeError int) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		log.Printf("Unable to parse URL %q, it should look like \"https://www.jstor.org/stable/123\"", rawURL)
+		exit(exitCodeError)
+	}
+	var lines []linter.ResolvedLine
+	for _, filePath := range filePaths {
+		resolved, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		lines = append(lines, resolved...)
+	}
+	match, neverProxy, found := linter.MatchURL(linter.ResolvedStanzas(lines), u)
+	proxied := found && !neverProxy
+	if format == linter.FormatText {
+		switch {
+		case neverProxy:
+			fmt.Printf("%v:%v: %v\n\n%v is not proxied, because its host is never proxied.\n", match.File, match.Line, match.Text, rawURL)
+		case found:
+			fmt.Printf("%v:%v: %q %v\n\n%v is proxied by this stanza.\n", match.File, match.Line, match.Title, match.Text, rawURL)
+		default:
+			fmt.Printf("%v is not proxied, no stanza matches it.\n", rawURL)
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		result := struct {
+			URL     string
+			Proxied bool
+			Match   *linter.AffectedLine `json:",omitempty"`
+		}{URL: rawURL, Proxied: proxied}
+		if found {
+			result.Match = &match
+		}
+		if err := encoder.Encode(result); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if !proxied {
+		exit(exitCodeIssues)
+	}
+}
+
+// listStartingPoints prints the title and proxied starting point URL of each stanza in the files,
+// and the files they include, separated by a tab so the list can be pasted into a spreadsheet.
+func listStartingPoints(proxyPrefix string, filePaths []string, includeFileDirectory, format string, exitCodeError int) {
+	found := []linter.StartingPoint{}
+	for _, filePath := range filePaths {
+		lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		found = append(found, linter.StartingPoints(linter.ResolvedStanzas(lines), proxyPrefix)...)
+	}
+	if format == linter.FormatText {
+		for _, s := range found {
+			fmt.Printf("%v\t%v\n", s.Title, s.StartingPoint)
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(found); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+}
+
+// reportHTTPStanzas prints the stanzas in the files, and the files they include, whose starting point URLs
+// use the http scheme. If there are any, the program exits with exitCodeIssues.
+func reportHTTPStanzas(filePaths []string, includeFileDirectory, format string, exitCodeIssues, exitCodeError int) {
+	found := []linter.HTTPStanza{}
+	for _, filePath := range filePaths {
+		lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		found = append(found, linter.HTTPStanzas(linter.ResolvedStanzas(lines))...)
+	}
+	if format == linter.FormatText {
+		for _, s := range found {
+			fmt.Printf("%v:%v: %q uses %v\n", s.File, s.Line, s.Title, s.URL)
+			if s.Source != "" {
+				fmt.Printf("    Source: %v\n", s.Source)
+			}
+		}
+		if len(found) == 1 {
+			fmt.Print("\n1 stanza does not use HTTPS.\n")
+		} else {
+			fmt.Printf("\n%v stanzas do not use HTTPS.\n", len(found))
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(found); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if len(found) > 0 {
+		exit(exitCodeIssues)
+	}
+}
+
+// reportUnsourcedStanzas prints the stanzas in the files, and the files they include, which don't have Source comments.
+// If there are any, the program exits with exitCodeIssues.
+func reportUnsourcedStanzas(filePaths []string, includeFileDirectory, format string, exitCodeIssues, exitCodeError int) {
+	found := []linter.UnsourcedStanza{}
+	for _, filePath := range filePaths {
+		lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		found = append(found, linter.UnsourcedStanzas(linter.ResolvedStanzas(lines))...)
+	}
+	if format == linter.FormatText {
+		for _, s := range found {
+			fmt.Printf("%v:%v: %q\n", s.File, s.Line, cmp.Or(s.Title, s.URL))
+		}
+		if len(found) == 1 {
+			fmt.Print("\n1 stanza does not have a Source comment.\n")
+		} else {
+			fmt.Printf("\n%v stanzas do not have a Source comment.\n", len(found))
+		}
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(found); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if len(found) > 0 {
+		exit(exitCodeIssues)
+	}
+}
+
+// readTenants resolves the files of each tenant. The tenants are the roots of the manifest, if there is one,
+// or otherwise each file, named by its path.
+func readTenants(manifestPath string, filePaths []string, includeFileDirectory string) (tenants []linter.Tenant, err error) {
+	roots := []linter.ManifestRoot{}
+	if manifestPath != "" {
+		manifest, err := linter.ReadManifest(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		roots = manifest.Roots
+	} else {
+		for _, filePath := range filePaths {
+			roots = append(roots, linter.ManifestRoot{Name: filePath, Files: []string{filePath}, IncludeFileDirectory: includeFileDirectory})
+		}
+	}
+	for _, root := range roots {
+		var lines []linter.ResolvedLine
+		for _, filePath := range root.Files {
+			resolved, err := linter.ResolveFile(filePath, root.IncludeFileDirectory)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, resolved...)
+		}
+		tenants = append(tenants, linter.Tenant{Name: root.Name, Stanzas: linter.ResolvedStanzas(lines)})
+	}
+	return tenants, nil
+}
+
+// reportDrift prints the stanzas which tenants of a consortium have in common, but in different versions.
+// The most common version of each stanza is printed first, and the other versions are printed with the lines
+// they add or remove. If any stanzas drifted, the program exits with exitCodeIssues.
+func reportDrift(manifestPath string, filePaths []string, includeFileDirectory, format string, exitCodeIssues, exitCodeError int) {
+	tenants, err := readTenants(manifestPath, filePaths, includeFileDirectory)
+	if err != nil {
+		log.Printf("Error processing file: %v", err)
+		exit(exitCodeError)
+	}
+	drift := linter.DriftReport(tenants)
+	if format == linter.FormatText {
+		for _, d := range drift {
+			fmt.Printf("%v: %v versions\n", d.Title, len(d.Versions))
+			for i, v := range d.Versions {
+				fmt.Printf("  %v (%v)\n", strings.Join(v.Tenants, ", "), strings.Join(v.Locations, ", "))
+				if i == 0 {
+					fmt.Printf("    most common version\n")
+				}
+				for _, line := range v.Added {
+					fmt.Printf("    + %v\n", line)
+				}
+				for _, line := range v.Removed {
+					fmt.Printf("    - %v\n", line)
+				}
+			}
+		}
+		if len(drift) > 0 {
+			fmt.Printf("\n%v of the stanzas the tenants have in common differ.\n", len(drift))
+		}
+	} else {
+		if drift == nil {
+			drift = []linter.StanzaDrift{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(drift); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if len(drift) > 0 {
+		exit(exitCodeIssues)
+	}
+}
+
+// revertBackup restores the files in a backup made by -fix. If backup is empty, the latest backup in backupDir is restored.
+func revertBackup(backupDir, backup string, exitCodeError int) {
+	if backup == "" {
+		var err error
+		backup, err = linter.LatestBackup(backupDir)
+		if err != nil {
+			log.Printf("Error finding backup: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	b, err := linter.ReadBackup(backup)
+	if err != nil {
+		log.Printf("Error reading backup: %v", err)
+		exit(exitCodeError)
+	}
+	restored, err := linter.RevertBackup(b)
+	for _, path := range restored {
+		fmt.Printf("Restored %v from the backup made %v.\n", path, b.Time.Local().Format(time.DateTime))
+	}
+	if err != nil {
+		log.Printf("Error reverting backup: %v", err)
+		exit(exitCodeError)
+	}
+}
+
+// writeSummary writes the summary of the findings to the report file, and adds it to the history file,
+// if they are set. If either can't be written, the program exits with exitCodeError.
+func writeSummary(l *linter.Linter, reportFile, historyFile string, filePaths []string, exitCodeError int) {
+	w, ok := l.Writer.(*linter.SummaryWriter)
+	if !ok {
+		return
+	}
+	summary := w.Summary
+	summary.Time = time.Now().UTC().Truncate(time.Second)
+	summary.Version = version
+	summary.Files = append(summary.Files, filePaths...)
+	if reportFile != "" {
+		if err := linter.WriteSummary(reportFile, summary); err != nil {
+			log.Printf("Error writing report file: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	if historyFile != "" {
+		if err := linter.AppendHistory(historyFile, summary); err != nil {
+			log.Printf("Error appending to history file: %v", err)
+			exit(exitCodeError)
+		}
+	}
+}
+
+// startCPUProfile starts writing a CPU profile to path. If the profile can't be started, the program exits with exitCodeError.
+func startCPUProfile(path string, exitCodeError int) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("Error creating CPU profile: %v", err)
+		exit(exitCodeError)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		log.Printf("Error starting CPU profile: %v", err)
+		exit(exitCodeError)
+	}
+}
+
+// writeOriginIndex writes the origins claimed by the stanzas in the files, and the files they include, to path.
+// If the index can't be written, the program exits with exitCodeError.
+func writeOriginIndex(path string, filePaths []string, includeFileDirectory string, exitCodeError int) {
+	var index []linter.OriginIndexEntry
+	for _, filePath := range filePaths {
+		lines, err := linter.ResolveFile(filePath, includeFileDirectory)
+		if err != nil {
+			log.Printf("Error processing file: %v", err)
+			exit(exitCodeError)
+		}
+		index = append(index, linter.OriginIndex(linter.ResolvedStanzas(lines))...)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("Error creating origin index: %v", err)
+		exit(exitCodeError)
+	}
+	defer f.Close()
+	if err := linter.WriteOriginIndex(f, path, index); err != nil {
+		log.Printf("Error writing origin index: %v", err)
+		exit(exitCodeError)
+	}
+}
+
+// lintFiles processes the files with the linter, and returns the number of issues found.
+// If a file can't be processed, its path and the error are returned.
+func lintFiles(l *linter.Linter, filePaths []string, includeFileDirectory string) (warningCount int, failed string, err error) {
+	for _, filePath := range filePaths {
+		fileWarningCount, err := l.ProcessFile(filePath)
+		if err != nil {
+			return warningCount, filePath, err
+		}
+		warningCount += fileWarningCount
+		// ProcessFile() recursively processes files referenced
+		// by IncludeFile directives.
+		// If includeFileDirectory is not set by a CLI option,
+		// ProcessFile() will set the linter's IncludeFileDirectory
+		// to the parent directory of the first file is processes.
+		// That is done because IncludeFile directives are processed
+		// as though they were in the file that was processed first.
+		// There might be multiple files passed as CLI arguments,
+		// which might not be in the same parent directory.
+		// The IncludeFileDirectory is reset here so that it does not
+		// potentially remain set to the parent directory of the first
+		// filePath in the argument list.
+		l.IncludeFileDirectory = includeFileDirectory
+		// Stop processing files if an issue triggered -fail-fast.
+		if l.Stopped {
+			break
+		}
+	}
+	return warningCount, "", nil
+}
+
+// lintManifest lints each root of the manifest with its own options, config, and linter.
+// In the text format, each root's findings are printed under its name. In the structured formats,
+// a report with a section for each root is written when every root has been linted.
+// A root which can't be linted is reported, and the remaining roots are still linted.
+func lintManifest(manifestPath string, newLinter func(linterOptions, linter.Config, string) (*linter.Linter, error),
+	options linterOptions, config linter.Config, format string, exitCodeError int) (warningCount int, failed bool) {
+	manifest, err := linter.ReadManifest(manifestPath)
+	if err != nil {
+		log.Printf("Error reading manifest: %v", err)
+		exit(exitCodeError)
+	}
+	// Check the options of every root before linting any of them, so a typo doesn't waste a long run.
+	rootOptions := make([]linterOptions, len(manifest.Roots))
+	for i, root := range manifest.Roots {
+		rootOptions[i], err = options.forRoot(root.Options, format)
+		if err != nil {
+			log.Printf("Error in the options of %v: %v", root.Name, err)
+			exit(exitCodeError)
+		}
+	}
+	structured := format == linter.FormatJSON || format == linter.FormatSARIF
+	var report linter.ManifestReport
+	for i, root := range manifest.Roots {
+		if !structured {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("== %v ==\n", root.Name)
+		}
+		rootWarningCount, processed, err := lintRoot(root, newLinter, rootOptions[i], config, &report)
+		if err != nil {
+			log.Printf("Error linting %v: %v", root.Name, err)
+			failed = true
+			continue
+		}
+		failed = failed || !processed
+		warningCount += rootWarningCount
+		if !structured {
+			switch rootWarningCount {
+			case 0:
+			case 1:
+				fmt.Printf("%v issue found in %v.\n", rootWarningCount, root.Name)
+			default:
+				fmt.Printf("%v issues found in %v.\n", rootWarningCount, root.Name)
+			}
+		}
+	}
+	if structured {
+		var out any = report
+		if format == linter.FormatSARIF {
+			out = report.SARIF()
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(out); err != nil {
+			log.Printf("Error writing report: %v", err)
+			exit(exitCodeError)
+		}
+	}
+	return warningCount, failed
+}
+
+// lintRoot lints the files of one manifest root with the root's options. In the structured formats,
+// the root's findings and errors are added to the manifest report.
+// If one of the root's files can't be processed, the error is reported with the findings, and processed is false.
+func lintRoot(root linter.ManifestRoot, newLinter func(linterOptions, linter.Config, string) (*linter.Linter, error),
+	options linterOptions, config linter.Config, report *linter.ManifestReport) (warningCount int, processed bool, err error) {
+	if root.Config != "" {
+		config, err = linter.ReadConfig(root.Config)
+		if err != nil {
+			return 0, false, fmt.Errorf("error reading config: %w", err)
+		}
+	}
+	l, err := newLinter(options, config, root.IncludeFileDirectory)
+	if err != nil {
+		return 0, false, err
+	}
+	warningCount, failedFile, fileErr := lintFiles(l, root.Files, root.IncludeFileDirectory)
+	if fileErr != nil {
+		l.ReportError(failedFile, fileErr)
+	}
+	if !l.Structured() {
+		return warningCount, fileErr == nil, l.WriteReport()
+	}
+	if l.Metadata != nil {
+		l.Metadata.Finish()
+	}
+	// Use empty lists instead of nulls, to make the output easier to consume.
+	rootReport := linter.RootReport{Name: root.Name, Report: linter.Report{Metadata: l.Metadata, Findings: []linter.Finding{}, Errors: []linter.ProcessingError{}}}
+	rootReport.Findings = append(rootReport.Findings, l.Report.Findings...)
+	rootReport.Errors = append(rootReport.Errors, l.Report.Errors...)
+	report.Roots = append(report.Roots, rootReport)
+	return warningCount, fileErr == nil, nil
+}
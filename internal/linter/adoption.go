@@ -0,0 +1,106 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// An HTTPStanza is a stanza whose starting point URL does not use the HTTPS scheme.
+type HTTPStanza struct {
+	File   string
+	Line   int
+	Title  string
+	URL    string
+	Source string `json:",omitempty"`
+}
+
+// HTTPStanzas returns the stanzas whose URL directive uses the http scheme, sorted by file and line,
+// for planning a migration to HTTPS.
+func HTTPStanzas(stanzas [][]ResolvedLine) (found []HTTPStanza) {
+	for _, stanza := range stanzas {
+		var s HTTPStanza
+		for _, line := range stanza {
+			if line.Comment {
+				if source, ok := strings.CutPrefix(strings.TrimSpace(line.Text), "# Source - "); ok {
+					s.Source = strings.TrimSpace(source)
+				}
+				continue
+			}
+			if !line.Known {
+				continue
+			}
+			switch line.Directive {
+			case Title:
+				s.Title = TrimDirective(line.Text, Title)
+			case URL:
+				u, err := ParseURLDirective(line.Line)
+				if err != nil {
+					continue
+				}
+				parsed, err := url.Parse(u.URL)
+				if err != nil || !strings.EqualFold(parsed.Scheme, "http") {
+					continue
+				}
+				s.File, s.Line = SplitAt(line.At)
+				s.URL = u.URL
+			}
+		}
+		if s.URL != "" {
+			found = append(found, s)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b HTTPStanza) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return found
+}
+
+// An UnsourcedStanza is a stanza without a Source comment.
+// Line is the first line of the stanza which isn't a comment.
+type UnsourcedStanza struct {
+	File  string
+	Line  int
+	Title string
+	URL   string `json:",omitempty"`
+}
+
+// UnsourcedStanzas returns the stanzas with a Title or URL directive which don't have a Source comment,
+// sorted by file and line.
+func UnsourcedStanzas(stanzas [][]ResolvedLine) (found []UnsourcedStanza) {
+	for _, stanza := range stanzas {
+		var s UnsourcedStanza
+		sourced := false
+		for _, line := range stanza {
+			if line.Comment {
+				sourced = sourced || strings.HasPrefix(strings.TrimSpace(line.Text), "# Source - ")
+				continue
+			}
+			if s.File == "" {
+				s.File, s.Line = SplitAt(line.At)
+			}
+			if !line.Known {
+				continue
+			}
+			switch line.Directive {
+			case Title:
+				s.Title = TrimDirective(line.Text, Title)
+			case URL:
+				if u, err := ParseURLDirective(line.Line); err == nil {
+					s.URL = u.URL
+				}
+			}
+		}
+		if !sourced && (s.Title != "" || s.URL != "") {
+			found = append(found, s)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b UnsourcedStanza) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return found
+}
@@ -0,0 +1,64 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestHTTPStanzas(t *testing.T) {
+	var lines []ResolvedLine
+	for i, text := range []string{
+		"Title Secure",
+		"URL https://secure.example.com",
+		"",
+		"# Source - https://help.oclc.org/Library_Management/EZproxy/EZproxy_database_stanzas/Database_stanzas_I/Insecure",
+		"T Insecure",
+		"U -Refresh insecure http://insecure.example.com/start",
+		"",
+		"Title No URL",
+		"H http://host.example.com",
+	} {
+		lines = append(lines, ResolveLine(text, fmt.Sprintf("z.txt:%v", i+1)))
+	}
+	lines = append(lines, ResolveLine("", "z.txt:10"), ResolveLine("Title First", "a.txt:1"), ResolveLine("URL http://first.example.com", "a.txt:2"))
+
+	expected := []HTTPStanza{
+		{File: "a.txt", Line: 2, Title: "First", URL: "http://first.example.com"},
+		{
+			File:   "z.txt",
+			Line:   6,
+			Title:  "Insecure",
+			URL:    "http://insecure.example.com/start",
+			Source: "https://help.oclc.org/Library_Management/EZproxy/EZproxy_database_stanzas/Database_stanzas_I/Insecure",
+		},
+	}
+	if found := HTTPStanzas(ResolvedStanzas(lines)); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("incorrect stanzas %+v", found)
+	}
+}
+
+func TestUnsourcedStanzas(t *testing.T) {
+	var lines []ResolvedLine
+	for i, text := range []string{
+		"# Source - https://help.oclc.org/Library_Management/EZproxy/EZproxy_database_stanzas/Database_stanzas_J/JSTOR",
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"",
+		"# Local stanza",
+		"T Local",
+		"U https://local.example.com",
+		"",
+		"Name ezproxy.library.example.edu",
+	} {
+		lines = append(lines, ResolveLine(text, fmt.Sprintf("config.txt:%v", i+1)))
+	}
+	expected := []UnsourcedStanza{{File: "config.txt", Line: 6, Title: "Local", URL: "https://local.example.com"}}
+	if found := UnsourcedStanzas(ResolvedStanzas(lines)); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("incorrect stanzas %+v", found)
+	}
+}
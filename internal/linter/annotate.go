@@ -0,0 +1,47 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// AnnotateLine prints a line without findings when the whole file is printed.
+// If Highlight is set, the line is highlighted, and the end of each stanza is marked.
+func (l *Linter) AnnotateLine(at, title, line string) {
+	if !l.Highlight {
+		fmt.Fprintf(l.Output, "%v: %v\n", at, line)
+		return
+	}
+	// The stanza state is reset after an empty line or "#" line which ends a stanza.
+	if l.State.LastLineEmpty && title != "" {
+		fmt.Fprintf(l.Output, "%v: %v\n", at, strings.TrimSpace(HighlightLine(line)+" "+
+			color.MagentaString("── End of stanza %q ──", title)))
+		return
+	}
+	fmt.Fprintf(l.Output, "%v: %v\n", at, HighlightLine(line))
+}
+
+// HighlightLine returns the line with the label of its directive colorized.
+// An abbreviated label is followed by the full label, like "T → Title".
+// Comments are dimmed, and lines with unknown labels are returned unchanged.
+func HighlightLine(line string) string {
+	r := ResolveLine(line, "")
+	if r.Comment {
+		return color.HiBlackString(line)
+	}
+	if !r.Known {
+		return line
+	}
+	i := strings.IndexFunc(line, func(c rune) bool { return c != ' ' && c != '\t' })
+	label, _ := SplitLabel(line)
+	highlighted := line[:i] + color.BlueString(label)
+	if abbreviation, ok := LabelAbbreviations()[r.Directive]; ok && strings.EqualFold(label, abbreviation) {
+		highlighted += color.HiBlackString(" → %v", CanonicalLabel(r.Directive))
+	}
+	return highlighted + line[i+len(label):]
+}
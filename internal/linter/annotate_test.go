@@ -0,0 +1,51 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestHighlightLine(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+	var tests = []struct {
+		line     string
+		expected string
+	}{
+		{"T Example", "T → Title Example"},
+		{"  dj example.com", "  dj → DomainJavaScript example.com"},
+		{"Title Example", "Title Example"},
+		{"Option Cookie", "Option Cookie"},
+		{"# A comment", "# A comment"},
+		{"Unknown directive", "Unknown directive"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if highlighted := HighlightLine(tt.line); highlighted != tt.expected {
+			t.Fatalf("incorrect highlighted line %q instead of %q", highlighted, tt.expected)
+		}
+	}
+}
+
+func TestAnnotateLineStanzaEnd(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+	var output bytes.Buffer
+	linter := Linter{Highlight: true, Output: &output}
+	for _, line := range []string{"Title Example", "URL https://www.example.com/", ""} {
+		title := linter.State.Title
+		Messages(linter.ProcessLineAt(line, "test"))
+		linter.AnnotateLine("test", title, line)
+	}
+	expected := "test: Title Example\ntest: URL https://www.example.com/\ntest: ── End of stanza \"Example\" ──\n"
+	if output.String() != expected {
+		t.Fatalf("incorrect output %q instead of %q", output.String(), expected)
+	}
+}
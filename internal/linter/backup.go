@@ -0,0 +1,151 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BackupRecordName is the name of the file in a backup's directory which lists the files it holds.
+const BackupRecordName = "backup.json"
+
+// backupTimeFormat names each backup's directory after the time of the run, so the names sort in time order.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// A Backup holds copies of the files -fix rewrote in one run, so the fixes can be reverted.
+// Each run has its own directory in the backup directory, named after the time of the run.
+type Backup struct {
+	Dir   string `json:"-"`
+	Time  time.Time
+	Files []BackupFile
+}
+
+// A BackupFile is a file -fix rewrote. Copy is the name of the copy of the original file in the backup's directory,
+// and Fixed is the SHA-256 hash of the fixed file, so a revert doesn't overwrite later changes.
+type BackupFile struct {
+	Path  string
+	Copy  string
+	Mode  os.FileMode
+	Fixed string
+}
+
+// hashContent returns the hex encoded SHA-256 hash of the content.
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// backupFile copies the original content of the file at filePath to the run's backup, before the fixed content is written.
+// The backup's directory is made for the first file which is fixed.
+func (l *Linter) backupFile(filePath string, original, fixed []byte, mode os.FileMode) error {
+	if l.Backup == nil {
+		now := time.Now().UTC()
+		dir := filepath.Join(l.BackupDir, now.Format(backupTimeFormat))
+		if err := os.MkdirAll(l.BackupDir, 0700); err != nil {
+			return err
+		}
+		if err := os.Mkdir(dir, 0700); err != nil {
+			return err
+		}
+		l.Backup = &Backup{Dir: dir, Time: now, Files: []BackupFile{}}
+	}
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	// A file fixed twice in one run keeps its first copy, which has the original content.
+	if i := slices.IndexFunc(l.Backup.Files, func(f BackupFile) bool { return f.Path == path }); i != -1 {
+		l.Backup.Files[i].Fixed = hashContent(fixed)
+		return l.writeBackupRecord()
+	}
+	f := BackupFile{
+		Path:  path,
+		Copy:  fmt.Sprintf("%v-%v", len(l.Backup.Files)+1, filepath.Base(path)),
+		Mode:  mode,
+		Fixed: hashContent(fixed),
+	}
+	if err := os.WriteFile(filepath.Join(l.Backup.Dir, f.Copy), original, 0600); err != nil {
+		return err
+	}
+	l.Backup.Files = append(l.Backup.Files, f)
+	return l.writeBackupRecord()
+}
+
+// writeBackupRecord writes the list of files in the run's backup.
+// The record is written after each file, so files fixed before an error can still be reverted.
+func (l *Linter) writeBackupRecord() error {
+	content, err := json.MarshalIndent(l.Backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(l.Backup.Dir, BackupRecordName), append(content, '\n'), 0600)
+}
+
+// ReadBackup reads the backup in dir.
+func ReadBackup(dir string) (b Backup, err error) {
+	content, err := os.ReadFile(filepath.Join(dir, BackupRecordName))
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(content, &b); err != nil {
+		return b, fmt.Errorf("unable to read backup %v: %w", dir, err)
+	}
+	b.Dir = dir
+	return b, nil
+}
+
+// LatestBackup returns the directory of the most recent backup in backupDir.
+func LatestBackup(backupDir string) (string, error) {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return "", err
+	}
+	// The directories are named after the time of the run, so the last one is the most recent.
+	for _, entry := range slices.Backward(entries) {
+		dir := filepath.Join(backupDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, BackupRecordName)); entry.IsDir() && err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("there are no backups in %v", backupDir)
+}
+
+// RevertBackup restores the files in the backup to the state they were in before they were fixed,
+// then removes the backup, so the next revert restores the backup before it.
+// If a file has changed since it was fixed, nothing is restored, so later changes aren't lost.
+func RevertBackup(b Backup) (restored []string, err error) {
+	var changed []string
+	for _, f := range b.Files {
+		content, err := os.ReadFile(f.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err != nil || hashContent(content) != f.Fixed {
+			changed = append(changed, f.Path)
+		}
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("files have changed since they were fixed, and were not restored: %v", strings.Join(changed, ", "))
+	}
+	for _, f := range b.Files {
+		content, err := os.ReadFile(filepath.Join(b.Dir, f.Copy))
+		if err != nil {
+			return restored, err
+		}
+		if err := os.WriteFile(f.Path, content, f.Mode.Perm()); err != nil {
+			return restored, err
+		}
+		restored = append(restored, f.Path)
+	}
+	return restored, os.RemoveAll(b.Dir)
+}
@@ -0,0 +1,69 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRevertBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	original := "title Example\nURL http://example.com\n"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	backupDir := filepath.Join(dir, "backups")
+	linter := &Linter{DirectiveCase: true, Fix: true, BackupDir: backupDir, Format: FormatJSON, Output: io.Discard}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fixed) != "Title Example\nURL http://example.com\n" {
+		t.Fatalf("incorrect fixed file %q", fixed)
+	}
+	latest, err := LatestBackup(backupDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ReadBackup(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A file which changed after it was fixed isn't restored.
+	if err := os.WriteFile(path, []byte("Title Changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RevertBackup(b); err == nil {
+		t.Fatal("expected an error reverting a changed file")
+	}
+	if err := os.WriteFile(path, fixed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RevertBackup(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 1 || restored[0] != path {
+		t.Fatalf("incorrect restored files %v", restored)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != original {
+		t.Fatalf("incorrect restored file %q instead of %q", content, original)
+	}
+	// The backup is removed once it is restored.
+	if _, err := LatestBackup(backupDir); err == nil {
+		t.Fatal("expected no backups after the revert")
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A Cache stores the findings for files which do not include other files, so those files can be skipped
+// when they are processed again with the same contents, options, and findings from the files before them.
+// Options should describe everything which changes the findings, like the version of the linter and its flags.
+// The cache does not notice changes to OCLC's stanzas or to the files referenced by directives.
+type Cache struct {
+	Dir     string
+	Options string
+	Hits    int
+	Misses  int
+}
+
+// NewCache returns a Cache which stores its entries in dir, creating it if needed.
+// The options are taken from the metadata for the run.
+func NewCache(dir string, m *Metadata) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	options, err := json.Marshal(struct {
+		Version string
+		Flags   map[string]string
+		Config  Config
+	}{m.Version, m.Flags, m.Config})
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir, Options: string(options)}, nil
+}
+
+// cacheState is the state which carries over from one file to the next, other than the values in the SeenIndexes.
+// Each file starts with an empty stanza state, and ends after an empty line or "#" line which resets it,
+// so only whether the last line was empty is kept.
+type cacheState struct {
+	BlankLine           bool
+	Name                string
+	NameAt              string
+	HAName              string
+	NameReferences      []HostLine
+	LoginPorts          map[int]string
+	RunAsAt             string
+	SkipPorts           map[string]string
+	BannerDirectivesAt  map[Directive]string
+	FirstStanzaAt       string
+	ProxyByHostname     bool
+	HostnameDirective   Directive
+	HostnameDirectiveAt string
+	DomainThreatAt      string
+	DomainThreatCount   int
+	Group               string
+	IncludeDepth        int
+}
+
+// cacheSeen holds the values a file added to the SeenIndexes. The indexes grow with every stanza,
+// so an entry only stores the values its file added, instead of a copy of each index.
+type cacheSeen struct {
+	Titles       []seenEntry `json:",omitempty"`
+	Origins      []seenEntry `json:",omitempty"`
+	Descriptions []seenEntry `json:",omitempty"`
+	Vendors      []seenEntry `json:",omitempty"`
+}
+
+// cacheSeenLengths are the lengths of the SeenIndexes before a file is processed.
+type cacheSeenLengths struct {
+	titles, origins, descriptions, vendors int
+}
+
+// A cacheReport is a call to ReportLine.
+type cacheReport struct {
+	At       string
+	Title    string
+	Line     string
+	Messages []string
+}
+
+// A cacheEntry is the result of processing a file.
+type cacheEntry struct {
+	Reports      []cacheReport
+	WarningCount int
+	State        cacheState
+	Seen         cacheSeen
+}
+
+// A cacheRecorder records the reports for a file while it is processed.
+// Files which include other files are not stored.
+type cacheRecorder struct {
+	key      string
+	reports  []cacheReport
+	includes bool
+}
+
+// Cacheable reports whether files can be skipped using the cache.
+// Files are always processed when they are fixed or printed in full, or when processing can stop early.
+func (l *Linter) Cacheable() bool {
+	return l.Cache != nil && !l.Fix && !l.Annotate && !l.Verbose && l.FailFast == ""
+}
+
+// seenLengths returns the number of changes to the SeenIndexes, so the values a file changes can be found.
+func (l *Linter) seenLengths() cacheSeenLengths {
+	return cacheSeenLengths{l.PreviousTitles.Changes(), l.PreviousOrigins.Changes(), l.PreviousDescriptions.Changes(), l.PreviousVendors.Changes()}
+}
+
+// seenSince returns the values added to or replaced in the SeenIndexes since they had the numbers of changes.
+func (l *Linter) seenSince(n cacheSeenLengths) cacheSeen {
+	return cacheSeen{
+		Titles:       l.PreviousTitles.Since(n.titles),
+		Origins:      l.PreviousOrigins.Since(n.origins),
+		Descriptions: l.PreviousDescriptions.Since(n.descriptions),
+		Vendors:      l.PreviousVendors.Since(n.vendors),
+	}
+}
+
+// addSeen stores the values a cached file added to or replaced in the SeenIndexes.
+func (l *Linter) addSeen(seen cacheSeen) {
+	l.PreviousTitles.AddEntries(seen.Titles)
+	l.PreviousOrigins.AddEntries(seen.Origins)
+	l.PreviousDescriptions.AddEntries(seen.Descriptions)
+	l.PreviousVendors.AddEntries(seen.Vendors)
+}
+
+func (l *Linter) cacheState() cacheState {
+	return cacheState{
+		BlankLine:           l.State.BlankLine,
+		Name:                l.Name,
+		NameAt:              l.NameAt,
+		HAName:              l.HAName,
+		NameReferences:      l.NameReferences,
+		LoginPorts:          l.LoginPorts,
+		RunAsAt:             l.RunAsAt,
+		SkipPorts:           l.SkipPorts,
+		BannerDirectivesAt:  l.BannerDirectivesAt,
+		FirstStanzaAt:       l.FirstStanzaAt,
+		ProxyByHostname:     l.ProxyByHostname,
+		HostnameDirective:   l.HostnameDirective,
+		HostnameDirectiveAt: l.HostnameDirectiveAt,
+		DomainThreatAt:      l.DomainThreatAt,
+		DomainThreatCount:   l.DomainThreatCount,
+		Group:               l.Group,
+		IncludeDepth:        l.IncludeDepth,
+	}
+}
+
+func (l *Linter) restoreCacheState(s cacheState) {
+	l.State = State{LastLineEmpty: true, BlankLine: s.BlankLine}
+	l.Name = s.Name
+	l.NameAt = s.NameAt
+	l.HAName = s.HAName
+	l.NameReferences = s.NameReferences
+	l.LoginPorts = s.LoginPorts
+	l.RunAsAt = s.RunAsAt
+	l.SkipPorts = s.SkipPorts
+	l.BannerDirectivesAt = s.BannerDirectivesAt
+	l.FirstStanzaAt = s.FirstStanzaAt
+	l.ProxyByHostname = s.ProxyByHostname
+	l.HostnameDirective = s.HostnameDirective
+	l.HostnameDirectiveAt = s.HostnameDirectiveAt
+	l.DomainThreatAt = s.DomainThreatAt
+	l.DomainThreatCount = s.DomainThreatCount
+	l.Group = s.Group
+	l.IncludeDepth = s.IncludeDepth
+}
+
+// updateCacheDigest adds the changes to the state since the last update to the running digest of the state.
+// The values in the SeenIndexes are only added once, so hashing the state doesn't get slower as the config grows.
+func (l *Linter) updateCacheDigest() error {
+	state := l.cacheState()
+	state.BlankLine = false
+	b, err := json.Marshal(struct {
+		Digest string
+		State  cacheState
+		Seen   cacheSeen
+	}{l.cacheDigest, state, l.seenSince(l.cacheLengths)})
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(b)
+	l.cacheDigest, l.cacheLengths = hex.EncodeToString(sum[:]), l.seenLengths()
+	return nil
+}
+
+// cacheKey returns the key for the file with the content, processed with the current state,
+// which is described by its running digest.
+// Stale stanzas depend on the current date, so it is part of the key when they are reported.
+func (l *Linter) cacheKey(filePath string, content []byte) (string, error) {
+	if err := l.updateCacheDigest(); err != nil {
+		return "", err
+	}
+	date := ""
+	if l.StaleAfter > 0 {
+		date = time.Now().Format(time.DateOnly)
+	}
+	contentSum := sha256.Sum256(content)
+	b, err := json.Marshal(struct {
+		Options string
+		Date    string
+		File    string
+		Content string
+		State   string
+	}{l.Cache.Options, date, filePath, hex.EncodeToString(contentSum[:]), l.cacheDigest})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// load returns the entry for the key, if there is one.
+func (c *Cache) load(key string) (e cacheEntry, ok bool, err error) {
+	b, err := os.ReadFile(filepath.Join(c.Dir, key+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		c.Misses++
+		return e, false, nil
+	}
+	if err != nil {
+		return e, false, err
+	}
+	if err := json.Unmarshal(b, &e); err != nil {
+		return e, false, err
+	}
+	c.Hits++
+	return e, true, nil
+}
+
+// store writes the entry for the key.
+func (c *Cache) store(key string, e cacheEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.Dir, key+".json"), b, 0600)
+}
+
+// startCache looks for the file in the cache. If it is there, its findings are reported again,
+// the state after it and the values it added to the SeenIndexes are restored, and the file does not need to be processed.
+// Otherwise, the file's findings are recorded until the returned function is called, which stores them
+// if the file was processed successfully.
+func (l *Linter) startCache(filePath string, content []byte) (warningCount int, hit bool, done func(warningCount int, ok bool) error, err error) {
+	key, err := l.cacheKey(filePath, content)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	e, ok, err := l.Cache.load(key)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	if ok {
+		l.restoreCacheState(e.State)
+		l.addSeen(e.Seen)
+		for _, r := range e.Reports {
+			l.ReportLine(r.At, r.Title, r.Line, r.Messages)
+		}
+		return e.WarningCount, true, nil, nil
+	}
+	recorder := &cacheRecorder{key: key}
+	l.cacheRecorders = append(l.cacheRecorders, recorder)
+	lengths := l.cacheLengths
+	return 0, false, func(warningCount int, ok bool) error {
+		l.cacheRecorders = l.cacheRecorders[:len(l.cacheRecorders)-1]
+		if !ok || recorder.includes {
+			return nil
+		}
+		return l.Cache.store(key, cacheEntry{Reports: recorder.reports, WarningCount: warningCount, State: l.cacheState(), Seen: l.seenSince(lengths)})
+	}, nil
+}
+
+// recordCacheReport records a call to ReportLine for the file being processed.
+func (l *Linter) recordCacheReport(at, title, line string, messages []string) {
+	if len(l.cacheRecorders) == 0 {
+		return
+	}
+	recorder := l.cacheRecorders[len(l.cacheRecorders)-1]
+	recorder.reports = append(recorder.reports, cacheReport{At: at, Title: title, Line: line, Messages: messages})
+}
+
+// recordCacheInclude records that the files being processed include other files, so they can't be stored.
+func (l *Linter) recordCacheInclude() {
+	for _, recorder := range l.cacheRecorders {
+		recorder.includes = true
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.txt": "IncludeFile a.txt\nIncludeFile b.txt\n",
+		"a.txt":      "Title JSTOR\nURL https://www.jstor.org/\nFooBar baz\n",
+		"b.txt":      "Title JSTOR\nURL https://www.jstor.org/stable/\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := &Cache{Dir: t.TempDir(), Options: "test"}
+	run := func() (int, []Finding) {
+		linter := Linter{FollowIncludeFile: true, Format: FormatJSON, Output: io.Discard, Cache: cache}
+		count, err := linter.ProcessFile(filepath.Join(dir, "config.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return count, linter.Report.Findings
+	}
+
+	count, findings := run()
+	if count != 3 || cache.Hits != 0 {
+		t.Fatalf("found %v issues with %v cache hits: %+v", count, cache.Hits, findings)
+	}
+	cachedCount, cachedFindings := run()
+	if cachedCount != count || !reflect.DeepEqual(cachedFindings, findings) {
+		t.Fatalf("found %v issues instead of %v from the cache: %+v", cachedCount, count, cachedFindings)
+	}
+	if cache.Hits != 2 {
+		t.Fatalf("%v cache hits instead of 2", cache.Hits)
+	}
+
+	// Only a.txt is processed again after it changes, and the duplicates in b.txt are still found.
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Title JSTOR\nURL https://www.jstor.org/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cache.Hits = 0
+	count, findings = run()
+	if count != 2 || cache.Hits != 1 || findings[0].File != filepath.Join(dir, "b.txt") {
+		t.Fatalf("found %v issues with %v cache hits: %+v", count, cache.Hits, findings)
+	}
+}
+
+func TestCacheName(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.txt": "IncludeFile a.txt\nIncludeFile b.txt\n",
+		"a.txt":      "Name proxy.library.example.edu\n",
+		"b.txt":      "Name ezproxy.library.example.edu\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := &Cache{Dir: t.TempDir(), Options: "test"}
+	run := func() []string {
+		linter := Linter{FollowIncludeFile: true, Pedantic: true, Format: FormatJSON, Output: io.Discard, Cache: cache}
+		if _, err := linter.ProcessFile(filepath.Join(dir, "config.txt")); err != nil {
+			t.Fatal(err)
+		}
+		var messages []string
+		for _, f := range linter.Report.Findings {
+			messages = append(messages, f.Message)
+		}
+		return messages
+	}
+
+	expected := []string{fmt.Sprintf("\"Name\" directive already seen at %q, the earlier value \"proxy.library.example.edu\" is replaced (L2012)",
+		filepath.Join(dir, "a.txt")+":1")}
+	if messages := run(); !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+
+	// a.txt is read from the cache, and b.txt is processed again after it changes.
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("Name proxy2.library.example.edu\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cache.Hits = 0
+	messages := run()
+	if cache.Hits != 1 || !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q with %v cache hits", messages, expected, cache.Hits)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A CollapseWriter collapses the findings in a file with the same code and message into one finding,
+// so output stays readable when hundreds of lines have the same issue, like trailing whitespace.
+// The collapsed finding is the first one, with the number of findings in Count and their lines in Lines.
+// Findings are held until the writer is closed, then passed on to another FindingWriter in their original order.
+type CollapseWriter struct {
+	Writer   FindingWriter
+	findings [][]*Finding
+	first    map[string]*Finding
+	errors   []ProcessingError
+}
+
+// NewCollapseWriter returns a CollapseWriter which passes the collapsed findings and errors on to w.
+func NewCollapseWriter(w FindingWriter) *CollapseWriter {
+	return &CollapseWriter{Writer: w, first: map[string]*Finding{}}
+}
+
+// WriteFindings holds the findings which aren't repeats of an earlier finding, and counts the repeats.
+func (w *CollapseWriter) WriteFindings(findings []Finding) error {
+	var kept []*Finding
+	for _, f := range findings {
+		key := strings.Join([]string{f.File, f.Code, f.Message}, "\x00")
+		first, ok := w.first[key]
+		if !ok {
+			f.Count, f.Lines = 1, []int{f.Line}
+			w.first[key] = &f
+			kept = append(kept, &f)
+			continue
+		}
+		first.Count++
+		first.Lines = append(first.Lines, f.Line)
+		// Keep the edits, so the collapsed finding fixes every line, but a Fix only has one line to replace.
+		first.Edits = append(first.Edits, f.Edits...)
+		first.Fix = nil
+	}
+	if len(kept) > 0 {
+		w.findings = append(w.findings, kept)
+	}
+	return nil
+}
+
+// WriteError holds the error until the writer is closed.
+func (w *CollapseWriter) WriteError(e ProcessingError) error {
+	w.errors = append(w.errors, e)
+	return nil
+}
+
+// Close writes the collapsed findings and the errors to the wrapped writer, then closes it.
+// Findings which weren't repeated don't have a Count or Lines.
+func (w *CollapseWriter) Close() error {
+	for _, kept := range w.findings {
+		findings := make([]Finding, 0, len(kept))
+		for _, f := range kept {
+			if f.Count == 1 {
+				f.Count, f.Lines = 0, nil
+			}
+			findings = append(findings, *f)
+		}
+		if err := w.Writer.WriteFindings(findings); err != nil {
+			return err
+		}
+	}
+	for _, e := range w.errors {
+		if err := w.Writer.WriteError(e); err != nil {
+			return err
+		}
+	}
+	return w.Writer.Close()
+}
+
+// Repeats describes the lines of a collapsed finding, like " (3 times, on lines 4, 9, 12)".
+// It is empty if the finding wasn't collapsed.
+func (f Finding) Repeats() string {
+	if f.Count < 2 {
+		return ""
+	}
+	lines := make([]string, 0, len(f.Lines))
+	for _, line := range f.Lines {
+		lines = append(lines, fmt.Sprint(line))
+	}
+	return fmt.Sprintf(" (%v times, on lines %v)", f.Count, strings.Join(lines, ", "))
+}
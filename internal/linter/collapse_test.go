@@ -0,0 +1,48 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCollapseWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(path, []byte("Title Example \nURL http://example.com \nFooBar\nDomain example.com \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := &Linter{Whitespace: true, Format: FormatJSON, Output: io.Discard}
+	linter.Writer = NewCollapseWriter(linter.FindingWriter())
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := linter.FindingWriter().Close(); err != nil {
+		t.Fatal(err)
+	}
+	type collapsed struct {
+		Line  int
+		Code  string
+		Count int
+		Lines []int
+	}
+	var got []collapsed
+	for _, f := range linter.Report.Findings {
+		got = append(got, collapsed{Line: f.Line, Code: f.Code, Count: f.Count, Lines: f.Lines})
+	}
+	expected := []collapsed{
+		{Line: 1, Code: "L5002", Count: 3, Lines: []int{1, 2, 4}},
+		{Line: 3, Code: "L9001"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("incorrect findings %+v instead of %+v", got, expected)
+	}
+	if repeats := linter.Report.Findings[0].Repeats(); repeats != " (3 times, on lines 1, 2, 4)" {
+		t.Fatalf("incorrect repeats %q", repeats)
+	}
+}
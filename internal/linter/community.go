@@ -0,0 +1,155 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// A CommunityStanza is a stanza from a community stanza repository.
+type CommunityStanza struct {
+	At    string
+	Title string
+	URL   string
+	Lines []string
+}
+
+// LoadCommunityStanzas loads the stanzas from the CommunityRepo, if they have not been loaded yet.
+// The CommunityRepo can be a local directory, or a Git repository which is cloned with the git command.
+func (l *Linter) LoadCommunityStanzas() error {
+	defer l.Profile.Time(ProfileCommunity)()
+	if l.CommunityRepo == "" || l.CommunityStanzas != nil {
+		return nil
+	}
+	dir := l.CommunityRepo
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir, err = os.MkdirTemp("", "ezproxy-config-lint-community-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		output, err := exec.Command("git", "clone", "--quiet", "--depth", "1", l.CommunityRepo, dir).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("unable to clone community stanza repository %v: %w: %v", l.CommunityRepo, err, strings.TrimSpace(string(output)))
+		}
+	}
+	stanzas, err := CommunityStanzasFromDirectory(dir)
+	if err != nil {
+		return err
+	}
+	l.CommunityStanzas = stanzas
+	return nil
+}
+
+// CommunityStanzasFromDirectory reads the stanzas from the .txt files in dir and its subdirectories.
+// Locations are relative to dir, so they match the paths in the repository.
+func CommunityStanzasFromDirectory(dir string) (stanzas []CommunityStanza, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".txt") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		var lines []ResolvedLine
+		for i, text := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+			lines = append(lines, ResolveLine(text, fmt.Sprintf("%v:%v", filepath.ToSlash(rel), i+1)))
+		}
+		for _, stanza := range ResolvedStanzas(lines) {
+			if s := NewCommunityStanza(stanza); s.Title != "" || s.URL != "" {
+				stanzas = append(stanzas, s)
+			}
+		}
+		return nil
+	})
+	if stanzas == nil {
+		stanzas = []CommunityStanza{}
+	}
+	return stanzas, err
+}
+
+// NewCommunityStanza makes a CommunityStanza from the lines of a stanza.
+func NewCommunityStanza(stanza []ResolvedLine) (s CommunityStanza) {
+	for _, line := range stanza {
+		if line.Comment {
+			continue
+		}
+		if s.At == "" {
+			s.At = line.At
+		}
+		s.Lines = append(s.Lines, CommunityLine(line.Line))
+		if !line.Known {
+			continue
+		}
+		switch line.Directive {
+		case Title:
+			s.Title = TrimDirective(line.Line, Title)
+		case URL:
+			if u, err := ParseURLDirective(line.Line); err == nil {
+				s.URL = u.URL
+			}
+		}
+	}
+	return s
+}
+
+// CommunityLine normalizes a line for comparison with community stanzas.
+// Whitespace is collapsed, so only changes to labels and arguments are reported.
+func CommunityLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// FindCommunityStanza finds the community stanza with the same title, ignoring case,
+// or if none have the same title, the same starting point URL.
+func (l *Linter) FindCommunityStanza(title, startingPointURL string) (CommunityStanza, bool) {
+	for _, s := range l.CommunityStanzas {
+		if title != "" && strings.EqualFold(s.Title, title) {
+			return s, true
+		}
+	}
+	for _, s := range l.CommunityStanzas {
+		if startingPointURL != "" && s.URL == startingPointURL {
+			return s, true
+		}
+	}
+	return CommunityStanza{}, false
+}
+
+// CommunityCheck compares the lines of the stanza which just ended to its community version.
+// The order of lines is not compared.
+func (l *Linter) CommunityCheck() (m []string) {
+	defer l.Profile.Time(ProfileCommunity)()
+	if l.State.Title == "" && l.State.URL == "" {
+		return m
+	}
+	community, found := l.FindCommunityStanza(l.State.Title, l.State.URL)
+	if !found {
+		return m
+	}
+	added, missing := lineDifference(community.Lines, l.State.Lines)
+	if len(missing) == 0 && len(added) == 0 {
+		return m
+	}
+	m = append(m, fmt.Sprintf("Stanza %q differs from the community version at %q: "+
+		"%v lines are only in the community version, and %v lines are only in this stanza (L9007)",
+		cmp.Or(l.State.Title, l.State.URL), community.At, len(missing), len(added)))
+	return m
+}
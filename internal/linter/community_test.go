@@ -0,0 +1,44 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommunityCheck(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "stanzas"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	community := "# A comment\nTitle JSTOR\nURL https://www.jstor.org\nHJ www.jstor.org\nDJ jstor.org\n\nTitle Wiley\nURL https://www.wiley.com\nDJ wiley.com\n"
+	if err := os.WriteFile(filepath.Join(repo, "stanzas", "community.txt"), []byte(community), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "config.txt")
+	local := "Title JSTOR\nU https://www.jstor.org\nDJ   jstor.org\nHostJavaScript www.jstor.org\n\nTitle Wiley Online\nURL https://www.wiley.com\nDJ wiley.com\nDJ onlinelibrary.wiley.com\n"
+	if err := os.WriteFile(config, []byte(local), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := Linter{CommunityRepo: repo, Format: FormatJSON, Output: io.Discard}
+	count, err := linter.ProcessFile(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Stanza \"Wiley Online\" differs from the community version at \"stanzas/community.txt:7\": " +
+		"1 lines are only in the community version, and 2 lines are only in this stanza (L9007)"
+	if count != 1 || linter.Report.Findings[0].Message != expected {
+		t.Fatalf("incorrect findings %+v", linter.Report.Findings)
+	}
+
+	linter = Linter{CommunityRepo: filepath.Join(repo, "missing"), Output: io.Discard}
+	if _, err := linter.ProcessFile(config); err == nil {
+		t.Fatal("expected an error for a missing repository")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// A Config holds the settings from a configuration file, for settings which are too detailed for flags.
+type Config struct {
+	// HeaderTemplate is a list of regular expressions. Every stanza must have a comment before its
+	// first directive which matches each expression. Named captures called "date", "email", or "url"
+	// must be a valid date in the form YYYY-MM-DD, email address, or URL.
+	HeaderTemplate []string
+	HeaderPatterns []*regexp.Regexp `json:"-"`
+	// RequireBanners is a list of banner directives, like "AutoLoginIPBanner", which must be used
+	// if the config has the IP ranges the banner is shown to.
+	RequireBanners []string `json:",omitempty"`
+	// TitleAliases maps local Title values, without the -Hide qualifier, to the title of the OCLC stanza
+	// they were intentionally renamed from, so the Source title check only reports when the OCLC title changes.
+	TitleAliases map[string]string `json:",omitempty"`
+	// StanzaFileName is the pattern for the names of files with one stanza, used with -one-stanza-per-file.
+	// "{slug}" is replaced by the slug of the stanza's title, and "*" matches any characters. The default is "{slug}.txt".
+	StanzaFileName string `json:",omitempty"`
+	// SourceHosts maps hosts other than help.oclc.org which Source comments can point to, like a vendor's support site
+	// or an internal wiki, to whether the page is fetched to check the stanza's title. Like OCLC's pages,
+	// the page should have the stanza in a <pre> element.
+	SourceHosts map[string]bool `json:",omitempty"`
+	// SharedOrigins lists origins, like "https://cdn.example.com", and domains, like "doi.org", which are legitimately used
+	// by many stanzas, so they aren't reported as already seen in another stanza. A domain covers its subdomains.
+	SharedOrigins []string `json:",omitempty"`
+	// StanzaHeader is a regular expression for the header comments which start a stanza, like "# --- JSTOR ---",
+	// used with -implicit-boundaries. The default is DefaultStanzaHeader.
+	StanzaHeader        string         `json:",omitempty"`
+	stanzaHeaderPattern *regexp.Regexp `json:"-"`
+	// Messages maps rule codes, like "L2002", to Go templates which replace the messages of the rule's findings,
+	// to add local remediation instructions or translate them. The templates use the fields of MessageData,
+	// like "{{.Message}} Ask the e-resources team before adding a duplicate." The rule's code is kept at the end.
+	Messages         map[string]string             `json:",omitempty"`
+	messageTemplates map[string]*template.Template `json:"-"`
+}
+
+// DefaultStanzaHeader matches header comments like "# --- JSTOR ---" or "#### EBSCO ####".
+const DefaultStanzaHeader = `^#\s*(-{3,}|#{3,}|={3,}).*$`
+
+var defaultStanzaHeaderPattern = regexp.MustCompile(DefaultStanzaHeader)
+
+// StanzaHeaderPattern returns the compiled StanzaHeader setting, or DefaultStanzaHeader if it isn't set or isn't valid.
+func (c Config) StanzaHeaderPattern() *regexp.Regexp {
+	if c.stanzaHeaderPattern != nil {
+		return c.stanzaHeaderPattern
+	}
+	if pattern, err := regexp.Compile(c.StanzaHeader); err == nil && c.StanzaHeader != "" {
+		return pattern
+	}
+	return defaultStanzaHeaderPattern
+}
+
+// ReadConfig reads the configuration file at path. Unknown settings are an error, to catch typos.
+func ReadConfig(path string) (c Config, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return c, fmt.Errorf("unable to read config %v: %w", path, err)
+	}
+	for _, template := range c.HeaderTemplate {
+		pattern, err := regexp.Compile(template)
+		if err != nil {
+			return c, fmt.Errorf("HeaderTemplate %q in config %v is not a valid regular expression: %w", template, path, err)
+		}
+		c.HeaderPatterns = append(c.HeaderPatterns, pattern)
+	}
+	for _, banner := range c.RequireBanners {
+		if directive, ok := LabelDirective(banner); !ok || directive.String() != banner || BannerPairs()[directive] == Undefined {
+			return c, fmt.Errorf("RequireBanners value %q in config %v is not a banner directive", banner, path)
+		}
+	}
+	for title, oclcTitle := range c.TitleAliases {
+		if title == "" || oclcTitle == "" {
+			return c, fmt.Errorf("TitleAliases in config %v can not have an empty title", path)
+		}
+	}
+	sourceHosts := make(map[string]bool, len(c.SourceHosts))
+	for host, verifyTitle := range c.SourceHosts {
+		if hostname, _, _ := strings.Cut(host, ":"); !IsHostname(hostname) || strings.Contains(host, "/") {
+			return c, fmt.Errorf("SourceHosts value %q in config %v is not a host", host, path)
+		}
+		sourceHosts[strings.ToLower(host)] = verifyTitle
+	}
+	if len(sourceHosts) > 0 {
+		c.SourceHosts = sourceHosts
+	}
+	for i, shared := range c.SharedOrigins {
+		if strings.Contains(shared, "://") {
+			u, err := url.Parse(shared)
+			if err != nil || !IsHostname(u.Hostname()) || strings.Trim(u.Path, "/") != "" {
+				return c, fmt.Errorf("SharedOrigins value %q in config %v is not an origin or a domain", shared, path)
+			}
+			c.SharedOrigins[i] = NormalizeOrigin(u)
+			continue
+		}
+		domain := strings.ToLower(strings.TrimPrefix(shared, "."))
+		if !IsHostname(domain) {
+			return c, fmt.Errorf("SharedOrigins value %q in config %v is not an origin or a domain", shared, path)
+		}
+		c.SharedOrigins[i] = domain
+	}
+	if c.StanzaHeader != "" {
+		c.stanzaHeaderPattern, err = regexp.Compile(c.StanzaHeader)
+		if err != nil {
+			return c, fmt.Errorf("StanzaHeader %q in config %v is not a valid regular expression: %w", c.StanzaHeader, path, err)
+		}
+	}
+	c.messageTemplates, err = parseMessageTemplates(c.Messages)
+	if err != nil {
+		return c, fmt.Errorf("Messages in config %v are not valid: %w", path, err)
+	}
+	if c.StanzaFileName != "" {
+		if _, err := filepath.Match(c.StanzaFileName, ""); err != nil || !strings.Contains(c.StanzaFileName, "{slug}") {
+			return c, fmt.Errorf("StanzaFileName %q in config %v should be a file name pattern with \"{slug}\"", c.StanzaFileName, path)
+		}
+	}
+	return c, nil
+}
+
+// SharedOrigin reports whether the origin, as returned by NormalizeOrigin, is in the SharedOrigins setting,
+// or is on one of its domains.
+func (c Config) SharedOrigin(origin string) bool {
+	if slices.Contains(c.SharedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && c.SharedHost(u.Hostname())
+}
+
+// SharedHost reports whether the host is on one of the domains in the SharedOrigins setting.
+func (c Config) SharedHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, shared := range c.SharedOrigins {
+		if host == shared || strings.HasSuffix(host, "."+shared) {
+			return true
+		}
+	}
+	return false
+}
+
+// HeaderTemplateChecks checks the comments before the first directive of the stanza which just ended
+// against the HeaderTemplate.
+func (l *Linter) HeaderTemplateChecks() (m []string) {
+	if l.State.Title == "" && l.State.URL == "" {
+		return m
+	}
+	for _, pattern := range l.Config.HeaderPatterns {
+		matched := false
+		for _, comment := range l.State.HeaderComments {
+			match := pattern.FindStringSubmatch(comment)
+			if match == nil {
+				continue
+			}
+			matched = true
+			for i, name := range pattern.SubexpNames() {
+				if problem := CaptureCheck(name, match[i]); problem != "" {
+					m = append(m, fmt.Sprintf("Stanza %q has a header comment %q with an invalid %v, %v (L3018)",
+						cmp.Or(l.State.Title, l.State.URL), comment, name, problem))
+				}
+			}
+			break
+		}
+		if !matched {
+			m = append(m, fmt.Sprintf("Stanza %q doesn't have a header comment matching %q (L4010)", cmp.Or(l.State.Title, l.State.URL), pattern))
+		}
+	}
+	return m
+}
+
+// CaptureCheck validates the value of a named capture from a HeaderTemplate.
+// It returns a description of the problem, or an empty string if the value is valid.
+func CaptureCheck(name, value string) string {
+	switch name {
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return fmt.Sprintf("%q is not a date in the form YYYY-MM-DD", value)
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Sprintf("%q is not an email address", value)
+		}
+	case "url":
+		if u, err := url.ParseRequestURI(value); err != nil || u.Host == "" {
+			return fmt.Sprintf("%q is not a URL", value)
+		}
+	}
+	return ""
+}
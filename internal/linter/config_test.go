@@ -0,0 +1,296 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	dir := t.TempDir()
+	var tests = []struct {
+		content string
+		valid   bool
+	}{
+		{`{"HeaderTemplate": ["^# Updated: (?P<date>.+)$"]}`, true},
+		{`{"HeaderTemplate": ["^# Updated: (?P<date>.+$"]}`, false},
+		{`{"HeaderTemplates": []}`, false},
+		{`{"StanzaHeader": "^# --- .+ ---$"}`, true},
+		{`{"StanzaHeader": "^# --- (.+ ---$"}`, false},
+		{`not json`, false},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := ReadConfig(path)
+		if (err == nil) != tt.valid {
+			t.Fatalf("test %v: unexpected error value %v", i, err)
+		}
+		if tt.valid && len(c.HeaderPatterns) != len(c.HeaderTemplate) {
+			t.Fatalf("test %v: templates were not compiled", i)
+		}
+	}
+}
+
+func TestHeaderTemplateChecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"HeaderTemplate": ["^# Updated: (?P<date>.+)$", "^# Contact: (?P<email>.+)$"]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Config: c}
+	var messages []string
+	for i, line := range []string{
+		"# Updated: 2024-05-01",
+		"# Contact: eresources@library.example.edu",
+		"Title Good",
+		"URL https://good.example.com",
+		"",
+		"# Updated: 2024-13-01",
+		"Title Bad",
+		"# Contact: eresources@library.example.edu",
+		"URL https://bad.example.com",
+		"",
+	} {
+		messages = append(messages, Messages(linter.ProcessLineAt(line, fmt.Sprintf("config.txt:%v", i+1)))...)
+	}
+	expected := []string{
+		"Stanza \"Bad\" has a header comment \"# Updated: 2024-13-01\" with an invalid date, \"2024-13-01\" is not a date in the form YYYY-MM-DD (L3018)",
+		"Stanza \"Bad\" doesn't have a header comment matching \"^# Contact: (?P<email>.+)$\" (L4010)",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q", messages)
+	}
+}
+
+func TestReadConfigRequireBanners(t *testing.T) {
+	var tests = []struct {
+		content string
+		valid   bool
+	}{
+		{`{"RequireBanners": ["AutoLoginIPBanner"]}`, true},
+		{`{"RequireBanners": ["AutoLoginIP"]}`, false},
+		{`{"RequireBanners": ["ExcludeIPBanner"]}`, true},
+		{`{"RequireBanners": ["autologinipbanner"]}`, false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadConfig(path); (err == nil) != tt.valid {
+			t.Fatalf("ReadConfig() returned %v for %v", err, tt.content)
+		}
+	}
+}
+
+func TestTitleAliases(t *testing.T) {
+	var tests = []struct {
+		title    string
+		oclc     string
+		expected []string
+	}{
+		{"Title Local Name", "Docuseek2 (updated 20180101)", nil},
+		{"Title -Hide Local Name", "Docuseek2 (updated 20180101)", nil},
+		{"Title Local Name", "Docuseek2 (updated 20240101)",
+			[]string{"Source title doesn't match the title \"Docuseek2 (updated 20180101)\" this stanza was renamed from, you might need to update this stanza (L9002)"}},
+		{"Title Other Name", "Docuseek2 (updated 20180101)",
+			[]string{"Source title doesn't match, you might need to update this stanza (L9002)"}},
+	}
+	for _, tt := range tests {
+		linter := Linter{Config: Config{TitleAliases: map[string]string{"Local Name": "Docuseek2 (updated 20180101)"}}}
+		linter.State.OCLCTitle = tt.oclc
+		if messages := Messages(linter.ProcessLineAt(tt.title, "test:1")); !reflect.DeepEqual(messages, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q for %q", messages, tt.expected, tt.title)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"TitleAliases": {"Local Name": ""}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig(path); err == nil {
+		t.Fatal("ReadConfig() accepted an empty OCLC title")
+	}
+}
+
+func TestReadConfigStanzaFileName(t *testing.T) {
+	for content, valid := range map[string]bool{
+		`{"StanzaFileName": "{slug}*.txt"}`:  true,
+		`{"StanzaFileName": "database.txt"}`: false,
+		`{"StanzaFileName": "[{slug}.txt"}`:  false,
+	} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadConfig(path); (err == nil) != valid {
+			t.Fatalf("ReadConfig() returned error %v for %v", err, content)
+		}
+	}
+}
+
+func TestSourceHosts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body><pre>Title Wiki Title\nURL https://www.example.com</pre></body></html>")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		sourceHosts map[string]bool
+		title       string
+		expected    []string
+	}{
+		{nil, "",
+			[]string{"Error processsing Source line (L9003): source line isn't pointing to OCLC or a host in the SourceHosts setting"}},
+		{map[string]bool{serverURL.Host: false}, "", nil},
+		{map[string]bool{serverURL.Host: true}, "Wiki Title", nil},
+	}
+	for _, tt := range tests {
+		linter := Linter{Source: true, Client: server.Client(), Config: Config{SourceHosts: tt.sourceHosts}}
+		messages := Messages(linter.ProcessLineAt("# Source - "+server.URL+"/stanzas/example", "test:1"))
+		if !reflect.DeepEqual(messages, tt.expected) || linter.State.OCLCTitle != tt.title {
+			t.Fatalf("incorrect messages %q and title %q instead of %q and %q for %v", messages, linter.State.OCLCTitle, tt.expected, tt.title, tt.sourceHosts)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"SourceHosts": {"Wiki.Example.edu": true, "support.example.com": false}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadConfig(path)
+	if expected := map[string]bool{"wiki.example.edu": true, "support.example.com": false}; err != nil || !reflect.DeepEqual(c.SourceHosts, expected) {
+		t.Fatalf("ReadConfig() returned %v and %v instead of %v", c.SourceHosts, err, expected)
+	}
+	if err := os.WriteFile(path, []byte(`{"SourceHosts": {"https://wiki.example.edu": true}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig(path); err == nil {
+		t.Fatal("ReadConfig() accepted a URL as a Source host")
+	}
+}
+
+func TestSharedOrigins(t *testing.T) {
+	lines := []string{
+		"Title One",
+		"URL https://www.example.com",
+		"HJ https://doi.org",
+		"HJ https://cdn.example.net",
+		"HJ http://cdn.example.net",
+		"",
+		"Title Two",
+		"URL https://www.example.org",
+		"HJ https://dx.doi.org",
+		"HJ https://DOI.org:443",
+		"HJ https://cdn.example.net",
+		"HJ http://cdn.example.net",
+		"",
+	}
+	var tests = []struct {
+		sharedOrigins []string
+		expected      []string
+	}{
+		{nil, []string{
+			"Origin already seen at \"test:3\" (L2002)",
+			"Origin already seen at \"test:4\" (L2002)",
+			"Origin already seen at \"test:5\" (L2002)",
+		}},
+		{[]string{"doi.org", "https://cdn.example.net"}, []string{
+			"Origin already seen at \"test:5\" (L2002)",
+		}},
+	}
+	for _, tt := range tests {
+		linter := Linter{Config: Config{SharedOrigins: tt.sharedOrigins}}
+		var messages []string
+		for i, line := range lines {
+			for _, message := range Messages(linter.ProcessLineAt(line, fmt.Sprintf("test:%v", i+1))) {
+				if strings.HasSuffix(message, "(L2002)") {
+					messages = append(messages, message)
+				}
+			}
+		}
+		if !reflect.DeepEqual(messages, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q for %v", messages, tt.expected, tt.sharedOrigins)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	var configs = []struct {
+		content  string
+		expected []string
+	}{
+		{`{"SharedOrigins": [".DOI.org", "HTTPS://CDN.example.net:443/"]}`, []string{"doi.org", "https://cdn.example.net"}},
+		{`{"SharedOrigins": ["https://cdn.example.net/path"]}`, nil},
+		{`{"SharedOrigins": ["not a domain"]}`, nil},
+	}
+	for _, tt := range configs {
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := ReadConfig(path)
+		if (err == nil) != (tt.expected != nil) || !reflect.DeepEqual(c.SharedOrigins, tt.expected) && err == nil {
+			t.Fatalf("ReadConfig() returned %v and %v instead of %v for %v", c.SharedOrigins, err, tt.expected, tt.content)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	for content, valid := range map[string]bool{
+		`{"Messages": {"L2002": "{{.Message}}. Ask the e-resources team before adding a duplicate."}}`: true,
+		`{"Messages": {"L9001": "Directive inconnue {{index .Args 0}}"}}`:                              true,
+		`{"Messages": {"L0000": "{{.Message}}"}}`:                                                      false,
+		`{"Messages": {"L2002": "{{.Message"}}`:                                                        false,
+		`{"Messages": {"L2002": "{{.Mesage}}"}}`:                                                       false,
+	} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadConfig(path); (err == nil) != valid {
+			t.Fatalf("ReadConfig() returned error %v for %v", err, content)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"Messages": {"L9001": "Directive inconnue « {{index .Args 0}} »", "L5001": "{{.Title}}: {{index .Args 5}}"}}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{DirectiveCase: true, Config: c}
+	findings := linter.ProcessLineAt("FooBar baz", "test:1")
+	findings = append(findings, linter.ProcessLineAt("title Example", "test:2")...)
+	// The rule codes, and the fingerprints made from the linter's messages, don't change.
+	expected := []string{
+		"Directive inconnue « FooBar » (L9001)",
+		"\"title\" directive does not have the right letter casing. It should be replaced by \"Title\" (L5001)",
+	}
+	if !reflect.DeepEqual(Messages(findings), expected) {
+		t.Fatalf("incorrect messages %q instead of %q", Messages(findings), expected)
+	}
+	if findings[0].Code != "L9001" || findings[0].Fingerprint != Fingerprint("L9001", "", "FooBar baz", "") {
+		t.Fatalf("incorrect finding %+v", findings[0])
+	}
+}
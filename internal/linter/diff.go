@@ -0,0 +1,124 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffContext is the number of unchanged lines shown around each change in a diff.
+const DiffContext = 3
+
+// A diffLine is a line in a diff, with its kind: ' ' for unchanged lines, '-' for removed lines, and '+' for added lines.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the changes from the old lines to the new lines of the file at path, in the unified diff format.
+// If the lines are the same, the diff is empty.
+func UnifiedDiff(path string, old, new []string) string {
+	edits := diffLines(old, new)
+	var b strings.Builder
+	for start := 0; start < len(edits); {
+		// Find the next change.
+		for start < len(edits) && edits[start].kind == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %v\n+++ %v\n", path, path)
+		}
+		// Extend the hunk until there are more than twice the context lines without changes.
+		end := start
+		for unchanged := 0; end < len(edits) && unchanged <= 2*DiffContext; end++ {
+			if edits[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		for end > start && edits[end-1].kind == ' ' {
+			end--
+		}
+		first, last := max(start-DiffContext, 0), min(end+DiffContext, len(edits))
+		oldStart, newStart := 1, 1
+		for _, e := range edits[:first] {
+			if e.kind != '+' {
+				oldStart++
+			}
+			if e.kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, e := range edits[first:last] {
+			if e.kind != '+' {
+				oldCount++
+			}
+			if e.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%v,%v +%v,%v @@\n", oldStart, oldCount, newStart, newCount)
+		for _, e := range edits[first:last] {
+			fmt.Fprintf(&b, "%c%v\n", e.kind, e.text)
+		}
+		start = last
+	}
+	return b.String()
+}
+
+// diffLines returns the lines of a shortest edit from old to new, found with the longest common subsequence.
+// The common prefix and suffix are removed first, because fixes usually change a few lines in a long file.
+func diffLines(old, new []string) (edits []diffLine) {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	for _, line := range old[:prefix] {
+		edits = append(edits, diffLine{' ', line})
+	}
+	a, b := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, diffLine{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for _, line := range old[len(old)-suffix:] {
+		edits = append(edits, diffLine{' ', line})
+	}
+	return edits
+}
@@ -0,0 +1,24 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	old := strings.Split("a b c d e f g h i j k l m n o", " ")
+	new := strings.Split("a B c d e f g h i j k l n o p", " ")
+	expected := "--- test.txt\n+++ test.txt\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -10,6 +10,6 @@\n j\n k\n l\n-m\n n\n o\n+p\n"
+	if diff := UnifiedDiff("test.txt", old, new); diff != expected {
+		t.Fatalf("incorrect diff %q instead of %q", diff, expected)
+	}
+	if diff := UnifiedDiff("test.txt", old, old); diff != "" {
+		t.Fatalf("incorrect diff %q for unchanged lines", diff)
+	}
+}
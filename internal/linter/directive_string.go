@@ -183,7 +183,7 @@ func _() {
 	_ = x[XDebug-172]
 }
 
-const _Directive_name = "UndefinedAddUserHeaderAllowIPAllowVarsAnonymousURLAuditAuditPurgeAutoLoginIPAutoLoginIPBannerBinaryTimeoutBooks24x7SiteByteServeCASServiceURLChargeSetLatencyCharsetClientTimeoutConnectWindowCookieCookieFilterDbVarDbVar0DbVar1DbVar2DbVar3DbVar4DbVar5DbVar6DbVar7DbVar8DbVar9DenyIfRequestHeaderDescriptionDNSDomainDomainJavaScriptEBLSecretEbrarySiteEncryptVarExcludeIPExcludeIPBannerExtraLoginCookieFindFirstPortFormSelectFormSubmitFormVariableGartnerGroupHANameHAPeerHostHostJavaScriptHTTPHeaderHTTPMethodIdentifierIncludeFileIncludeIPInterfaceIntruderIPAttemptsIntruderLogIntruderUserAttemptsIntrusionAPILBPeerLocationLogFileLogFilterLogFormatLoginCookieDomainLoginCookieNameLoginMenuLoginPortLoginPortSSLLogSPUMaxConcurrentTransfersMaxLifetimeMaxSessionsMaxVirtualHostsMessagesFileMetaFindMimeFilterNameNeverProxyOption AcceptX-Forwarded-ForOption AllowSendGZipOption AllowWebSubdirectoriesOption AnyDNSHostnameOption BlockCountryChangeOption CookieOption CookiePassThroughOption CSRFTokenOption DisableSSL40bitOption DisableSSL56bitOption DisableSSLv2Option DomainCookieOnlyOption ebraryUnencodedTokensOption ExcludeIPMenuOption ForceHTTPSAdminOption ForceHTTPSLoginOption ForceWildcardCertificateOption HideEZproxyOption HttpsHyphensOption I choose to use Domain lines that threaten the security of my networkOption IgnoreWildcardCertificateOption IPv6Option LoginReplaceGroupsOption LogRefererOption LogSAMLOption LogSessionOption LogSPUEditOption LogUserOption MenuByGroupsOption MetaEZproxyRewritingOption NoCookieOption NoHideEZproxyOption NoHttpsHyphensOption NoMetaEZproxyRewritingOption NoProxyFTPOption NoUTF16Option NoX-Forwarded-ForOption ProxyByHostnameOption ProxyFTPOption RecordPeaksOption RedirectUnknownOption ReferInHostnameOption RelaxedRADIUSOption RequireAuthenticateOption SafariCookiePatchOption StatusUserOption TicketIgnoreExcludeIPOption UnsafeRedirectUnknownOption UsernameCaretNOption UTF16Option X-Forwarded-ForOverDriveSitePDFRefreshPDFRefreshPostPDFRefreshPrePidFileProxyProxyHostnameEditProxySSLRADIUSRetryRedirectSafeRefererRejectIPRemoteIPHeaderRemoteIPInternalProxyRemoteIPTrustedProxyRemoteTimeoutReplaceRunAsShibbolethDisableShibbolethMetadataSkipPortSPUEditSPUEditVarSQLiteTempDirSSLCipherSuiteSSLHonorCipherOrderSSLOpenSSLConfCmdSSOUsernameTitleTokenKeyTokenSignatureKeyUMaskURLURLAppendEncodedURLRedirectURLRedirectAppendURLRedirectAppendEncodedUsageLimitValidateXDebug"
+const _Directive_name = "UndefinedAddUserHeaderAllowIPAllowVarsAnonymousURLAuditAuditPurgeAutoLoginIPAutoLoginIPBannerBinaryTimeoutBooks24x7SiteByteServeCASServiceURLChargeSetLatencyCharsetClientTimeoutConnectWindowCookieCookieFilterDbVarDbVar0DbVar1DbVar2DbVar3DbVar4DbVar5DbVar6DbVar7DbVar8DbVar9DenyIfRequestHeaderDescriptionDNSDomainDomainJavaScriptEBLSecretebrarySiteEncryptVarExcludeIPExcludeIPBannerExtraLoginCookieFindFirstPortFormSelectFormSubmitFormVariableGartnerGroupHANameHAPeerHostHostJavaScriptHTTPHeaderHTTPMethodIdentifierIncludeFileIncludeIPInterfaceIntruderIPAttemptsIntruderLogIntruderUserAttemptsIntrusionAPILBPeerLocationLogFileLogFilterLogFormatLoginCookieDomainLoginCookieNameLoginMenuLoginPortLoginPortSSLLogSPUMaxConcurrentTransfersMaxLifetimeMaxSessionsMaxVirtualHostsMessagesFileMetaFindMimeFilterNameNeverProxyOption AcceptX-Forwarded-ForOption AllowSendGZipOption AllowWebSubdirectoriesOption AnyDNSHostnameOption BlockCountryChangeOption CookieOption CookiePassThroughOption CSRFTokenOption DisableSSL40bitOption DisableSSL56bitOption DisableSSLv2Option DomainCookieOnlyOption ebraryUnencodedTokensOption ExcludeIPMenuOption ForceHTTPSAdminOption ForceHTTPSLoginOption ForceWildcardCertificateOption HideEZproxyOption HttpsHyphensOption I choose to use Domain lines that threaten the security of my networkOption IgnoreWildcardCertificateOption IPv6Option LoginReplaceGroupsOption LogRefererOption LogSAMLOption LogSessionOption LogSPUEditOption LogUserOption MenuByGroupsOption MetaEZproxyRewritingOption NoCookieOption NoHideEZproxyOption NoHttpsHyphensOption NoMetaEZproxyRewritingOption NoProxyFTPOption NoUTF16Option NoX-Forwarded-ForOption ProxyByHostnameOption ProxyFTPOption RecordPeaksOption RedirectUnknownOption ReferInHostnameOption RelaxedRADIUSOption RequireAuthenticateOption SafariCookiePatchOption StatusUserOption TicketIgnoreExcludeIPOption UnsafeRedirectUnknownOption UsernameCaretNOption UTF16Option X-Forwarded-ForOverDriveSitePDFRefreshPDFRefreshPostPDFRefreshPrePidFileProxyProxyHostnameEditProxySSLRADIUSRetryRedirectSafeRefererRejectIPRemoteIPHeaderRemoteIPInternalProxyRemoteIPTrustedProxyRemoteTimeoutReplaceRunAsShibbolethDisableShibbolethMetadataSkipPortSPUEditSPUEditVarSQLiteTempDirSSLCipherSuiteSSLHonorCipherOrderSSLOpenSSLConfCmdSSOUsernameTitleTokenKeyTokenSignatureKeyUMaskURLURLAppendEncodedURLRedirectURLRedirectAppendURLRedirectAppendEncodedUsageLimitValidateXDebug"
 
 var _Directive_index = [...]uint16{0, 9, 22, 29, 38, 50, 55, 65, 76, 93, 106, 119, 128, 141, 157, 164, 177, 190, 196, 208, 213, 219, 225, 231, 237, 243, 249, 255, 261, 267, 273, 292, 303, 306, 312, 328, 337, 347, 357, 366, 381, 397, 401, 410, 420, 430, 442, 449, 454, 460, 466, 470, 484, 494, 504, 514, 525, 534, 543, 561, 572, 592, 604, 610, 618, 625, 634, 643, 660, 675, 684, 693, 705, 711, 733, 744, 755, 770, 782, 790, 800, 804, 814, 842, 862, 891, 912, 937, 950, 974, 990, 1012, 1034, 1053, 1076, 1104, 1124, 1146, 1168, 1199, 1217, 1236, 1312, 1344, 1355, 1380, 1397, 1411, 1428, 1445, 1459, 1478, 1505, 1520, 1540, 1561, 1590, 1607, 1621, 1645, 1667, 1682, 1700, 1722, 1744, 1764, 1790, 1814, 1831, 1859, 1887, 1908, 1920, 1942, 1955, 1965, 1979, 1992, 1999, 2004, 2021, 2029, 2040, 2052, 2059, 2067, 2081, 2102, 2122, 2135, 2142, 2147, 2164, 2182, 2190, 2197, 2207, 2220, 2234, 2253, 2270, 2281, 2286, 2294, 2311, 2316, 2319, 2335, 2346, 2363, 2387, 2397, 2405, 2411}
 
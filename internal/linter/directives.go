@@ -4,6 +4,9 @@
 package linter
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -48,7 +51,7 @@ const (
 	Domain
 	DomainJavaScript
 	EBLSecret
-	EbrarySite
+	EbrarySite // ebrarySite
 	EncryptVar
 	ExcludeIP
 	ExcludeIPBanner
@@ -187,204 +190,89 @@ const (
 	XDebug
 )
 
-var LabelToDirective = map[string]Directive{ //nolint:gochecknoglobals
-	"A":                               AutoLoginIP,
-	"AddUserHeader":                   AddUserHeader,
-	"AllowIP":                         AllowIP,
-	"AllowVars":                       AllowVars,
-	"AnonymousURL":                    AnonymousURL,
-	"Audit":                           Audit,
-	"AuditPurge":                      AuditPurge,
-	"AutoLoginIP":                     AutoLoginIP,
-	"AutoLoginIPBanner":               AutoLoginIPBanner,
-	"BinaryTimeout":                   BinaryTimeout,
-	"Books24x7Site":                   Books24x7Site,
-	"ByteServe":                       ByteServe,
-	"CASServiceURL":                   CASServiceURL,
-	"ChargeSetLatency":                ChargeSetLatency,
-	"Charset":                         Charset,
-	"ClientTimeout":                   ClientTimeout,
-	"ConnectWindow":                   ConnectWindow,
-	"Cookie":                          Cookie,
-	"CookieFilter":                    CookieFilter,
-	"D":                               Domain,
-	"DbVar":                           DbVar,
-	"DbVar0":                          DbVar0,
-	"DbVar1":                          DbVar1,
-	"DbVar2":                          DbVar2,
-	"DbVar3":                          DbVar3,
-	"DbVar4":                          DbVar4,
-	"DbVar5":                          DbVar5,
-	"DbVar6":                          DbVar6,
-	"DbVar7":                          DbVar7,
-	"DbVar8":                          DbVar8,
-	"DbVar9":                          DbVar9,
-	"DenyIfRequestHeader":             DenyIfRequestHeader,
-	"Description":                     Description,
-	"DJ":                              DomainJavaScript,
-	"DNS":                             DNS,
-	"Domain":                          Domain,
-	"DomainJavaScript":                DomainJavaScript,
-	"E":                               ExcludeIP,
-	"EBLSecret":                       EBLSecret,
-	"ebrarySite":                      EbrarySite,
-	"EncryptVar":                      EncryptVar,
-	"ExcludeIP":                       ExcludeIP,
-	"ExcludeIPBanner":                 ExcludeIPBanner,
-	"ExtraLoginCookie":                ExtraLoginCookie,
-	"Find":                            Find,
-	"FirstPort":                       FirstPort,
-	"FormSelect":                      FormSelect,
-	"FormSubmit":                      FormSubmit,
-	"FormVariable":                    FormVariable,
-	"Gartner":                         Gartner,
-	"Group":                           Group,
-	"H":                               Host,
-	"HAName":                          HAName,
-	"HAPeer":                          HAPeer,
-	"HJ":                              HostJavaScript,
-	"Host":                            Host,
-	"HostJavaScript":                  HostJavaScript,
-	"HTTPHeader":                      HTTPHeader,
-	"HTTPMethod":                      HTTPMethod,
-	"I":                               IncludeIP,
-	"Identifier":                      Identifier,
-	"IncludeFile":                     IncludeFile,
-	"IncludeIP":                       IncludeIP,
-	"Interface":                       Interface,
-	"IntruderIPAttempts":              IntruderIPAttempts,
-	"IntruderLog":                     IntruderLog,
-	"IntruderUserAttempts":            IntruderUserAttempts,
-	"IntrusionAPI":                    IntrusionAPI,
-	"LBPeer":                          LBPeer,
-	"Location":                        Location,
-	"LogFile":                         LogFile,
-	"LogFilter":                       LogFilter,
-	"LogFormat":                       LogFormat,
-	"LoginCookieDomain":               LoginCookieDomain,
-	"LoginCookieName":                 LoginCookieName,
-	"LoginMenu":                       LoginMenu,
-	"LoginPort":                       LoginPort,
-	"LoginPortSSL":                    LoginPortSSL,
-	"LogSPU":                          LogSPU,
-	"MaxConcurrentTransfers":          MaxConcurrentTransfers,
-	"MaxLifetime":                     MaxLifetime,
-	"MaxSessions":                     MaxSessions,
-	"MaxVirtualHosts":                 MaxVirtualHosts,
-	"MC":                              MaxConcurrentTransfers,
-	"MessagesFile":                    MessagesFile,
-	"MetaFind":                        MetaFind,
-	"MimeFilter":                      MimeFilter,
-	"ML":                              MaxLifetime,
-	"MS":                              MaxSessions,
-	"MV":                              MaxVirtualHosts,
-	"Name":                            Name,
-	"NeverProxy":                      NeverProxy,
-	"Option AcceptX-Forwarded-For":    OptionAcceptXForwardedFor,
-	"Option AllowSendGZip":            OptionAllowSendGZip,
-	"Option AllowWebSubdirectories":   OptionAllowWebSubdirectories,
-	"Option AnyDNSHostname":           OptionAnyDNSHostname,
-	"Option BlockCountryChange":       OptionBlockCountryChange,
-	"Option Cookie":                   OptionCookie,
-	"Option CookiePassThrough":        OptionCookiePassThrough,
-	"Option CSRFToken":                OptionCSRFToken,
-	"Option DisableSSL40bit":          OptionDisableSSL40bit,
-	"Option DisableSSL56bit":          OptionDisableSSL56bit,
-	"Option DisableSSLv2":             OptionDisableSSLv2,
-	"Option DomainCookieOnly":         OptionDomainCookieOnly,
-	"Option ebraryUnencodedTokens":    OptionEbraryUnencodedTokens,
-	"Option ExcludeIPMenu":            OptionExcludeIPMenu,
-	"Option ForceHTTPSAdmin":          OptionForceHTTPSAdmin,
-	"Option ForceHTTPSLogin":          OptionForceHTTPSLogin,
-	"Option ForceWildcardCertificate": OptionForceWildcardCertificate,
-	"Option HideEZproxy":              OptionHideEZproxy,
-	"Option HttpsHyphens":             OptionHttpsHyphens,
-	"Option I choose to use Domain lines that threaten the security of my network": OptionIChooseToUseDomainLinesThatThreatenTheSecurityOfMyNetwork,
-	"Option IgnoreWildcardCertificate":                                             OptionIgnoreWildcardCertificate,
-	"Option IPv6":                                                                  OptionIPv6,
-	"Option LoginReplaceGroups":                                                    OptionLoginReplaceGroups,
-	"Option LogReferer":                                                            OptionLogReferer,
-	"Option LogSAML":                                                               OptionLogSAML,
-	"Option LogSession":                                                            OptionLogSession,
-	"Option LogSPUEdit":                                                            OptionLogSPUEdit,
-	"Option LogUser":                                                               OptionLogUser,
-	"Option MenuByGroups":                                                          OptionMenuByGroups,
-	"Option MetaEZproxyRewriting":                                                  OptionMetaEZproxyRewriting,
-	"Option NoCookie":                                                              OptionNoCookie,
-	"Option NoHideEZproxy":                                                         OptionNoHideEZproxy,
-	"Option NoHttpsHyphens":                                                        OptionNoHttpsHyphens,
-	"Option NoMetaEZproxyRewriting":                                                OptionNoMetaEZproxyRewriting,
-	"Option NoProxyFTP":                                                            OptionNoProxyFTP,
-	"Option NoUTF16":                                                               OptionNoUTF16,
-	"Option NoX-Forwarded-For":                                                     OptionNoXForwardedFor,
-	"Option ProxyByHostname":                                                       OptionProxyByHostname,
-	"Option ProxyFTP":                                                              OptionProxyFTP,
-	"Option RecordPeaks":                                                           OptionRecordPeaks,
-	"Option RedirectUnknown":                                                       OptionRedirectUnknown,
-	"Option ReferInHostname":                                                       OptionReferInHostname,
-	"Option RelaxedRADIUS":                                                         OptionRelaxedRADIUS,
-	"Option RequireAuthenticate":                                                   OptionRequireAuthenticate,
-	"Option SafariCookiePatch":                                                     OptionSafariCookiePatch,
-	"Option StatusUser":                                                            OptionStatusUser,
-	"Option TicketIgnoreExcludeIP":                                                 OptionTicketIgnoreExcludeIP,
-	"Option UnsafeRedirectUnknown":                                                 OptionUnsafeRedirectUnknown,
-	"Option UsernameCaretN":                                                        OptionUsernameCaretN,
-	"Option UTF16":                                                                 OptionUTF16,
-	"Option X-Forwarded-For":                                                       OptionXForwardedFor,
-	"OverDriveSite":                                                                OverDriveSite,
-	"PDFRefresh":                                                                   PDFRefresh,
-	"PDFRefreshPost":                                                               PDFRefreshPost,
-	"PDFRefreshPre":                                                                PDFRefreshPre,
-	"PHE":                                                                          ProxyHostnameEdit,
-	"PidFile":                                                                      PidFile,
-	"PIDFile":                                                                      PidFile,
-	"Proxy":                                                                        Proxy,
-	"ProxyHostnameEdit":                                                            ProxyHostnameEdit,
-	"ProxySSL":                                                                     ProxySSL,
-	"RADIUSRetry":                                                                  RADIUSRetry,
-	"RedirectSafe":                                                                 RedirectSafe,
-	"Referer":                                                                      Referer,
-	"RejectIP":                                                                     RejectIP,
-	"RemoteIPHeader":                                                               RemoteIPHeader,
-	"RemoteIPInternalProxy":                                                        RemoteIPInternalProxy,
-	"RemoteIPTrustedProxy":                                                         RemoteIPTrustedProxy,
-	"RemoteTimeout":                                                                RemoteTimeout,
-	"Replace":                                                                      Replace,
-	"RunAs":                                                                        RunAs,
-	"ShibbolethDisable":                                                            ShibbolethDisable,
-	"ShibbolethMetadata":                                                           ShibbolethMetadata,
-	"SkipPort":                                                                     SkipPort,
-	"SPUEdit":                                                                      SPUEdit,
-	"SPUEditVar":                                                                   SPUEditVar,
-	"SQLiteTempDir":                                                                SQLiteTempDir,
-	"SSLCipherSuite":                                                               SSLCipherSuite,
-	"SSLHonorCipherOrder":                                                          SSLHonorCipherOrder,
-	"SSLOpenSSLConfCmd":                                                            SSLOpenSSLConfCmd,
-	"SSOUsername":                                                                  SSOUsername,
-	"T":                                                                            Title,
-	"Title":                                                                        Title,
-	"TokenKey":                                                                     TokenKey,
-	"TokenSignatureKey":                                                            TokenSignatureKey,
-	"U":                                                                            URL,
-	"UMask":                                                                        UMask,
-	"URL":                                                                          URL,
-	"URLAppendEncoded ":                                                            URLAppendEncoded,
-	"URLRedirect":                                                                  URLRedirect,
-	"URLRedirectAppend":                                                            URLRedirectAppend,
-	"URLRedirectAppendEncoded":                                                     URLRedirectAppendEncoded,
-	"UsageLimit":                                                                   UsageLimit,
-	"Validate":                                                                     Validate,
-	"XDebug":                                                                       XDebug,
-}
+// LabelToDirective maps the labels EZproxy accepts to their Directive. It is made when the package is initialized,
+// from the canonical label of each Directive, which is its String, and LabelAbbreviations, so labels have one source of truth.
+var LabelToDirective = map[string]Directive{} //nolint:gochecknoglobals
 
+// LowercaseLabelToDirective maps lowercase labels to their Directive, since EZproxy ignores the case of labels.
 var LowercaseLabelToDirective = map[string]Directive{} //nolint:gochecknoglobals
 
+// LowercaseLabelToLabel maps lowercase labels to the labels in LabelToDirective, which have the canonical casing.
+var LowercaseLabelToLabel = map[string]string{} //nolint:gochecknoglobals
+
+// LabelAbbreviations returns the abbreviated labels EZproxy accepts for some directives.
+func LabelAbbreviations() map[Directive]string {
+	return map[Directive]string{
+		AutoLoginIP:            "A",
+		Domain:                 "D",
+		DomainJavaScript:       "DJ",
+		ExcludeIP:              "E",
+		Host:                   "H",
+		HostJavaScript:         "HJ",
+		IncludeIP:              "I",
+		MaxConcurrentTransfers: "MC",
+		MaxLifetime:            "ML",
+		MaxSessions:            "MS",
+		MaxVirtualHosts:        "MV",
+		ProxyHostnameEdit:      "PHE",
+		Title:                  "T",
+		URL:                    "U",
+	}
+}
+
+// Directives returns every Directive except Undefined, in order.
+func Directives() (directives []Directive) {
+	// The String of a value past the last Directive is made by stringer, like "Directive(173)".
+	for d := Undefined + 1; !strings.HasPrefix(d.String(), "Directive("); d++ {
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// CanonicalLabel returns the full label for a directive, with the casing EZproxy's documentation uses,
+// like "HostJavaScript" for HostJavaScript. It is empty for Undefined and values which aren't directives.
+func CanonicalLabel(d Directive) string {
+	if d == Undefined || strings.HasPrefix(d.String(), "Directive(") {
+		return ""
+	}
+	return d.String()
+}
+
+// addLabel adds a label for a directive to the label maps. A label which is empty, has surrounding whitespace,
+// or is already a label in any case panics, since the label maps would silently drift from the directives.
+func addLabel(label string, directive Directive) {
+	if label == "" || label != strings.TrimSpace(label) {
+		panic(fmt.Sprintf("label %q for directive %v is empty or has surrounding whitespace", label, int(directive)))
+	}
+	if existing, ok := LowercaseLabelToLabel[strings.ToLower(label)]; ok {
+		panic(fmt.Sprintf("label %q for directive %v duplicates the label %q", label, int(directive), existing))
+	}
+	LabelToDirective[label] = directive
+	LowercaseLabelToDirective[strings.ToLower(label)] = directive
+	LowercaseLabelToLabel[strings.ToLower(label)] = label
+}
+
 func init() {
-	for label, directive := range LabelToDirective {
-		LowercaseLabelToDirective[strings.ToLower(label)] = directive
+	for _, directive := range Directives() {
+		addLabel(CanonicalLabel(directive), directive)
+	}
+	abbreviations := LabelAbbreviations()
+	for _, directive := range slices.Sorted(maps.Keys(abbreviations)) {
+		addLabel(abbreviations[directive], directive)
 	}
 }
 
 func (d Directive) MarshalText() ([]byte, error) {
 	return []byte(d.String()), nil
 }
+
+// UnmarshalText sets the directive from its name, as written by MarshalText.
+func (d *Directive) UnmarshalText(text []byte) error {
+	for directive := Undefined; directive < Directive(len(_Directive_index)-1); directive++ {
+		if directive.String() == string(text) {
+			*d = directive
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown directive %q", text)
+}
@@ -0,0 +1,63 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalLabel(t *testing.T) {
+	tests := []struct {
+		directive Directive
+		expected  string
+	}{
+		{HostJavaScript, "HostJavaScript"},
+		{OptionXForwardedFor, "Option X-Forwarded-For"},
+		{EbrarySite, "ebrarySite"},
+		{URLAppendEncoded, "URLAppendEncoded"},
+		{Undefined, ""},
+		{Directive(len(Directives()) + 1), ""},
+	}
+	for _, tt := range tests {
+		if got := CanonicalLabel(tt.directive); got != tt.expected {
+			t.Errorf("incorrect canonical label %q for %v instead of %q", got, int(tt.directive), tt.expected)
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	// Every directive has its canonical label, and the abbreviated labels are the only other labels.
+	if len(LabelToDirective) != len(Directives())+len(LabelAbbreviations()) {
+		t.Fatalf("%v labels for %v directives and %v abbreviations", len(LabelToDirective), len(Directives()), len(LabelAbbreviations()))
+	}
+	for _, d := range Directives() {
+		label := CanonicalLabel(d)
+		if directive, ok := LabelDirective(label); !ok || directive != d {
+			t.Errorf("canonical label %q doesn't map to directive %v", label, int(d))
+		}
+		if canonical := LowercaseLabelToLabel[strings.ToLower(label)]; canonical != label {
+			t.Errorf("incorrect casing %q for %q", canonical, label)
+		}
+	}
+	for _, label := range []string{"urlappendencoded", "PIDFile", "EBRARYSITE"} {
+		if _, ok := LabelDirective(label); !ok {
+			t.Errorf("label %q isn't known in a different case", label)
+		}
+	}
+}
+
+func TestAddLabelDuplicate(t *testing.T) {
+	for _, label := range []string{"title", "Hj", "Title ", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic adding label %q", label)
+				}
+			}()
+			addLabel(label, Title)
+		}()
+	}
+}
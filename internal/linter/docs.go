@@ -0,0 +1,73 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"strings"
+)
+
+// DocumentationBase is the address of OCLC's documentation for config directives.
+const DocumentationBase = "https://help.oclc.org/Library_Management/EZproxy/Configure_resources/"
+
+// DocumentationPages returns the pages of OCLC's documentation for directives whose page isn't named after the directive,
+// like the page for Host, which also documents its abbreviation.
+func DocumentationPages() map[Directive]string {
+	pages := map[Directive]string{
+		Domain:                   "Domain_D",
+		DomainJavaScript:         "DomainJavaScript_DJ",
+		Find:                     "Find_Replace",
+		Host:                     "Host_H",
+		HostJavaScript:           "HostJavaScript_HJ",
+		OptionHttpsHyphens:       "Option_HttpsHyphens_Option_NoHttpsHyphens",
+		OptionNoHttpsHyphens:     "Option_HttpsHyphens_Option_NoHttpsHyphens",
+		Replace:                  "Find_Replace",
+		URL:                      "URL_version_1",
+		URLAppendEncoded:         "URL_version_2",
+		URLRedirect:              "URL_version_2",
+		URLRedirectAppend:        "URL_version_2",
+		URLRedirectAppendEncoded: "URL_version_2",
+	}
+	for _, d := range []Directive{DbVar0, DbVar1, DbVar2, DbVar3, DbVar4, DbVar5, DbVar6, DbVar7, DbVar8, DbVar9} {
+		pages[d] = "DbVar0_DbVar9"
+	}
+	return pages
+}
+
+// DocumentationURL returns the address of OCLC's documentation for a directive.
+// Pages are named after the directive, with underscores for spaces, unless they are in DocumentationPages.
+func DocumentationURL(d Directive) string {
+	if d == Undefined {
+		return ""
+	}
+	page, ok := DocumentationPages()[d]
+	if !ok {
+		page = strings.ReplaceAll(d.String(), " ", "_")
+	}
+	return DocumentationBase + page
+}
+
+// LineDocumentationURL returns the address of OCLC's documentation for the directive on a line.
+// URL directives with qualifiers link to the page for their version. Lines without a known directive,
+// like comments, don't have a link.
+func LineDocumentationURL(line string) string {
+	label, _ := SplitLabel(line)
+	if strings.EqualFold(label, "Option") {
+		label = strings.Join(strings.Fields(line), " ")
+	}
+	directive, ok := LabelDirective(label)
+	if !ok {
+		return ""
+	}
+	if directive == URL {
+		if u, err := ParseURLDirective(line); err == nil {
+			switch {
+			case u.Form != "":
+				return DocumentationBase + "URL_version_3"
+			case u.Refresh || u.Redirect || u.Append:
+				return DocumentationBase + "URL_version_2"
+			}
+		}
+	}
+	return DocumentationURL(directive)
+}
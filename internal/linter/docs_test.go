@@ -0,0 +1,35 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"testing"
+)
+
+func TestLineDocumentationURL(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"Title JSTOR", DocumentationBase + "Title"},
+		{"T JSTOR", DocumentationBase + "Title"},
+		{"HJ www.jstor.org", DocumentationBase + "HostJavaScript_HJ"},
+		{"dj jstor.org", DocumentationBase + "DomainJavaScript_DJ"},
+		{"DbVar3 example", DocumentationBase + "DbVar0_DbVar9"},
+		{"Option NoHttpsHyphens", DocumentationBase + "Option_HttpsHyphens_Option_NoHttpsHyphens"},
+		{"Option  MetaEZproxyRewriting", DocumentationBase + "Option_MetaEZproxyRewriting"},
+		{"URL https://www.jstor.org/", DocumentationBase + "URL_version_1"},
+		{"URL -Redirect jstor https://www.jstor.org/", DocumentationBase + "URL_version_2"},
+		{"URL -Form=post jstor https://www.jstor.org/login", DocumentationBase + "URL_version_3"},
+		{"# Source - https://help.oclc.org/", ""},
+		{"FooBar baz", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := LineDocumentationURL(tt.line); got != tt.expected {
+			t.Errorf("incorrect documentation for %q, %q instead of %q", tt.line, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// A Tenant is one institution's config in a consortium, like a root of a manifest.
+type Tenant struct {
+	Name    string
+	Stanzas [][]ResolvedLine
+}
+
+// A StanzaDrift is a stanza which more than one tenant uses, in different versions.
+// The most common version is first, since it's usually the one to push to the other tenants.
+type StanzaDrift struct {
+	Title    string
+	Versions []StanzaVersion
+}
+
+// A StanzaVersion is one version of a stanza, and the tenants which use it.
+// Added and Removed are the lines which differ from the most common version.
+type StanzaVersion struct {
+	Tenants   []string
+	Locations []string
+	Added     []string `json:",omitempty"`
+	Removed   []string `json:",omitempty"`
+	lines     []string
+}
+
+// DriftReport compares the stanzas the tenants have in common, and returns those which differ between tenants.
+// Stanzas are matched by title, ignoring case and "-Hide", or by starting point URL if they don't have a title.
+// Like CommunityCheck, comments, whitespace, and the order of lines are not compared.
+// If a tenant has several stanzas with the same title, the first is used.
+func DriftReport(tenants []Tenant) (drift []StanzaDrift) {
+	var keys []string
+	titles := map[string]string{}
+	versions := map[string][]StanzaVersion{}
+	for _, tenant := range tenants {
+		seen := map[string]bool{}
+		for _, stanza := range tenant.Stanzas {
+			s := NewCommunityStanza(stanza)
+			title := strings.TrimSpace(strings.TrimPrefix(s.Title, "-Hide "))
+			key := strings.ToLower(cmp.Or(title, s.URL))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := titles[key]; !ok {
+				keys = append(keys, key)
+				titles[key] = cmp.Or(title, s.URL)
+			}
+			lines := slices.Sorted(slices.Values(s.Lines))
+			i := slices.IndexFunc(versions[key], func(v StanzaVersion) bool { return slices.Equal(v.lines, lines) })
+			if i == -1 {
+				versions[key] = append(versions[key], StanzaVersion{lines: lines})
+				i = len(versions[key]) - 1
+			}
+			versions[key][i].Tenants = append(versions[key][i].Tenants, tenant.Name)
+			versions[key][i].Locations = append(versions[key][i].Locations, s.At)
+		}
+	}
+	for _, key := range keys {
+		v := versions[key]
+		if len(v) < 2 {
+			continue
+		}
+		// The sort is stable, so versions used by the same number of tenants stay in config order.
+		slices.SortStableFunc(v, func(a, b StanzaVersion) int { return cmp.Compare(len(b.Tenants), len(a.Tenants)) })
+		for i := 1; i < len(v); i++ {
+			v[i].Added, v[i].Removed = lineDifference(v[0].lines, v[i].lines)
+		}
+		drift = append(drift, StanzaDrift{Title: titles[key], Versions: v})
+	}
+	return drift
+}
+
+// lineDifference returns the lines which are only in b, and the lines which are only in a.
+// Repeated lines are counted, so a line which is in b twice and a once is added once.
+func lineDifference(a, b []string) (added, removed []string) {
+	removed = slices.Clone(a)
+	for _, line := range b {
+		if i := slices.Index(removed, line); i != -1 {
+			removed = slices.Delete(removed, i, i+1)
+		} else {
+			added = append(added, line)
+		}
+	}
+	if len(removed) == 0 {
+		removed = nil
+	}
+	return added, removed
+}
@@ -0,0 +1,46 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDriftReport(t *testing.T) {
+	jstor := ResolvedStanzas(resolveLines(
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"DJ jstor.org",
+		"",
+		"Title Only Here",
+		"URL https://only.example.com",
+	))
+	law := ResolvedStanzas(resolveLines(
+		"# A comment which isn't compared.",
+		"Title -Hide jstor",
+		"DomainJavaScript   jstor.org",
+		"URL https://www.jstor.org",
+		"Domain ithaka.org",
+	))
+	tenants := []Tenant{{Name: "main", Stanzas: jstor}, {Name: "law", Stanzas: law}, {Name: "branch", Stanzas: jstor}}
+	expected := []StanzaDrift{{
+		Title: "JSTOR",
+		Versions: []StanzaVersion{
+			{Tenants: []string{"main", "branch"}, Locations: []string{"config.txt:1", "config.txt:1"},
+				lines: []string{"DomainJavaScript jstor.org", "Title JSTOR", "URL https://www.jstor.org"}},
+			{Tenants: []string{"law"}, Locations: []string{"config.txt:2"},
+				Added:   []string{"Domain ithaka.org", "Title -Hide jstor"},
+				Removed: []string{"Title JSTOR"},
+				lines:   []string{"Domain ithaka.org", "DomainJavaScript jstor.org", "Title -Hide jstor", "URL https://www.jstor.org"}},
+		},
+	}}
+	if drift := DriftReport(tenants); !reflect.DeepEqual(drift, expected) {
+		t.Fatalf("incorrect drift %+v instead of %+v", drift, expected)
+	}
+	if drift := DriftReport(tenants[:1]); drift != nil {
+		t.Fatalf("expected no drift for one tenant, got %+v", drift)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A ResolvedLine is a line of a config file as EZproxy sees it.
+type ResolvedLine struct {
+	At        string    // The location of the line, or the first segment of a multiline.
+	Segments  int       // The number of lines which were joined to make the line.
+	Text      string    // The line as it was written, with multiline segments joined.
+	Line      string    // The line with the full label of its directive, if it has one.
+	Directive Directive // The directive the line maps to, if Known is true.
+	Known     bool
+	Comment   bool
+}
+
+// Separator reports whether the line ends a stanza.
+func (r ResolvedLine) Separator() bool {
+	return strings.TrimSpace(r.Text) == "" || r.Text == "#"
+}
+
+// Contains reports whether the line, or one of its multiline segments, is at the line number in filePath.
+func (r ResolvedLine) Contains(filePath string, lineNum int) bool {
+	file, first := SplitAt(r.At)
+	return filepath.Clean(file) == filepath.Clean(filePath) && lineNum >= first && lineNum < first+r.Segments
+}
+
+// Explain returns the line annotated with the directive it maps to.
+func (r ResolvedLine) Explain() string {
+	var annotation string
+	switch {
+	case r.Comment:
+		annotation = "comment"
+	case !r.Known:
+		annotation = "unknown directive"
+	default:
+		annotation = r.Directive.String()
+	}
+	if r.Segments > 1 {
+		annotation += fmt.Sprintf(", joined from %v lines", r.Segments)
+	}
+	if r.Text != r.Line {
+		annotation += fmt.Sprintf(", written as %q", r.Text)
+	}
+	return fmt.Sprintf("%v: %v ← %v", r.At, r.Line, annotation)
+}
+
+// ResolveLine finds the directive for a line, and rewrites the line with the full label of the directive.
+func ResolveLine(text, at string) ResolvedLine {
+	r := ResolvedLine{At: at, Segments: 1, Text: text, Line: text}
+	if strings.HasPrefix(strings.TrimSpace(text), "#") {
+		r.Comment = true
+		return r
+	}
+	label, argument := SplitLabel(text)
+	if strings.EqualFold(label, "Option") {
+		label, argument = "Option "+argument, ""
+	}
+	r.Directive, r.Known = LabelDirective(label)
+	if r.Known {
+		r.Line = strings.TrimSpace(CanonicalLabel(r.Directive) + " " + argument)
+	}
+	return r
+}
+
+// ResolveFile returns the lines of the file at filePath as EZproxy reads them,
+// with multiline segments joined and the lines of files referenced by IncludeFile directives in place of those directives.
+// IncludeFile paths which are not absolute are resolved from includeFileDirectory, or the parent directory of filePath if it is empty.
+func ResolveFile(filePath, includeFileDirectory string) ([]ResolvedLine, error) {
+	if includeFileDirectory == "" {
+		includeFileDirectory = filepath.Dir(filePath)
+	}
+	return resolveFile(filePath, includeFileDirectory, nil)
+}
+
+func resolveFile(filePath, includeFileDirectory string, parents []string) (resolved []ResolvedLine, err error) {
+	if slices.Contains(parents, filepath.Clean(filePath)) {
+		return resolved, fmt.Errorf("IncludeFile loop, %v includes itself", filePath)
+	}
+	parents = append(parents, filepath.Clean(filePath))
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return resolved, err
+	}
+	content, err = Decompress(filePath, content)
+	if err != nil {
+		return resolved, err
+	}
+	scanner := newScanner(bytes.NewReader(content))
+	lineNum := 0
+	segments := ""
+	segmentsAt := ""
+	segmentCount := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNum++
+		at := fmt.Sprintf("%v:%v", filePath, lineNum)
+
+		// Comments and empty lines are not part of multiline strings.
+		if segmentCount == 0 && (strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#")) {
+			resolved = append(resolved, ResolveLine(line, at))
+			continue
+		}
+		if segmentCount == 0 {
+			segmentsAt = at
+		}
+		segmentCount++
+		if strings.HasSuffix(line, "\\") {
+			segments += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		r := ResolveLine(segments+line, segmentsAt)
+		r.Segments = segmentCount
+		segments, segmentCount = "", 0
+
+		if r.Known && r.Directive == IncludeFile {
+			_, includeFilePath := SplitLabel(r.Text)
+			if !filepath.IsAbs(includeFilePath) {
+				includeFilePath = filepath.Join(includeFileDirectory, includeFilePath)
+			}
+			included, err := resolveFile(includeFilePath, includeFileDirectory, parents)
+			if err != nil {
+				return resolved, fmt.Errorf("error encountered when processing line %q at %v: %w", r.Text, r.At, err)
+			}
+			resolved = append(resolved, included...)
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, scanner.Err()
+}
+
+// ResolvedStanzas splits the resolved lines into stanzas, which are separated by empty lines or "#" lines.
+func ResolvedStanzas(lines []ResolvedLine) (stanzas [][]ResolvedLine) {
+	var stanza []ResolvedLine
+	for _, line := range lines {
+		if line.Separator() {
+			if len(stanza) > 0 {
+				stanzas = append(stanzas, stanza)
+			}
+			stanza = nil
+			continue
+		}
+		stanza = append(stanza, line)
+	}
+	if len(stanza) > 0 {
+		stanzas = append(stanzas, stanza)
+	}
+	return stanzas
+}
+
+// FindStanza finds the stanza which contains a line number in filePath, or which has a Title matching target.
+// Titles are matched without regard to case.
+func FindStanza(stanzas [][]ResolvedLine, filePath, target string) ([]ResolvedLine, error) {
+	lineNum, err := strconv.Atoi(target)
+	for _, stanza := range stanzas {
+		for _, line := range stanza {
+			if err == nil && line.Contains(filePath, lineNum) {
+				return stanza, nil
+			}
+			if err != nil && line.Known && line.Directive == Title && strings.EqualFold(TrimDirective(line.Text, Title), strings.TrimSpace(target)) {
+				return stanza, nil
+			}
+		}
+	}
+	if err == nil {
+		return nil, fmt.Errorf("no stanza found at line %v of %v", lineNum, filePath)
+	}
+	return nil, fmt.Errorf("no stanza found with the title %q", target)
+}
@@ -0,0 +1,60 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.txt")
+	included := filepath.Join(dir, "included.txt")
+	err := os.WriteFile(config, []byte("Title First\nURL https://first.example.com\n\nIncludeFile included.txt\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(included, []byte("# A comment\noption cookie\nT Second\nU https://second.example.com/\\\nsearch\nHJ second.example.com\nOption Cookie\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := ResolveFile(config, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stanzas := ResolvedStanzas(lines)
+	if len(stanzas) != 2 {
+		t.Fatalf("expected 2 stanzas, got %v", len(stanzas))
+	}
+
+	expected := []string{
+		included + ":1: # A comment ← comment",
+		included + ":2: Option Cookie ← Option Cookie, written as \"option cookie\"",
+		included + ":3: Title Second ← Title, written as \"T Second\"",
+		included + ":4: URL https://second.example.com/search ← URL, joined from 2 lines, written as \"U https://second.example.com/search\"",
+		included + ":6: HostJavaScript second.example.com ← HostJavaScript, written as \"HJ second.example.com\"",
+		included + ":7: Option Cookie ← Option Cookie",
+	}
+	for _, target := range []struct{ file, target string }{{included, "5"}, {config, "second"}} {
+		stanza, err := FindStanza(stanzas, target.file, target.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var explained []string
+		for _, line := range stanza {
+			explained = append(explained, line.Explain())
+		}
+		if !reflect.DeepEqual(explained, expected) {
+			t.Fatalf("incorrect explanation for %v, got %q", target.target, explained)
+		}
+	}
+
+	if _, err := FindStanza(stanzas, config, "3"); err == nil {
+		t.Fatal("expected an error when no stanza is at the line")
+	}
+}
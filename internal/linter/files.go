@@ -0,0 +1,39 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// IsGzip reports whether the file at filePath is compressed with gzip, based on its ".gz" extension.
+func IsGzip(filePath string) bool {
+	if u, err := url.Parse(filePath); err == nil && IsURL(filePath) {
+		filePath = u.Path
+	}
+	return strings.EqualFold(path.Ext(filePath), ".gz")
+}
+
+// Decompress returns the decompressed content of a file compressed with gzip, or the content unchanged for other files.
+func Decompress(filePath string, content []byte) ([]byte, error) {
+	if !IsGzip(filePath) {
+		return content, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress %v: %w", filePath, err)
+	}
+	defer r.Close()
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress %v: %w", filePath, err)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,147 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProcessGzipFile(t *testing.T) {
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	if _, err := io.WriteString(w, "Title JSTOR\nURL https://www.jstor.org\nFooBar baz \n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.txt.gz")
+	if err := os.WriteFile(path, compressed.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := Linter{Fix: true, Whitespace: true, Format: FormatJSON, Output: io.Discard}
+	count, err := linter.ProcessFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("found %v issues instead of 2: %+v", count, linter.Report.Findings)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, compressed.Bytes()) {
+		t.Fatal("compressed files should not be fixed")
+	}
+
+	if _, err := Decompress("config.txt.gz", []byte("Title JSTOR\n")); err == nil {
+		t.Fatal("expected an error for a file which is not compressed")
+	}
+}
+
+func TestFileSizeChecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "databases.txt")
+	content := "Title A\nURL https://a.example.com\n\nTitle B\nURL https://b.example.com\n\nTitle C\nURL https://c.example.com\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Pedantic: true, MaxFileStanzas: 2, MaxFileLines: 7, Format: FormatJSON, Output: io.Discard}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, f := range linter.Report.Findings {
+		if f.Code == "L5009" {
+			messages = append(messages, f.Message)
+		}
+	}
+	expected := []string{
+		"File has 3 stanzas, more than 2. Consider moving stanzas into their own files, included with IncludeFile directives (L5009)",
+		"File has 8 lines, more than 7. Consider moving stanzas into their own files, included with IncludeFile directives (L5009)",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+}
+
+func TestStanzaFileChecks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.txt":              "IncludeFile jstor-arts-sciences.txt\nIncludeFile science.txt\nIncludeFile databases.txt\n",
+		"jstor-arts-sciences.txt": "Title -Hide JSTOR (Arts & Sciences)\nURL https://www.jstor.org\n",
+		"science.txt":             "Title Science Direct\nURL https://www.sciencedirect.com\n",
+		"databases.txt":           "Title A\nURL https://a.example.com\n\nTitle B\nURL https://b.example.com\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linter := Linter{OneStanzaPerFile: true, FollowIncludeFile: true, Format: FormatJSON, Output: io.Discard}
+	if _, err := linter.ProcessFile(filepath.Join(dir, "config.txt")); err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, f := range linter.Report.Findings {
+		if f.Code == "L5010" {
+			messages = append(messages, f.Message)
+		}
+	}
+	expected := []string{
+		"File name \"science.txt\" does not match \"science-direct.txt\", made from the title of its stanza \"Science Direct\" (L5010)",
+		"File read with IncludeFile has 2 stanzas, it should have exactly one (L5010)",
+		"File name \"databases.txt\" does not match \"a.txt\", made from the title of its stanza \"A\" (L5010)",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+
+	linter = Linter{OneStanzaPerFile: true, Config: Config{StanzaFileName: "*{slug}*"}}
+	if m := linter.StanzaFileChecks("db/2024-science-direct.txt", "Science Direct", 1); m != nil {
+		t.Fatalf("incorrect messages %q for a file name matching the pattern", m)
+	}
+}
+
+func TestIncludedDuplicateCheck(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.txt": "IncludeFile jstor.txt\n\nTitle JSTOR\nURL https://www.jstor.org\n",
+		"jstor.txt":  "Title JSTOR\nURL https://www.jstor.org/action\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linter := Linter{FollowIncludeFile: true, Format: FormatJSON, Output: io.Discard}
+	if _, err := linter.ProcessFile(filepath.Join(dir, "config.txt")); err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, f := range linter.Report.Findings {
+		if f.Code == "L2013" {
+			messages = append(messages, f.Message)
+		}
+	}
+	configAt, includedAt := filepath.Join(dir, "config.txt"), filepath.Join(dir, "jstor.txt")
+	expected := []string{
+		fmt.Sprintf("Stanza title \"JSTOR\" is at %q and in the included file at %q, one of the stanzas might be left over "+
+			"from moving stanzas out of the config file (L2013)", configAt+":3", includedAt+":1"),
+		fmt.Sprintf("Stanza URL origin \"https://www.jstor.org\" is at %q and in the included file at %q, one of the stanzas might be left over "+
+			"from moving stanzas out of the config file (L2013)", configAt+":4", includedAt+":2"),
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+}
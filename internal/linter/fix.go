@@ -0,0 +1,182 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// A Fix describes how to change a line in a config file to resolve a warning.
+type Fix struct {
+	Delete bool     `json:",omitempty"` // Remove the line from the file.
+	Old    string   `json:",omitempty"` // If Delete is false, replace the first instance of Old in the line...
+	New    string   `json:",omitempty"` // ...with New.
+	Lines  []string `json:",omitempty"` // If set, replace the whole line with these lines.
+}
+
+// An Edit replaces the bytes from StartColumn on StartLine up to, but not including, EndColumn on EndLine with NewText.
+// Lines and columns are counted from one, and columns count bytes. An edit which removes a whole line
+// ends at the first column of the next line.
+type Edit struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+	NewText     string
+}
+
+// Edits returns the edits which apply the fix to the line with the given line number.
+// There are no edits if the text the fix replaces isn't in the line.
+func (f Fix) Edits(line string, lineNumber int) []Edit {
+	switch {
+	case f.Delete:
+		return []Edit{{StartLine: lineNumber, StartColumn: 1, EndLine: lineNumber + 1, EndColumn: 1}}
+	case len(f.Lines) > 0:
+		return []Edit{{StartLine: lineNumber, StartColumn: 1, EndLine: lineNumber, EndColumn: len(line) + 1, NewText: strings.Join(f.Lines, "\n")}}
+	}
+	i := strings.Index(line, f.Old)
+	if i == -1 {
+		return nil
+	}
+	return []Edit{{StartLine: lineNumber, StartColumn: i + 1, EndLine: lineNumber, EndColumn: i + len(f.Old) + 1, NewText: f.New}}
+}
+
+// Apply returns the lines which replace the line when the fix's edits are applied to it.
+// No lines are returned if the fix removes the line.
+func (f Fix) Apply(line string) []string {
+	for _, e := range f.Edits(line, 1) {
+		if e.EndLine > e.StartLine {
+			return nil
+		}
+		line = line[:e.StartColumn-1] + e.NewText + line[e.EndColumn-1:]
+	}
+	return strings.Split(line, "\n")
+}
+
+// AddFix records a fix for the line at the given location. The fix is attached to the findings for the line,
+// and is written to the file if fix mode is enabled. If the line already has a fix, the first fix is kept.
+func (l *Linter) AddFix(at string, fix Fix) {
+	if l.Fixes == nil {
+		l.Fixes = make(map[string]Fix)
+	}
+	if _, ok := l.Fixes[at]; !ok {
+		l.Fixes[at] = fix
+	}
+}
+
+// ApplyFixes returns a copy of lines with fixes applied.
+// The ats slice holds the location of each line, which is used to find its fix.
+func ApplyFixes(lines, ats []string, fixes map[string]Fix) (fixed []string, count int) {
+	for i, line := range lines {
+		fix, ok := fixes[ats[i]]
+		if !ok {
+			fixed = append(fixed, line)
+			continue
+		}
+		fixed = append(fixed, fix.Apply(line)...)
+		count++
+	}
+	return fixed, count
+}
+
+// writeFixes applies any fixes for lines in the file at filePath and writes the result back to the file.
+// The fixed lines are linted first, and nothing is written if the fixes would add issues.
+// The original line endings are preserved, and the original file is backed up if BackupDir is set. If Diff is set, the changes are printed as a diff for review instead.
+func (l *Linter) writeFixes(filePath string, content []byte, lines, ats []string) (count int, err error) {
+	defer l.Profile.Time(ProfileFixes)()
+	fixed, count := ApplyFixes(lines, ats, l.Fixes)
+	// Stanzas are sorted after the other fixes, which are found by the location of each line.
+	if l.Pedantic {
+		var moved int
+		fixed, moved = SortStanzas(fixed)
+		count += moved
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if added := l.addedIssues(lines, fixed); len(added) > 0 {
+		return 0, fmt.Errorf("the fixes for %v would add issues (%v), so the file was not changed", filePath, strings.Join(added, ", "))
+	}
+	if l.Diff {
+		fmt.Fprint(l.Output, UnifiedDiff(filePath, lines, fixed))
+		return count, nil
+	}
+	newline := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	output := strings.Join(fixed, newline)
+	if len(fixed) > 0 && bytes.HasSuffix(content, []byte("\n")) {
+		output += newline
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, err
+	}
+	// Back up the original file first, so the fixes can be reverted.
+	if l.BackupDir != "" {
+		if err := l.backupFile(filePath, content, []byte(output), info.Mode().Perm()); err != nil {
+			return 0, fmt.Errorf("unable to back up %v: %w", filePath, err)
+		}
+	}
+	return count, os.WriteFile(filePath, []byte(output), info.Mode().Perm())
+}
+
+// fixCheckLinter returns a linter with the same checks and settings as l, which lints lines in memory to validate fixes.
+// It is a copy of l, without the state of the run, since the lines are linted on their own.
+// It doesn't make network requests, follow IncludeFile directives, or write output, so it can't have issues the file doesn't.
+func (l *Linter) fixCheckLinter() *Linter {
+	check := *l
+	// Network requests and IncludeFile directives.
+	check.Source, check.SourceCompare, check.Client = false, false, nil
+	check.CommunityRepo, check.CommunityStanzas = "", nil
+	check.FollowIncludeFile = false
+	// Output, fixes, and the tools which watch the run.
+	check.Annotate, check.Highlight, check.Verbose, check.FailFast, check.Fix, check.Diff = false, false, false, "", false, false
+	check.Output, check.Writer = io.Discard, nil
+	check.Metadata, check.Profile, check.Cache, check.Progress = nil, nil, nil, nil
+	// The state of the run, which would otherwise be shared with l.
+	check.State, check.RunState = State{}, RunState{}
+	return &check
+}
+
+// countIssues lints the lines in memory and counts the issues with each code.
+func (l *Linter) countIssues(lines []string) map[string]int {
+	check := l.fixCheckLinter()
+	counts := map[string]int{}
+	// An empty line ends the last stanza, like the end of a file.
+	for i, line := range append(slices.Clone(lines), "") {
+		messages := check.processLine(line, fmt.Sprintf("fix:%v", i+1))
+		// The included file isn't read, but when l follows IncludeFile directives,
+		// it ends with an empty line which ends the stanza before it.
+		if l.FollowIncludeFile && check.processedIncludeFile(line) {
+			messages = append(messages, check.processLine("", fmt.Sprintf("fix:%v", i+1))...)
+		}
+		for _, message := range messages {
+			if MessageSeverity(message) != SeverityInfo {
+				counts[MessageCode(message)]++
+			}
+		}
+	}
+	return counts
+}
+
+// addedIssues returns the codes of the issues which the fixed lines have more of than the original lines,
+// to guard against a faulty fix corrupting a config. Issues are counted by code, since fixing a line
+// can change the messages of the issues on other lines, like a directive named in an ordering issue.
+func (l *Linter) addedIssues(lines, fixed []string) (added []string) {
+	before, after := l.countIssues(lines), l.countIssues(fixed)
+	for _, code := range slices.Sorted(maps.Keys(after)) {
+		if after[code] > before[code] {
+			added = append(added, code)
+		}
+	}
+	return added
+}
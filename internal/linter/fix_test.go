@@ -0,0 +1,284 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFixRedundantHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "JSTOR.txt")
+	content := "Title JSTOR\r\nURL https://www.jstor.org/\r\nHJ www.jstor.org\r\nDJ jstor.org\r\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{RedundantHosts: true, Fix: true, Output: io.Discard}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Title JSTOR\r\nURL https://www.jstor.org/\r\nDJ jstor.org\r\n"
+	if string(fixed) != expected {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, expected)
+	}
+}
+
+func TestFixDomainHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "JSTOR.txt")
+	content := "Title JSTOR\r\nURL https://www.jstor.org/\r\nH http://images.jstor.org\r\nD jstor.org\r\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{DomainHosts: true, Fix: true, Output: io.Discard}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Title JSTOR\r\nURL https://www.jstor.org/\r\nH http://images.jstor.org\r\nHost https://www.jstor.org\r\n"
+	if string(fixed) != expected {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, expected)
+	}
+}
+
+func TestFixDuplicateLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Wiley.txt")
+	content := "Title Wiley\nURL https://onlinelibrary.wiley.com\nDJ wiley.com\nHJ www.wileyonlinelibrary.com\nDJ wiley.com\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Fix: true, Output: io.Discard}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Title Wiley\nURL https://onlinelibrary.wiley.com\nDJ wiley.com\nHJ www.wileyonlinelibrary.com\n"
+	if string(fixed) != expected {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, expected)
+	}
+}
+
+func TestFixDuplicateLinesInDifferentPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "JSTOR.txt")
+	content := "Option DomainCookieOnly\nTitle JSTOR\nURL https://www.jstor.org/\nOption DomainCookieOnly\nHJ www.jstor.org\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Fix: true, Output: io.Discard}
+	count, err := linter.ProcessFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count == 0 {
+		t.Fatal("found no issues, expected the duplicate line to be reported")
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fixed) != content {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, content)
+	}
+}
+
+func TestFixIncludeFileOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	content := "Name ezproxy.library.example.edu\n\nIncludeFile databases/wiley.txt\n# Shared with the law library.\n" +
+		"IncludeFile databases/JSTOR.txt\nIncludeFile databases/ebsco.txt\n\nIncludeFile local.txt\nIncludeFile custom.txt\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Pedantic: true, Fix: true, Format: FormatJSON, Output: io.Discard}
+	count, err := linter.ProcessFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("found %v issues instead of 3: %+v", count, linter.Report.Findings)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Name ezproxy.library.example.edu\n\nIncludeFile databases/ebsco.txt\n# Shared with the law library.\n" +
+		"IncludeFile databases/JSTOR.txt\nIncludeFile databases/wiley.txt\n\nIncludeFile custom.txt\nIncludeFile local.txt\n"
+	if string(fixed) != expected {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, expected)
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	lines := []string{"Title A", "H a.com", "h b.com"}
+	ats := []string{"f:1", "f:2", "f:3"}
+	fixes := map[string]Fix{"f:2": {Delete: true}, "f:3": {Old: "h", New: "H"}}
+	fixed, count := ApplyFixes(lines, ats, fixes)
+	if count != 2 || len(fixed) != 2 || fixed[1] != "H b.com" {
+		t.Fatalf("incorrect fixed lines %q with count %v", fixed, count)
+	}
+}
+
+func TestFixEdits(t *testing.T) {
+	var tests = []struct {
+		fix      Fix
+		line     string
+		edits    []Edit
+		replaced []string
+	}{
+		{Fix{Delete: true}, "H a.com", []Edit{{StartLine: 4, StartColumn: 1, EndLine: 5, EndColumn: 1}}, nil},
+		{Fix{Old: "hj", New: "HJ"}, "  hj b.com", []Edit{{StartLine: 4, StartColumn: 3, EndLine: 4, EndColumn: 5, NewText: "HJ"}}, []string{"  HJ b.com"}},
+		{Fix{Lines: []string{"H https://a.com", "H https://b.com"}}, "D com", []Edit{{StartLine: 4, StartColumn: 1, EndLine: 4, EndColumn: 6, NewText: "H https://a.com\nH https://b.com"}},
+			[]string{"H https://a.com", "H https://b.com"}},
+		{Fix{Old: "missing", New: "found"}, "H a.com", nil, []string{"H a.com"}},
+	}
+	for _, tt := range tests {
+		if edits := tt.fix.Edits(tt.line, 4); !reflect.DeepEqual(edits, tt.edits) {
+			t.Fatalf("incorrect edits %+v instead of %+v for %+v", edits, tt.edits, tt.fix)
+		}
+		if replaced := tt.fix.Apply(tt.line); !reflect.DeepEqual(replaced, tt.replaced) {
+			t.Fatalf("incorrect lines %q instead of %q for %+v", replaced, tt.replaced, tt.fix)
+		}
+	}
+}
+
+func TestFixBlankLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	content := "Title A\nURL https://a.example.com/\n\nDJ a.example.com\n\n\n\nTitle B\nURL https://b.example.com/\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Pedantic: true, Fix: true, Format: FormatJSON, Output: io.Discard}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Title A\nURL https://a.example.com/\nDJ a.example.com\n\nTitle B\nURL https://b.example.com/\n"
+	if string(fixed) != expected {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, expected)
+	}
+}
+
+func TestFixIPRanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	content := "ExcludeIP 192.0.2.128-192.0.2.255\nExcludeIP 192.0.2.0-192.0.2.127\nExcludeIP 198.51.100.7\nExcludeIP 192.0.2.10\n" +
+		"IncludeIP 192.0.2.10\n\nAutoLoginIP 10.0.0.0/8\nAutoLoginIP 172.16.0.0-172.16.0.9\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{Pedantic: true, Fix: true, Format: FormatJSON, Output: io.Discard}
+	count, err := linter.ProcessFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("found %v issues instead of 2: %+v", count, linter.Report.Findings)
+	}
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "ExcludeIP 192.0.2.0/24\nExcludeIP 198.51.100.7\n" +
+		"IncludeIP 192.0.2.10\n\nAutoLoginIP 10.0.0.0/8\nAutoLoginIP 172.16.0.0-172.16.0.9\n"
+	if string(fixed) != expected {
+		t.Fatalf("incorrect fixed file %q instead of %q", fixed, expected)
+	}
+}
+
+func TestFixDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	content := "ExcludeIP 192.0.2.128-192.0.2.255\nExcludeIP 192.0.2.0-192.0.2.127\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var output strings.Builder
+	linter := Linter{Pedantic: true, Fix: true, Diff: true, Output: &output}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	unchanged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unchanged) != content {
+		t.Fatalf("file was changed to %q", unchanged)
+	}
+	expected := "--- " + path + "\n+++ " + path + "\n@@ -1,2 +1,1 @@\n-ExcludeIP 192.0.2.128-192.0.2.255\n-ExcludeIP 192.0.2.0-192.0.2.127\n+ExcludeIP 192.0.2.0/24\n"
+	if !strings.Contains(output.String(), expected) {
+		t.Fatalf("output %q does not contain the diff %q", output.String(), expected)
+	}
+}
+
+func TestFixAddingIssues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "JSTOR.txt")
+	content := "title JSTOR\nURL https://www.jstor.org/\nDJ jstor.org\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := Linter{DirectiveCase: true, Fix: true, Output: io.Discard}
+	// A faulty fix, which replaces a line with an unknown directive.
+	linter.AddFix(path+":3", Fix{Old: "DJ", New: "DX"})
+	_, err := linter.ProcessFile(path)
+	if err == nil || !strings.Contains(err.Error(), "would add issues (L9001)") {
+		t.Fatalf("expected an error about the added issues, got %v", err)
+	}
+	unchanged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unchanged) != content {
+		t.Fatalf("the file was changed to %q", unchanged)
+	}
+}
+
+func TestCountIssuesIncludeFile(t *testing.T) {
+	lines := []string{"IncludeFile databases/JSTOR.txt", "Title Google", "URL https://www.google.com"}
+	var tests = []struct {
+		followIncludeFile bool
+		expected          map[string]int
+	}{
+		// The included file ends with an empty line, which ends the stanza before the Title directive.
+		{true, map[string]int{}},
+		{false, map[string]int{"L1001": 1}},
+	}
+	for _, tt := range tests {
+		linter := Linter{FollowIncludeFile: tt.followIncludeFile}
+		if counts := linter.countIssues(lines); !reflect.DeepEqual(counts, tt.expected) {
+			t.Fatalf("incorrect counts %v instead of %v with FollowIncludeFile %v", counts, tt.expected, tt.followIncludeFile)
+		}
+	}
+}
+
+func TestFixCheckLinter(t *testing.T) {
+	linter := Linter{ServerConfig: true, Values: map[string]string{"HOST": "www.jstor.org"}, Fix: true, Source: true, FollowIncludeFile: true}
+	linter.ProcessLineAt("Title JSTOR", "test:1")
+	check := linter.fixCheckLinter()
+	if !check.ServerConfig || !reflect.DeepEqual(check.Values, linter.Values) {
+		t.Fatalf("the settings of the linter weren't copied: %+v", check)
+	}
+	if check.Fix || check.Source || check.FollowIncludeFile || check.State.Title != "" {
+		t.Fatalf("the fix check linter should not fix, make network requests, follow IncludeFile directives, or keep the state of the run: %+v", check)
+	}
+	check.ProcessLineAt("Title Google", "fix:1")
+	if _, seen := linter.PreviousTitles.Seen(linter.SeenGroup(), "Google"); seen {
+		t.Fatal("the fix check linter changed the state of the linter")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+)
+
+// NoLimit is the gate threshold which allows any number of new findings.
+const NoLimit = -1
+
+// GateThresholds are the most new findings of each severity a config can have, compared to its baseline,
+// and still pass the gate. A threshold of NoLimit allows any number.
+type GateThresholds struct {
+	MaxNewErrors   int
+	MaxNewWarnings int
+}
+
+// Validate checks that the thresholds are zero or more, or NoLimit.
+// Other negative thresholds would make the gate fail for every config.
+func (t GateThresholds) Validate() error {
+	if t.MaxNewErrors < NoLimit {
+		return fmt.Errorf("the most new errors %v is not valid, should be %v for no limit, or zero or more", t.MaxNewErrors, NoLimit)
+	}
+	if t.MaxNewWarnings < NoLimit {
+		return fmt.Errorf("the most new warnings %v is not valid, should be %v for no limit, or zero or more", t.MaxNewWarnings, NoLimit)
+	}
+	return nil
+}
+
+// GateCounts are the number of findings of each severity.
+type GateCounts struct {
+	Errors   int
+	Warnings int
+	Info     int
+}
+
+// A GateResult is the summary printed by the gate command, for deploy scripts.
+// Failures explain which thresholds were exceeded, and NewFindings are the findings which aren't in the baseline.
+type GateResult struct {
+	Passed      bool
+	Baseline    string
+	Thresholds  GateThresholds
+	New         GateCounts
+	Fixed       GateCounts
+	Total       GateCounts
+	Failures    []string
+	NewFindings []Finding
+}
+
+// countSeverities counts the findings of each severity.
+func countSeverities(findings []Finding) (c GateCounts) {
+	for _, f := range findings {
+		switch f.Severity {
+		case SeverityError:
+			c.Errors++
+		case SeverityWarning:
+			c.Warnings++
+		case SeverityInfo:
+			c.Info++
+		}
+	}
+	return c
+}
+
+// Gate compares the findings to the baseline snapshot, and checks the new findings against the thresholds.
+// Informational findings never fail the gate.
+func Gate(baseline string, s Snapshot, findings []Finding, thresholds GateThresholds) GateResult {
+	added, _, fixed := CompareSnapshot(s, findings)
+	r := GateResult{
+		Baseline:    baseline,
+		Thresholds:  thresholds,
+		New:         countSeverities(added),
+		Fixed:       countSeverities(fixed),
+		Total:       countSeverities(findings),
+		Failures:    []string{},
+		NewFindings: append([]Finding{}, added...),
+	}
+	if thresholds.MaxNewErrors != NoLimit && r.New.Errors > thresholds.MaxNewErrors {
+		r.Failures = append(r.Failures, fmt.Sprintf("%v new errors, more than the limit of %v", r.New.Errors, thresholds.MaxNewErrors))
+	}
+	if thresholds.MaxNewWarnings != NoLimit && r.New.Warnings > thresholds.MaxNewWarnings {
+		r.Failures = append(r.Failures, fmt.Sprintf("%v new warnings, more than the limit of %v", r.New.Warnings, thresholds.MaxNewWarnings))
+	}
+	r.Passed = len(r.Failures) == 0
+	return r
+}
@@ -0,0 +1,60 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGate(t *testing.T) {
+	baseline := Snapshot{Version: SnapshotVersion, Findings: []Finding{
+		{File: "config.txt", Line: 3, Text: "FooBar", Code: "L9001", Severity: SeverityError, Message: "Unknown directive \"FooBar\" (L9001)"},
+		{File: "config.txt", Line: 12, Text: "HJ a.com ", Code: "L5002", Severity: SeverityWarning, Message: "Line ends in a space or tab character (L5002)"},
+	}}
+	findings := []Finding{
+		{File: "config.txt", Line: 3, Text: "FooBar", Code: "L9001", Severity: SeverityError, Message: "Unknown directive \"FooBar\" (L9001)"},
+		{File: "config.txt", Line: 20, Text: "HJ b.com ", Code: "L5002", Severity: SeverityWarning, Message: "Line ends in a space or tab character (L5002)"},
+		{File: "config.txt", Line: 25, Code: "L9013", Severity: SeverityInfo, Message: "Unable to check the Source page, the stanza's title was not compared (L9013): timeout"},
+	}
+	var tests = []struct {
+		thresholds GateThresholds
+		failures   []string
+	}{
+		{GateThresholds{MaxNewErrors: 0, MaxNewWarnings: NoLimit}, []string{}},
+		{GateThresholds{MaxNewErrors: 0, MaxNewWarnings: 1}, []string{}},
+		{GateThresholds{MaxNewErrors: 0, MaxNewWarnings: 0}, []string{"1 new warnings, more than the limit of 0"}},
+	}
+	for _, tt := range tests {
+		r := Gate("snapshot.json", baseline, findings, tt.thresholds)
+		if !reflect.DeepEqual(r.Failures, tt.failures) || r.Passed != (len(tt.failures) == 0) {
+			t.Fatalf("incorrect result %+v for thresholds %+v", r, tt.thresholds)
+		}
+		if r.New != (GateCounts{Warnings: 1, Info: 1}) || r.Fixed != (GateCounts{Warnings: 1}) || r.Total != (GateCounts{Errors: 1, Warnings: 1, Info: 1}) {
+			t.Fatalf("incorrect counts %+v", r)
+		}
+	}
+	r := Gate("", Snapshot{}, findings, GateThresholds{MaxNewErrors: 0, MaxNewWarnings: NoLimit})
+	if r.Passed || !reflect.DeepEqual(r.Failures, []string{"1 new errors, more than the limit of 0"}) {
+		t.Fatalf("incorrect result without a baseline %+v", r)
+	}
+}
+
+func TestGateThresholdsValidate(t *testing.T) {
+	var tests = []struct {
+		thresholds GateThresholds
+		valid      bool
+	}{
+		{GateThresholds{MaxNewErrors: 0, MaxNewWarnings: NoLimit}, true},
+		{GateThresholds{MaxNewErrors: 5, MaxNewWarnings: 10}, true},
+		{GateThresholds{MaxNewErrors: -2, MaxNewWarnings: 0}, false},
+		{GateThresholds{MaxNewErrors: 0, MaxNewWarnings: -5}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.thresholds.Validate(); (err == nil) != tt.valid {
+			t.Fatalf("unexpected error value %v for %+v", err, tt.thresholds)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// Scripts returns the scripts which are checked when looking for hostname labels which mix scripts.
+// Chinese, Japanese, and Korean scripts are commonly mixed, so they are grouped together.
+func Scripts() map[string][]*unicode.RangeTable {
+	return map[string][]*unicode.RangeTable{
+		"Arabic":     {unicode.Arabic},
+		"Armenian":   {unicode.Armenian},
+		"CJK":        {unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul},
+		"Cyrillic":   {unicode.Cyrillic},
+		"Devanagari": {unicode.Devanagari},
+		"Georgian":   {unicode.Georgian},
+		"Greek":      {unicode.Greek},
+		"Hebrew":     {unicode.Hebrew},
+		"Latin":      {unicode.Latin},
+		"Thai":       {unicode.Thai},
+	}
+}
+
+// LabelScripts returns the sorted names of the scripts used by the letters in a hostname label.
+func LabelScripts(label string) []string {
+	var found []string
+	for _, r := range label {
+		for name, tables := range Scripts() {
+			if unicode.IsOneOf(tables, r) && !slices.Contains(found, name) {
+				found = append(found, name)
+			}
+		}
+	}
+	slices.Sort(found)
+	return found
+}
+
+// HostnameChecks reports on internationalized hostnames which are not in punycode form,
+// which have invalid punycode, or which have labels that mix scripts.
+// Mixed scripts, like a Cyrillic "а" in "аpple.com", are usually the result of copy-paste corruption.
+func HostnameChecks(hostname string) (m []string) {
+	decoded := hostname
+	if strings.ContainsFunc(hostname, func(r rune) bool { return r > unicode.MaxASCII }) {
+		punycode, err := idna.Lookup.ToASCII(hostname)
+		if err != nil {
+			m = append(m, fmt.Sprintf("Hostname %q is not a valid internationalized domain name: %v (L3016)", hostname, err))
+			return m
+		}
+		m = append(m, fmt.Sprintf("Hostname %q contains non-ASCII characters, it should be replaced by %q (L3016)", hostname, punycode))
+	} else if strings.Contains(strings.ToLower(hostname), "xn--") {
+		unicodeHostname, err := idna.Lookup.ToUnicode(hostname)
+		if err != nil {
+			m = append(m, fmt.Sprintf("Hostname %q has invalid punycode: %v (L3016)", hostname, err))
+			return m
+		}
+		decoded = unicodeHostname
+	} else {
+		// ASCII hostnames only use the Latin script.
+		return m
+	}
+	for label := range strings.SplitSeq(decoded, ".") {
+		if scripts := LabelScripts(label); len(scripts) > 1 {
+			m = append(m, fmt.Sprintf("Hostname %q mixes %v scripts in %q, it might have been corrupted (L3017)",
+				hostname, strings.Join(scripts, " and "), label))
+		}
+	}
+	return m
+}
@@ -0,0 +1,30 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHostnameChecks(t *testing.T) {
+	var tests = []struct {
+		hostname string
+		expected []string
+	}{
+		{"www.example.com", nil},
+		{"xn--bcher-kva.de", nil},
+		{"bücher.de", []string{"Hostname \"bücher.de\" contains non-ASCII characters, it should be replaced by \"xn--bcher-kva.de\" (L3016)"}},
+		{"xn--bcher-k.de", []string{"Hostname \"xn--bcher-k.de\" has invalid punycode: idna: invalid label \"bcher-k\" (L3016)"}},
+		{"xn--pple-43d.com", []string{"Hostname \"xn--pple-43d.com\" mixes Cyrillic and Latin scripts in \"аpple\", it might have been corrupted (L3017)"}},
+	}
+
+	for _, tt := range tests {
+		messages := HostnameChecks(tt.hostname)
+		if !reflect.DeepEqual(messages, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q", messages, tt.expected)
+		}
+	}
+}
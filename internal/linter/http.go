@@ -0,0 +1,127 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HTTPClient returns the client used for all outbound requests, like fetching OCLC stanza pages.
+// Proxies are set with the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+// If Timeout is not set, OCLCHTTPTimeout is used.
+func (l *Linter) HTTPClient() *http.Client {
+	if l.Client == nil {
+		timeout := l.Timeout
+		if timeout <= 0 {
+			timeout = OCLCHTTPTimeout
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+		l.Client = &http.Client{Transport: transport, Timeout: timeout}
+	}
+	return l.Client
+}
+
+// DefaultUserAgent is the User-Agent header sent with requests when UserAgent is not set.
+const DefaultUserAgent = "ezproxy-config-lint"
+
+// Get makes a GET request for rawURL, with the UserAgent and any extra Headers.
+// Requests which fail, or which get a "Too Many Requests" or server error response,
+// are tried again up to Retries times, waiting a little longer after each attempt.
+func (l *Linter) Get(rawURL string) (resp *http.Response, err error) {
+	defer l.Profile.Time(ProfileNetwork)()
+	l.Progress.Request()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range l.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("User-Agent", cmp.Or(l.UserAgent, DefaultUserAgent))
+	for attempt := 0; ; attempt++ {
+		resp, err = l.HTTPClient().Do(req)
+		if err == nil && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("unexpected response status %q from %v", resp.Status, rawURL)
+		}
+		if attempt >= l.Retries {
+			return nil, err
+		}
+		time.Sleep(time.Duration(attempt+1) * OCLCRequestDelay)
+	}
+}
+
+// IsURL reports whether a file path is an http or https URL.
+func IsURL(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// ReadFile returns the content of the file at filePath, which can be a local path or an http or https URL.
+// Files ending in ".gz" are decompressed.
+func (l *Linter) ReadFile(filePath string) ([]byte, error) {
+	defer l.Profile.Time(ProfileReading)()
+	if !IsURL(filePath) {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return Decompress(filePath, content)
+	}
+	resp, err := l.Get(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %q from %v", resp.Status, filePath)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return Decompress(filePath, content)
+}
+
+// ParentDirectory returns the directory which contains the file at filePath.
+// For URLs, this is the URL with the last path segment removed.
+func ParentDirectory(filePath string) string {
+	if !IsURL(filePath) {
+		return filepath.Dir(filePath)
+	}
+	u, err := url.Parse(filePath)
+	if err != nil {
+		return filePath
+	}
+	return u.ResolveReference(&url.URL{Path: "./"}).String()
+}
+
+// JoinPath resolves the path of an included file from a directory, which can be a local path or a URL.
+func JoinPath(directory, includeFilePath string) string {
+	if !IsURL(directory) {
+		return filepath.Join(directory, includeFilePath)
+	}
+	u, err := url.Parse(directory)
+	if err != nil {
+		return includeFilePath
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.ResolveReference(&url.URL{Path: filepath.ToSlash(includeFilePath)}).String()
+}
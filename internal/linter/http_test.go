@@ -0,0 +1,125 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGetRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	linter := Linter{}
+	if _, err := linter.Get(server.URL); err == nil {
+		t.Fatal("expected an error without retries")
+	}
+	requests = 0
+	linter = Linter{Retries: 1}
+	resp, err := linter.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if requests != 2 {
+		t.Fatalf("made %v requests instead of 2", requests)
+	}
+}
+
+func TestGetHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	linter := Linter{Headers: http.Header{"From": {"admin@library.example.edu"}}}
+	resp, err := linter.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if received.Get("User-Agent") != DefaultUserAgent || received.Get("From") != "admin@library.example.edu" {
+		t.Fatalf("incorrect headers %v", received)
+	}
+}
+
+func TestProcessFileURL(t *testing.T) {
+	files := map[string]string{
+		"/configs/config.txt":        "Title JSTOR\nURL https://www.jstor.org\n\nIncludeFile stanzas/wiley.txt\n",
+		"/configs/stanzas/wiley.txt": "Title Wiley\nURL https://www.wiley.com\nFooBar baz\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, content)
+	}))
+	defer server.Close()
+
+	linter := Linter{FollowIncludeFile: true, Format: FormatJSON, Output: io.Discard}
+	count, err := linter.ProcessFile(server.URL + "/configs/config.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || linter.Report.Findings[0].File != server.URL+"/configs/stanzas/wiley.txt" {
+		t.Fatalf("incorrect findings %+v", linter.Report.Findings)
+	}
+
+	linter = Linter{Format: FormatJSON, Output: io.Discard}
+	if _, err := linter.ProcessFile(server.URL + "/configs/missing.txt"); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestSourceUnavailable(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, "<html><body><p>The stanza has moved.</p></body></html>")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		path     string
+		strict   bool
+		expected string
+	}{
+		{"/forbidden", false, "Unable to check the Source page, the stanza's title was not compared (L9013): " +
+			"unable to read Source page: unexpected response status \"403 Forbidden\""},
+		{"/forbidden", true, "Error processsing Source line (L9003): unable to read Source page: unexpected response status \"403 Forbidden\""},
+		{"/moved", false, "Unable to check the Source page, the stanza's title was not compared (L9013): " +
+			"unable to read Source page: no Title directive was found on the page, its layout might have changed"},
+	}
+	for _, tt := range tests {
+		linter := Linter{Source: true, SourceStrict: tt.strict, Client: server.Client(), Config: Config{SourceHosts: map[string]bool{serverURL.Host: true}}}
+		messages := Messages(linter.ProcessLineAt("# Source - "+server.URL+tt.path, "test:1"))
+		if len(messages) != 1 || messages[0] != tt.expected {
+			t.Fatalf("incorrect messages %q instead of %q for %v", messages, tt.expected, tt.path)
+		}
+		if count, expected := IssueCount(messages), map[bool]int{false: 0, true: 1}[tt.strict]; count != expected {
+			t.Fatalf("counted %v issues instead of %v for %q", count, expected, messages)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// An IncludeFileLine is an IncludeFile line in a block of IncludeFile lines.
+type IncludeFileLine struct {
+	Line string
+	At   string
+	Path string
+}
+
+// CompareIncludeFileLines orders IncludeFile lines alphabetically by path, without regard to case.
+func CompareIncludeFileLines(a, b IncludeFileLine) int {
+	return cmp.Or(cmp.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path)), cmp.Compare(a.Path, b.Path))
+}
+
+// IncludeFileOrderCheck reports IncludeFile lines which are not in alphabetical order
+// with the IncludeFile lines before them. A block of IncludeFile lines ends at an empty line or another directive.
+func (l *Linter) IncludeFileOrderCheck(directive Directive, line, at string) (m []string) {
+	if directive != IncludeFile {
+		l.EndIncludeFileBlock()
+		return m
+	}
+	_, path := SplitLabel(line)
+	current := IncludeFileLine{Line: line, At: at, Path: path}
+	if len(l.IncludeFileBlock) > 0 {
+		if last := l.IncludeFileBlock[len(l.IncludeFileBlock)-1]; CompareIncludeFileLines(current, last) < 0 {
+			m = append(m, fmt.Sprintf("\"IncludeFile\" path %q is not in alphabetical order, it should be before %q (L1017)", path, last.Path))
+		}
+	}
+	l.IncludeFileBlock = append(l.IncludeFileBlock, current)
+	return m
+}
+
+// EndIncludeFileBlock ends the current block of IncludeFile lines.
+// In fix mode, the lines in the block are sorted.
+func (l *Linter) EndIncludeFileBlock() {
+	sorted := slices.SortedStableFunc(slices.Values(l.IncludeFileBlock), CompareIncludeFileLines)
+	for i, line := range l.IncludeFileBlock {
+		if sorted[i].Line != line.Line {
+			l.AddFix(line.At, Fix{Old: line.Line, New: sorted[i].Line})
+		}
+	}
+	l.IncludeFileBlock = nil
+}
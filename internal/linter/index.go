@@ -0,0 +1,163 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// A SeenIndex stores where values, like titles or origins, were first seen across all the files in a config.
+// Values are scoped by a group, which is empty unless the linter is GroupScoped.
+// Configs can have hundreds of thousands of Host lines, so instead of an "at" string and a group prefix
+// for each value, the file paths and group names are interned, and each location is stored as two numbers.
+// The zero value is an empty index ready to use.
+type SeenIndex struct {
+	files   []string
+	fileIDs map[string]uint32
+	groups  map[string]uint32
+	entries map[seenKey]seenLocation
+	order   []seenKey
+}
+
+// A seenKey is a value in an interned group.
+type seenKey struct {
+	group uint32
+	value string
+}
+
+// A seenLocation is a line in an interned file.
+type seenLocation struct {
+	file uint32
+	line uint32
+}
+
+// A seenEntry is a value in the index, as it is stored in JSON.
+type seenEntry struct {
+	Group string `json:",omitempty"`
+	Value string
+	At    string
+}
+
+// Len returns the number of values in the index.
+func (s *SeenIndex) Len() int {
+	return len(s.entries)
+}
+
+// Seen returns the location where the value was first seen in the group.
+func (s *SeenIndex) Seen(group, value string) (at string, seen bool) {
+	g, ok := s.groups[group]
+	if !ok {
+		return "", false
+	}
+	location, seen := s.entries[seenKey{group: g, value: value}]
+	if !seen {
+		return "", false
+	}
+	if location.line == 0 {
+		return s.files[location.file], true
+	}
+	return fmt.Sprintf("%v:%v", s.files[location.file], location.line), true
+}
+
+// Add stores the location where the value was seen in the group.
+// Values which were already seen keep their first location.
+func (s *SeenIndex) Add(group, value, at string) {
+	s.store(group, value, at, false)
+}
+
+// Replace stores the location where the value was seen in the group,
+// replacing the location of a value which was already seen.
+func (s *SeenIndex) Replace(group, value, at string) {
+	s.store(group, value, at, true)
+}
+
+// store stores the location of the value, and records the change so that Since can return it.
+func (s *SeenIndex) store(group, value, at string, replace bool) {
+	if s.entries == nil {
+		s.fileIDs = map[string]uint32{}
+		s.groups = map[string]uint32{}
+		s.entries = map[seenKey]seenLocation{}
+	}
+	g, ok := s.groups[group]
+	if !ok {
+		g = uint32(len(s.groups))
+		s.groups[group] = g
+	}
+	key := seenKey{group: g, value: value}
+	if _, seen := s.entries[key]; seen && !replace {
+		return
+	}
+	file, line := SplitAt(at)
+	f, ok := s.fileIDs[file]
+	if !ok {
+		f = uint32(len(s.files))
+		s.files = append(s.files, file)
+		s.fileIDs[file] = f
+	}
+	s.entries[key] = seenLocation{file: f, line: uint32(line)}
+	s.order = append(s.order, key)
+}
+
+// Changes returns the number of times a value was added or replaced.
+func (s *SeenIndex) Changes() int {
+	return len(s.order)
+}
+
+// Since returns the values added or replaced after the index had n changes, in the order they were changed.
+// Values are never removed, so the values a file changed can be found from the number of changes before it.
+func (s *SeenIndex) Since(n int) []seenEntry {
+	groupNames := make([]string, len(s.groups))
+	for name, g := range s.groups {
+		groupNames[g] = name
+	}
+	entries := []seenEntry{}
+	changed := map[seenKey]bool{}
+	for _, key := range s.order[n:] {
+		if changed[key] {
+			continue
+		}
+		changed[key] = true
+		at, _ := s.Seen(groupNames[key.group], key.value)
+		entries = append(entries, seenEntry{Group: groupNames[key.group], Value: key.value, At: at})
+	}
+	return entries
+}
+
+// AddEntries stores the values returned by Since, replacing their earlier locations.
+func (s *SeenIndex) AddEntries(entries []seenEntry) {
+	for _, e := range entries {
+		s.Replace(e.Group, e.Value, e.At)
+	}
+}
+
+// MarshalJSON encodes the index as a list of values, sorted by group and value.
+func (s SeenIndex) MarshalJSON() ([]byte, error) {
+	groupNames := make([]string, len(s.groups))
+	for name, g := range s.groups {
+		groupNames[g] = name
+	}
+	entries := []seenEntry{}
+	for key := range s.entries {
+		at, _ := s.Seen(groupNames[key.group], key.value)
+		entries = append(entries, seenEntry{Group: groupNames[key.group], Value: key.value, At: at})
+	}
+	slices.SortFunc(entries, func(a, b seenEntry) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Value, b.Value))
+	})
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON decodes an index encoded by MarshalJSON.
+func (s *SeenIndex) UnmarshalJSON(b []byte) error {
+	var entries []seenEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	*s = SeenIndex{}
+	s.AddEntries(entries)
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSeenIndex(t *testing.T) {
+	var s SeenIndex
+	if _, seen := s.Seen("", "https://www.jstor.org"); seen {
+		t.Fatal("empty index has a value")
+	}
+	s.Add("", "https://www.jstor.org", "config.txt:2")
+	s.Add("", "https://www.jstor.org", "config.txt:9")
+	s.Add("staff", "https://www.jstor.org", "staff.txt:4")
+	s.Add("", "https://www.wiley.com", "config.txt:12")
+	var tests = []struct {
+		group string
+		value string
+		at    string
+		seen  bool
+	}{
+		{"", "https://www.jstor.org", "config.txt:2", true},
+		{"staff", "https://www.jstor.org", "staff.txt:4", true},
+		{"", "https://www.wiley.com", "config.txt:12", true},
+		{"staff", "https://www.wiley.com", "", false},
+		{"students", "https://www.jstor.org", "", false},
+	}
+	for _, tt := range tests {
+		if at, seen := s.Seen(tt.group, tt.value); at != tt.at || seen != tt.seen {
+			t.Fatalf("Seen(%q, %q) returned %q and %v instead of %q and %v", tt.group, tt.value, at, seen, tt.at, tt.seen)
+		}
+	}
+	if s.Len() != 3 {
+		t.Fatalf("index has %v values instead of 3", s.Len())
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded SeenIndex
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if at, seen := decoded.Seen(tt.group, tt.value); at != tt.at || seen != tt.seen {
+			t.Fatalf("decoded Seen(%q, %q) returned %q and %v instead of %q and %v", tt.group, tt.value, at, seen, tt.at, tt.seen)
+		}
+	}
+	again, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, again) {
+		t.Fatalf("index encoded as %s after decoding instead of %s", again, b)
+	}
+}
+
+func TestSeenIndexSince(t *testing.T) {
+	var s SeenIndex
+	s.Add("", "https://www.jstor.org", "config.txt:2")
+	n := s.Changes()
+	s.Add("staff", "https://www.jstor.org", "staff.txt:4")
+	s.Add("", "https://www.jstor.org", "staff.txt:9")
+	s.Add("", "https://www.wiley.com", "staff.txt")
+	s.Replace("staff", "https://www.jstor.org", "staff.txt:12")
+	s.Replace("staff", "https://www.jstor.org", "staff.txt:15")
+	expected := []seenEntry{
+		{Group: "staff", Value: "https://www.jstor.org", At: "staff.txt:15"},
+		{Value: "https://www.wiley.com", At: "staff.txt"},
+	}
+	added := s.Since(n)
+	if !reflect.DeepEqual(added, expected) {
+		t.Fatalf("incorrect values %+v instead of %+v", added, expected)
+	}
+
+	var restored SeenIndex
+	restored.Add("", "https://www.jstor.org", "config.txt:2")
+	restored.AddEntries(added)
+	for _, e := range append(expected, seenEntry{Value: "https://www.jstor.org", At: "config.txt:2"}) {
+		if at, seen := restored.Seen(e.Group, e.Value); !seen || at != e.At {
+			t.Fatalf("restored Seen(%q, %q) returned %q and %v instead of %q", e.Group, e.Value, at, seen, e.At)
+		}
+	}
+}
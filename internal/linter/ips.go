@@ -0,0 +1,161 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// An IPRangeLine is an ExcludeIP, IncludeIP, or AutoLoginIP line in a block of lines with the same directive.
+type IPRangeLine struct {
+	Directive Directive
+	Line      string
+	At        string
+	Label     string
+	Range     IPRange
+}
+
+// An IPRange is an inclusive range of IP addresses.
+type IPRange struct {
+	Start netip.Addr
+	End   netip.Addr
+}
+
+// ParseIPRange parses an IP range in the forms EZproxy accepts: a single address,
+// a range like "192.0.2.0-192.0.2.255", or a CIDR prefix like "192.0.2.0/24".
+func ParseIPRange(s string) (r IPRange, ok bool) {
+	if start, end, found := strings.Cut(s, "-"); found {
+		startAddr, startErr := netip.ParseAddr(start)
+		endAddr, endErr := netip.ParseAddr(end)
+		if startErr != nil || endErr != nil || startAddr.Is4() != endAddr.Is4() || endAddr.Less(startAddr) {
+			return r, false
+		}
+		return IPRange{startAddr, endAddr}, true
+	}
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return r, false
+		}
+		prefix = prefix.Masked()
+		return IPRange{prefix.Addr(), lastAddr(prefix)}, true
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return r, false
+	}
+	return IPRange{addr, addr}, true
+}
+
+// lastAddr returns the last address in the prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// String returns the range as a single address, a CIDR prefix if the range is exactly one prefix, or "start-end".
+func (r IPRange) String() string {
+	if r.Start == r.End {
+		return r.Start.String()
+	}
+	for bits := 0; bits < r.Start.BitLen(); bits++ {
+		if prefix := netip.PrefixFrom(r.Start, bits); prefix.Masked().Addr() == r.Start && lastAddr(prefix) == r.End {
+			return prefix.String()
+		}
+	}
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// Touches reports whether the ranges overlap or are next to each other, so that they can be merged.
+func (r IPRange) Touches(o IPRange) bool {
+	if r.Start.Is4() != o.Start.Is4() {
+		return false
+	}
+	if o.Start.Less(r.Start) {
+		r, o = o, r
+	}
+	return !r.End.Less(o.Start) || r.End.Next() == o.Start
+}
+
+// MergeIPRanges returns the ranges sorted, with overlapping and adjacent ranges merged.
+func MergeIPRanges(ranges []IPRange) (merged []IPRange) {
+	sorted := slices.SortedFunc(slices.Values(ranges), func(a, b IPRange) int {
+		return cmp.Or(a.Start.Compare(b.Start), a.End.Compare(b.End))
+	})
+	for _, r := range sorted {
+		if len(merged) > 0 && merged[len(merged)-1].Touches(r) {
+			last := &merged[len(merged)-1]
+			if last.End.Less(r.End) {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// IPRangeMergeCheck reports ExcludeIP, IncludeIP, and AutoLoginIP ranges which overlap or are next to
+// an earlier range in the same block of lines, or which are not in order with the range before them.
+// A block of lines ends at an empty line or another directive. The order of different IP directives
+// matters to EZproxy, so only lines with the same directive are merged.
+func (l *Linter) IPRangeMergeCheck(directive Directive, line, at string) (m []string) {
+	label, argument := SplitLabel(line)
+	r, ok := ParseIPRange(argument)
+	ok = ok && slices.Contains([]Directive{ExcludeIP, IncludeIP, AutoLoginIP}, directive)
+	if !ok || (len(l.IPRangeBlock) > 0 && l.IPRangeBlock[0].Directive != directive) {
+		l.EndIPRangeBlock()
+	}
+	if !ok {
+		return m
+	}
+	for _, previous := range l.IPRangeBlock {
+		if previous.Range.Touches(r) {
+			m = append(m, fmt.Sprintf("%q range %q overlaps or is next to range %q, they can be merged (L5006)", directive, argument, previous.Range))
+			break
+		}
+	}
+	if len(m) == 0 && len(l.IPRangeBlock) > 0 {
+		if last := l.IPRangeBlock[len(l.IPRangeBlock)-1]; r.Start.Less(last.Range.Start) {
+			m = append(m, fmt.Sprintf("%q range %q is not in order, it should be before %q (L5006)", directive, argument, last.Range))
+		}
+	}
+	if len(m) > 0 {
+		l.IPRangeBlockMergeable = true
+	}
+	l.IPRangeBlock = append(l.IPRangeBlock, IPRangeLine{Directive: directive, Line: line, At: at, Label: label, Range: r})
+	return m
+}
+
+// EndIPRangeBlock ends the current block of IP range lines.
+// In fix mode, if ranges in the block were reported, the block is replaced by the sorted and merged ranges.
+// Merging never adds ranges, so the first lines in the block are replaced and the rest are removed.
+func (l *Linter) EndIPRangeBlock() {
+	if l.IPRangeBlockMergeable {
+		ranges := make([]IPRange, 0, len(l.IPRangeBlock))
+		for _, line := range l.IPRangeBlock {
+			ranges = append(ranges, line.Range)
+		}
+		merged := MergeIPRanges(ranges)
+		for i, line := range l.IPRangeBlock {
+			if i >= len(merged) {
+				l.AddFix(line.At, Fix{Delete: true})
+				continue
+			}
+			if replacement := line.Label + " " + merged[i].String(); replacement != line.Line {
+				l.AddFix(line.At, Fix{Old: line.Line, New: replacement})
+			}
+		}
+	}
+	l.IPRangeBlock = nil
+	l.IPRangeBlockMergeable = false
+}
@@ -0,0 +1,73 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIPRange(t *testing.T) {
+	var tests = []struct {
+		s        string
+		expected string
+		ok       bool
+	}{
+		{"192.0.2.1", "192.0.2.1", true},
+		{"192.0.2.0-192.0.2.255", "192.0.2.0/24", true},
+		{"192.0.2.5/24", "192.0.2.0/24", true},
+		{"192.0.2.0-192.0.2.9", "192.0.2.0-192.0.2.9", true},
+		{"2001:db8::-2001:db8::ffff", "2001:db8::/112", true},
+		{"0.0.0.0-255.255.255.255", "0.0.0.0/0", true},
+		{"192.0.2.9-192.0.2.0", "", false},
+		{"192.0.2.0-2001:db8::", "", false},
+		{"example.com", "", false},
+	}
+	for _, tt := range tests {
+		r, ok := ParseIPRange(tt.s)
+		if ok != tt.ok || (ok && r.String() != tt.expected) {
+			t.Fatalf("ParseIPRange(%q) returned %q, %v instead of %q, %v", tt.s, r, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestMergeIPRanges(t *testing.T) {
+	var ranges []IPRange
+	for _, s := range []string{"198.51.100.7", "192.0.2.128/25", "192.0.2.0-192.0.2.127", "192.0.2.10", "255.255.255.255", "2001:db8::1"} {
+		r, _ := ParseIPRange(s)
+		ranges = append(ranges, r)
+	}
+	var merged []string
+	for _, r := range MergeIPRanges(ranges) {
+		merged = append(merged, r.String())
+	}
+	expected := []string{"192.0.2.0/24", "198.51.100.7", "255.255.255.255", "2001:db8::1"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("incorrect merged ranges %q instead of %q", merged, expected)
+	}
+}
+
+func TestIPRangeMergeCheck(t *testing.T) {
+	var tests = []struct {
+		lines    []string
+		expected []string
+	}{
+		{[]string{"ExcludeIP 192.0.2.0/25", "ExcludeIP 198.51.100.0/24", "IncludeIP 192.0.2.10", "ExcludeIP 192.0.2.128/25"}, nil},
+		{[]string{"ExcludeIP 192.0.2.0/25", "ExcludeIP 192.0.2.128-192.0.2.255"},
+			[]string{"\"ExcludeIP\" range \"192.0.2.128-192.0.2.255\" overlaps or is next to range \"192.0.2.0/25\", they can be merged (L5006)"}},
+		{[]string{"AutoLoginIP 198.51.100.0/24", "AutoLoginIP 192.0.2.0/24"},
+			[]string{"\"AutoLoginIP\" range \"192.0.2.0/24\" is not in order, it should be before \"198.51.100.0/24\" (L5006)"}},
+	}
+	for _, tt := range tests {
+		linter := Linter{Pedantic: true}
+		var messages []string
+		for _, line := range tt.lines {
+			messages = append(messages, Messages(linter.ProcessLineAt(line, "test:1"))...)
+		}
+		if !reflect.DeepEqual(messages, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q for %q", messages, tt.expected, tt.lines)
+		}
+	}
+}
@@ -5,69 +5,199 @@ package linter
 
 import (
 	"bufio"
-	"context"
+	"bytes"
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"maps"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/fatih/color"
 	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
 )
 
 const (
 	DefaultBufferSize = 1 * 1024 * 1024        // 1 MiB, the default size when creating a buffer for a scanner.
 	MaxBufferSize     = 5 * 1024 * 1024        // 5 MiB, the maximum size the scanner buffers can grow to.
-	OCLCHTTPTimeout   = 10 * time.Second       // The timeout to set on contexts when querying the OCLC website.
+	OCLCHTTPTimeout   = 10 * time.Second       // The default timeout for requests to the OCLC website.
 	OCLCRequestDelay  = 300 * time.Millisecond // The time to wait after querying the OCLC website.
 )
 
+// errSourceUnavailable is wrapped by the errors processSourceLine returns when the Source page couldn't be fetched or read,
+// as opposed to problems with the Source line itself.
+var errSourceUnavailable = errors.New("unable to read Source page")
+
 type State struct {
 	AddUserHeaderNeedsClosing bool
 	AnonymousURLNeedsClosing  bool
 	OpenOptions               []Directive
+	ClosedOptions             []Directive
 	InMultiline               bool
 	LastLineEmpty             bool
 	OCLCTitle                 string
-	Label                     string    `json:"PreviousLabel"`
-	Current                   Directive `json:"-"`
+	SourceStanzas             []SourceStanza `json:"-"`
+	SourceLines               []string       `json:"-"`
+	Label                     string         `json:"PreviousLabel"`
+	Current                   Directive      `json:"-"`
 	IsSeparator               bool
 	Previous                  Directive `json:"PreviousDirective"`
 	PreviousMultilineSegments string
 	Source                    string
+	HasSourceComment          bool
+	HeaderComments            []string
+	ReviewedOn                time.Time
+	HasOtherDirectives        bool
+	BlankLine                 bool
 	ProxyHostnameEditPatterns map[string]*regexp.Regexp
 	Title                     string
 	URL                       string
 	URLOrigin                 string
 	URLAt                     string
+	URLForm                   bool
+	DbVarsAt                  map[Directive]string
+	AllowedVars               []string
+	LastForm                  Directive
 	StanzaOrigins             map[string]string
+	StanzaLines               map[string]StanzaLine
+	HostLines                 []HostLine
+	HostReferences            []HostLine
+	Lines                     []string `json:"-"`
+}
+
+// A StanzaLine stores where a line was first seen in a stanza, for the duplicate line check.
+// BeforeStanza is true for lines before the stanza's Title and URL directives, like the Option lines which open a stanza.
+type StanzaLine struct {
+	At           string
+	BeforeStanza bool
+}
+
+// A HostLine stores the hostname from a Host, HostJavaScript, Domain, or DomainJavaScript line in a stanza.
+type HostLine struct {
+	Directive      Directive
+	Scheme         string
+	ExplicitScheme bool
+	Host           string
+	Port           string
+	At             string
+}
+
+// CoveredBy reports whether the Host or HostJavaScript line h is covered by the Domain or DomainJavaScript line d.
+// Domain lines do not enable JavaScript processing, so HostJavaScript lines are only covered by DomainJavaScript lines.
+func (h HostLine) CoveredBy(d HostLine) bool {
+	if h.Directive == HostJavaScript && d.Directive != DomainJavaScript {
+		return false
+	}
+	return h.Port == "" && (h.Host == d.Host || strings.HasSuffix(h.Host, "."+d.Host))
 }
 
 type Linter struct {
 	Annotate             bool
+	Highlight            bool
 	Verbose              bool
 	AdditionalPHEChecks  bool
 	DirectiveCase        bool
+	LabelStyle           string
 	HTTPS                bool
 	Origins              bool
 	Source               bool
+	SourceStrict         bool
+	SourceCompare        bool
 	Whitespace           bool
+	FileReferences       bool
+	ServerConfig         bool
+	RedundantHosts       bool
+	CrossSchemeOrigins   bool
+	DomainHosts          bool
+	Pedantic             bool
+	Fix                  bool
+	Diff                 bool
+	BackupDir            string
+	Format               string
+	FailFast             Severity
+	MinCategory          Category
+	Timeout              time.Duration
+	Retries              int
+	Client               *http.Client
+	UserAgent            string
+	Headers              http.Header
+	CommunityRepo        string
+	CommunityStanzas     []CommunityStanza
+	Config               Config
+	Values               map[string]string
+	StaleAfter           time.Duration
+	GroupScoped          bool
+	MaxStanzaHosts       int
+	MaxDescriptionLength int
+	MaxFileStanzas       int
+	MaxFileLines         int
+	OneStanzaPerFile     bool
+	SkeletonStanzas      bool
+	ImplicitBoundaries   bool
+	ServerHostname       string
 	FollowIncludeFile    bool
 	IncludeFileDirectory string
 	State                State
 	Output               io.Writer
-	PreviousTitles       map[string]string
-	PreviousOrigins      map[string]string
+	Writer               FindingWriter
+	Metadata             *Metadata
+	Profile              *Profile
+	Cache                *Cache
+	Progress             *Progress
+	RunState
+}
+
+// A RunState is the state of a run of the linter which carries over from one stanza and file to the next,
+// like the titles seen so far. It is kept apart from the linter's options, so a copy of a linter can start
+// a new run by clearing it, along with the stanza State.
+type RunState struct {
+	IncludeDepth          int
+	TopLevelFiles         map[string]bool
+	Group                 string
+	Stopped               bool
+	PreviousTitles        SeenIndex
+	PreviousOrigins       SeenIndex
+	PreviousDescriptions  SeenIndex
+	PreviousVendors       SeenIndex
+	Name                  string
+	NameAt                string
+	HAName                string
+	NameReferences        []HostLine
+	LoginPorts            map[int]string
+	RunAsAt               string
+	SkipPorts             map[string]string
+	BannerDirectivesAt    map[Directive]string
+	FirstStanzaAt         string
+	ProxyByHostname       bool
+	HostnameDirective     Directive
+	HostnameDirectiveAt   string
+	DomainThreatAt        string
+	DomainThreatCount     int
+	Fixes                 map[string]Fix
+	Backup                *Backup
+	TrailingDirectives    []string
+	IncludeFileBlock      []IncludeFileLine
+	IPRangeBlock          []IPRangeLine
+	IPRangeBlockMergeable bool
+	PreviousStanzaTitle   string
+	StanzaBreakAt         string
+	Report                Report
+	writeErr              error
+	cacheRecorders        []*cacheRecorder
+	cacheDigest           string
+	cacheLengths          cacheSeenLengths
 }
 
 func OptionPairs() map[Directive]Directive {
@@ -92,33 +222,194 @@ func CloserOptions() []Directive {
 	return slices.Collect(maps.Values(OptionPairs()))
 }
 
-var (
-	URLV1Regex = regexp.MustCompile(`(?i)^U(RL)?\s+(\S+)$`)
-	URLV2Regex = regexp.MustCompile(`(?i)^U(RL)?\s+(-Refresh )?\s*(-Redirect )?\s*(-Append -Encoded )?\s*(\S+)\s+(\S+)$`)
-	URLV3Regex = regexp.MustCompile(`(?i)^U(RL)?\s+(-Form)=([A-Za-z]+ )\s*(-RewriteHost )?\s*(\S+)\s+(\S+)$`)
+// ServerDirectives are directives which configure the whole EZproxy server,
+// and should appear in config.txt before the first stanza.
+func ServerDirectives() []Directive {
+	return []Directive{
+		FirstPort,
+		HAName,
+		HAPeer,
+		Interface,
+		LBPeer,
+		LoginCookieDomain,
+		LoginCookieName,
+		LoginPort,
+		LoginPortSSL,
+		MaxLifetime,
+		MaxSessions,
+		MaxVirtualHosts,
+		MessagesFile,
+		Name,
+		PidFile,
+		RunAs,
+		SSLCipherSuite,
+		SSLHonorCipherOrder,
+		SSLOpenSSLConfCmd,
+		UMask,
+	}
+}
+
+// The label styles which can be used for directives with abbreviated labels.
+const (
+	LabelStyleFull        = "full"
+	LabelStyleAbbreviated = "abbreviated"
 )
 
+// LabelStyles returns the label styles which LabelStyle can be set to.
+func LabelStyles() []string {
+	return []string{LabelStyleFull, LabelStyleAbbreviated}
+}
+
+// LabelStyleCheck reports on directives with abbreviated labels, like "HJ", which do not use the LabelStyle.
+// Differences in letter casing are left to the DirectiveCase check.
+// In fix mode, the label is replaced, so files maintained by different staff share one style.
+func (l *Linter) LabelStyleCheck(label, at string) (m []string) {
+	abbreviation, ok := LabelAbbreviations()[l.State.Current]
+	if !ok {
+		return m
+	}
+	expected := CanonicalLabel(l.State.Current)
+	if l.LabelStyle == LabelStyleAbbreviated {
+		expected = abbreviation
+	}
+	if strings.EqualFold(label, expected) {
+		return m
+	}
+	m = append(m, fmt.Sprintf("%q label should be replaced by %q to match the %v label style (L5008)", label, expected, l.LabelStyle))
+	l.AddFix(at, Fix{Old: label, New: expected})
+	return m
+}
+
+// SingleArgumentDirectives are directives which take exactly one argument.
+// Extra arguments after it usually mean that two lines were accidentally joined.
+func SingleArgumentDirectives() []Directive {
+	return []Directive{
+		BinaryTimeout,
+		Charset,
+		ClientTimeout,
+		ConnectWindow,
+		FirstPort,
+		Group,
+		MaxConcurrentTransfers,
+		MaxLifetime,
+		MaxSessions,
+		MaxVirtualHosts,
+		Name,
+		RemoteTimeout,
+		UMask,
+	}
+}
+
+// ProcessFile processes the file at filePath, and any files it includes.
+// Checks which apply to the whole config are run once the file has been processed.
 func (l *Linter) ProcessFile(filePath string) (warningCount int, err error) {
-	f, err := os.Open(filePath)
+	l.FirstStanzaAt = ""
+	l.DomainThreatAt = ""
+	l.DomainThreatCount = 0
+	l.Group = ""
+	if err := l.LoadCommunityStanzas(); err != nil {
+		return warningCount, err
+	}
+	warningCount, err = l.processFile(filePath)
+	if errors.Is(err, errFailFast) {
+		l.Stopped = true
+		return warningCount, nil
+	}
 	if err != nil {
 		return warningCount, err
 	}
-	defer f.Close()
+	if l.DomainThreatAt != "" {
+		// This is a security issue, so it is printed more prominently than other warnings.
+		warningCount++
+		l.ReportSecurity(l.DomainThreatAt, l.DomainThreatCheck())
+		if l.FailFastCheck([]string{l.DomainThreatCheck()}) {
+			l.Stopped = true
+			return warningCount, nil
+		}
+	}
+	if l.ServerConfig {
+		warnings := l.CategoryFilter(l.ServerConfigChecks())
+		if len(warnings) > 0 {
+			warningCount += IssueCount(warnings)
+			l.ReportLine(filePath, "", "", warnings)
+		}
+		if l.FailFastCheck(warnings) {
+			l.Stopped = true
+		}
+	}
+	return warningCount, nil
+}
+
+func (l *Linter) processFile(filePath string) (warningCount int, err error) {
+	if l.IncludeDepth == 0 {
+		if l.TopLevelFiles == nil {
+			l.TopLevelFiles = make(map[string]bool)
+		}
+		l.TopLevelFiles[filePath] = true
+	}
+	if l.Metadata != nil {
+		defer l.Metadata.StartFile(filePath)()
+	}
+	defer l.Profile.File(filePath)()
+	l.Progress.StartFile(filePath)
+
+	content, err := l.ReadFile(filePath)
+	if err != nil {
+		return warningCount, err
+	}
+	content = l.renderFile(content)
 
 	// If the IncludeFileDirectory was not set by the caller,
 	// use the parent directory of first file the linter processes.
 	if l.IncludeFileDirectory == "" {
-		l.IncludeFileDirectory = filepath.Dir(filePath)
+		l.IncludeFileDirectory = ParentDirectory(filePath)
+	}
+
+	// Files read from URLs, compressed files, and rendered templates can't be rewritten.
+	fix := l.Fix && !IsURL(filePath) && !IsGzip(filePath) && l.Values == nil
+
+	// Skip files which haven't changed since they were cached.
+	if l.Cacheable() {
+		cachedWarningCount, hit, done, err := l.startCache(filePath, content)
+		if err != nil {
+			return warningCount, fmt.Errorf("error using cache: %w", err)
+		}
+		if hit {
+			return cachedWarningCount, nil
+		}
+		defer func() {
+			if storeErr := done(warningCount, err == nil); storeErr != nil {
+				err = fmt.Errorf("error using cache: %w", storeErr)
+			}
+		}()
 	}
 
 	// Make a scanner to go through the file line by line.
-	scanner := newScanner(f)
+	scanner := newScanner(bytes.NewReader(content))
 
 	// Store the line number for output.
 	lineNum := 0
 
+	// In fix mode, store the lines and their locations so fixes can be applied.
+	var lines, ats []string
+
+	// Track the stanza directives after the final stanza separately for each file.
+	parentTrailingDirectives := l.TrailingDirectives
+	l.TrailingDirectives = []string{}
+
+	// Track the order of IncludeFile directives and stanzas separately for each file.
+	parentIncludeFileBlock := l.IncludeFileBlock
+	l.IncludeFileBlock = nil
+	parentIPRangeBlock, parentIPRangeBlockMergeable := l.IPRangeBlock, l.IPRangeBlockMergeable
+	l.IPRangeBlock, l.IPRangeBlockMergeable = nil, false
+	parentPreviousStanzaTitle := l.PreviousStanzaTitle
+	l.PreviousStanzaTitle = ""
+	parentStanzaBreakAt := l.StanzaBreakAt
+	l.StanzaBreakAt = ""
+
 	// Store information about each stanza.
 	l.State = State{}
+	stanzaCount, firstTitle := 0, ""
 
 	// Loop through each line in the file.
 	for {
@@ -156,37 +447,57 @@ func (l *Linter) ProcessFile(filePath string) (warningCount int, err error) {
 		}
 
 		at := fmt.Sprintf("%v:%v", filePath, lineNum)
+		l.Progress.Line()
+		if fix && more {
+			lines = append(lines, line)
+			ats = append(ats, at)
+		}
 
-		warnings := l.ProcessLineAt(line, at)
+		annotate := l.Annotate && more && !l.Structured()
+		// Keep the title, because the stanza state is reset when a stanza ends.
+		title := l.State.Title
+		inStanza := l.State.Title != "" || l.State.URL != ""
+		warnings := l.CategoryFilter(l.processLine(line, at))
+		if !inStanza && (l.State.Title != "" || l.State.URL != "") {
+			stanzaCount++
+		}
+		if firstTitle == "" && stanzaCount == 1 {
+			firstTitle = l.State.Title
+		}
 		if len(warnings) > 0 {
-			warningCount += len(warnings)
+			warningCount += IssueCount(warnings)
 			if l.State.LastLineEmpty {
 				// This will print any warnings that can only be checked after a stanza is closed, and apply to the whole stanza.
-				fmt.Fprintf(l.Output, "%v: %v\n", at, color.YellowString(fmt.Sprintf("↑ %v", strings.Join(warnings, ", "))))
+				l.ReportLine(at, title, "", warnings)
 				// If we're printing the whole file, print the empty line we just processed without any warnings.
 				// This helps break up the annotated output with lines between stanzas.
-				if l.Annotate && more {
+				if annotate && l.Highlight {
+					l.AnnotateLine(at, title, line)
+				} else if annotate {
 					fmt.Fprintf(l.Output, "%v:\n", at)
 				}
 			} else {
-				fmt.Fprintf(l.Output, "%v: %v %v\n", at, line, color.YellowString(fmt.Sprintf("← %v", strings.Join(warnings, ", "))))
+				l.ReportLine(at, l.State.Title, line, warnings)
 			}
-		} else if l.Annotate && more {
-			fmt.Fprintf(l.Output, "%v: %v\n", at, line)
+		} else if annotate {
+			l.AnnotateLine(at, title, line)
+		}
+		if l.FailFastCheck(warnings) {
+			return warningCount, errFailFast
 		}
 
 		// Follow IncludeFile paths recursively.
-		if l.FollowIncludeFile && l.State.Previous == IncludeFile {
-			splitLine := strings.Split(line, " ")
-			if len(splitLine) < 2 {
+		_, includeFilePath := SplitLabel(line)
+		if l.FollowIncludeFile && l.processedIncludeFile(line) {
+			if includeFilePath == "" {
 				return warningCount, fmt.Errorf("unable to find IncludeFile path on line %q", line)
 			}
-			includeFilePath := splitLine[1]
 			// If the file path for the included file is not absolute, we should
 			// join it with the IncludeFileDirectory, which has been set by the caller
 			// or to the parent directory of the first file the linter processed.
-			if !filepath.IsAbs(includeFilePath) {
-				includeFilePath = filepath.Join(l.IncludeFileDirectory, includeFilePath)
+			// Paths in files read from URLs are resolved from the URL, even if they are absolute.
+			if !filepath.IsAbs(includeFilePath) || IsURL(l.IncludeFileDirectory) {
+				includeFilePath = JoinPath(l.IncludeFileDirectory, includeFilePath)
 				if l.Verbose {
 					fmt.Fprintf(l.Output, "       Line: %v\n", line)
 					fmt.Fprintf(l.Output, "    in file: %v\n", filePath)
@@ -194,12 +505,17 @@ func (l *Linter) ProcessFile(filePath string) (warningCount int, err error) {
 				}
 			}
 
-			includeFileWarningCount, err := l.ProcessFile(includeFilePath)
-			if err != nil {
-				fmt.Fprintf(l.Output, "Error encountered when processing line %q.\n", line)
+			l.recordCacheInclude()
+			l.IncludeDepth++
+			includeFileWarningCount, err := l.processFile(includeFilePath)
+			l.IncludeDepth--
+			warningCount += includeFileWarningCount
+			if errors.Is(err, errFailFast) {
 				return warningCount, err
 			}
-			warningCount += includeFileWarningCount
+			if err != nil {
+				return warningCount, fmt.Errorf("error encountered when processing line %q at %v: %w", line, at, err)
+			}
 		}
 	}
 
@@ -207,21 +523,88 @@ func (l *Linter) ProcessFile(filePath string) (warningCount int, err error) {
 	if err := scanner.Err(); err != nil {
 		return warningCount, err
 	}
+
+	if warnings := l.CategoryFilter(l.TrailingDirectiveChecks()); len(warnings) > 0 {
+		warningCount += IssueCount(warnings)
+		l.ReportLine(filePath, "", "", warnings)
+		if l.FailFastCheck(warnings) {
+			return warningCount, errFailFast
+		}
+	}
+	if l.OneStanzaPerFile && l.IncludeDepth > 0 {
+		if warnings := l.CategoryFilter(l.StanzaFileChecks(filePath, firstTitle, stanzaCount)); len(warnings) > 0 {
+			warningCount += IssueCount(warnings)
+			l.ReportLine(filePath, "", "", warnings)
+			if l.FailFastCheck(warnings) {
+				return warningCount, errFailFast
+			}
+		}
+	}
+	if l.Pedantic {
+		if warnings := l.CategoryFilter(l.FileSizeChecks(lineNum, stanzaCount)); len(warnings) > 0 {
+			warningCount += IssueCount(warnings)
+			l.ReportLine(filePath, "", "", warnings)
+			if l.FailFastCheck(warnings) {
+				return warningCount, errFailFast
+			}
+		}
+	}
+	l.TrailingDirectives = parentTrailingDirectives
+	l.EndIncludeFileBlock()
+	l.IncludeFileBlock = parentIncludeFileBlock
+	l.EndIPRangeBlock()
+	l.IPRangeBlock, l.IPRangeBlockMergeable = parentIPRangeBlock, parentIPRangeBlockMergeable
+	l.PreviousStanzaTitle = parentPreviousStanzaTitle
+	l.StanzaBreakAt = parentStanzaBreakAt
+
+	if fix {
+		fixCount, err := l.writeFixes(filePath, content, lines, ats)
+		if err != nil {
+			return warningCount, err
+		}
+		if fixCount > 0 && !l.Structured() && !l.Diff {
+			fmt.Fprintf(l.Output, "%v: %v\n", filePath, color.GreenString(fmt.Sprintf("Fixed %v lines", fixCount)))
+		}
+	}
 	return warningCount, nil
 }
 
-func (l *Linter) ProcessLineAt(line, at string) (m []string) {
+// ProcessLineAt processes a line at a location, like "config.txt:12", and returns the findings for it.
+// Findings for an empty line apply to the stanza which just ended, and have the stanza's title.
+func (l *Linter) ProcessLineAt(line, at string) []Finding {
+	title := l.State.Title
+	messages := l.processLine(line, at)
+	if l.State.LastLineEmpty {
+		return l.NewFindings(at, title, "", messages)
+	}
+	return l.NewFindings(at, l.State.Title, line, messages)
+}
+
+// processedIncludeFile reports whether the line which was just processed is an IncludeFile directive.
+// Comments after an IncludeFile line do not change the previous directive, so the label is checked too.
+func (l *Linter) processedIncludeFile(line string) bool {
+	label, _ := SplitLabel(line)
+	directive, _ := LabelDirective(label)
+	return l.State.Previous == IncludeFile && directive == IncludeFile
+}
+
+// processLine processes a line at a location and returns the messages for it.
+func (l *Linter) processLine(line, at string) (m []string) {
+	defer l.Profile.Time(ProfileLines)()
 	// Get the OptionPairs which need to be closed.
 	optionPairs := OptionPairs()
 	openers := OpenerOptions()
 	closers := CloserOptions()
 
 	// Initialize maps if they are still nil.
-	if l.PreviousTitles == nil {
-		l.PreviousTitles = make(map[string]string)
+	if l.LoginPorts == nil {
+		l.LoginPorts = make(map[int]string)
 	}
-	if l.PreviousOrigins == nil {
-		l.PreviousOrigins = make(map[string]string)
+	if l.SkipPorts == nil {
+		l.SkipPorts = make(map[string]string)
+	}
+	if l.BannerDirectivesAt == nil {
+		l.BannerDirectivesAt = make(map[Directive]string)
 	}
 	if l.State.ProxyHostnameEditPatterns == nil {
 		l.State.ProxyHostnameEditPatterns = make(map[string]*regexp.Regexp)
@@ -229,22 +612,45 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 	if l.State.StanzaOrigins == nil {
 		l.State.StanzaOrigins = make(map[string]string)
 	}
+	if l.State.StanzaLines == nil {
+		l.State.StanzaLines = make(map[string]StanzaLine)
+	}
 
 	// Does the line end in a space or tab character?
 	if l.Whitespace && TrailingSpaceOrTabCheck(line) {
 		m = append(m, "Line ends in a space or tab character (L5002)")
 	}
 
+	// Are there template placeholders without a value?
+	m = append(m, l.PlaceholderCheck(line)...)
+
 	// Trim leading and trailing spaces to ensure the rest of the linting
 	// is uniform.
 	line = strings.TrimSpace(line)
 
+	// Does the line start a new stanza without a blank line?
+	// If so, end the stanza before it, as though there was an empty comment line.
+	// Without ImplicitBoundaries, a Title after the stanza's URL is reported, and the linter recovers the same way,
+	// so one missing blank line doesn't cause a cascade of issues in the stanzas after it.
+	if l.ImplicitBoundary(line) {
+		m = append(m, l.processLine("#", at)...)
+	} else if l.titleAfterURL(line) {
+		previous := l.State.Previous
+		m = append(m, l.processLine("#", at)...)
+		m = append(m, fmt.Sprintf("\"Title\" directive is out of order, previous directive: %q. "+
+			"A blank line might be missing before it, so it is checked as the start of a new stanza (L1001)", previous))
+	}
+
 	// Is the line empty, or an empty comment?
 	// If so, we're at the end of the stanza.
 	if line == "" || line == "#" {
+		defer l.Profile.Time(ProfileStanzas)()
 		if l.State.Title != "" && l.State.URL == "" && !l.State.IsSeparator {
 			m = append(m, fmt.Sprintf("Stanza %q has Title but no URL (L4003)", l.State.Title))
 		}
+		if l.Pedantic && (l.State.Title != "" || l.State.URL != "") && !l.State.HasSourceComment {
+			m = append(m, fmt.Sprintf("Stanza %q doesn't have a \"# Source - \" comment (L4009)", cmp.Or(l.State.Title, l.State.URL)))
+		}
 		if l.State.AddUserHeaderNeedsClosing {
 			m = append(m, fmt.Sprintf("Stanza %q uses AddUserHeader but doesn't have a corresponding \"AddUserHeader\" "+
 				"line at the end of the stanza (L4005)", l.State.Title))
@@ -260,31 +666,124 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 			}
 		}
 
+		m = append(m, l.HostReferenceChecks()...)
+		m = append(m, l.ServerHostnameChecks()...)
+
+		if l.HTTPS {
+			m = append(m, l.HTTPSHostChecks()...)
+		}
+
+		if l.RedundantHosts {
+			m = append(m, l.JavaScriptDuplicateChecks()...)
+			m = append(m, l.RedundantHostChecks()...)
+		}
+
+		if l.DomainHosts {
+			m = append(m, l.DomainHostChecks()...)
+		}
+
+		if l.SkeletonStanzas && l.State.Title != "" && l.State.URL != "" && !l.State.HasOtherDirectives {
+			m = append(m, fmt.Sprintf("Stanza %q only has Title and URL directives, it might have been pasted incompletely "+
+				"and be missing Host or Domain directives for the vendor's other hostnames (L4011)", l.State.Title))
+		}
+
+		if l.Pedantic {
+			m = append(m, l.HTTPSCounterpartChecks()...)
+			m = append(m, l.VendorChecks()...)
+		}
+
+		if l.Pedantic && l.MaxStanzaHosts > 0 {
+			m = append(m, l.StanzaSizeCheck()...)
+		}
+
+		if l.CommunityRepo != "" {
+			m = append(m, l.CommunityCheck()...)
+		}
+
+		if l.SourceCompare {
+			m = append(m, l.SourceCompareCheck()...)
+		}
+
+		if len(l.Config.HeaderPatterns) > 0 {
+			m = append(m, l.HeaderTemplateChecks()...)
+		}
+
+		if l.StaleAfter > 0 {
+			m = append(m, l.StaleCheck()...)
+		}
+
 		// If present, add the stored URL origin to the PreviousOrigins map.
+		// When checking origins, origins which were already seen keep their first location,
+		// so that later reports point to the first stanza which used the origin.
+		// Otherwise, reports point to the last stanza which used the origin.
+		addOrigin := l.PreviousOrigins.Replace
+		if l.Origins {
+			addOrigin = l.PreviousOrigins.Add
+		}
 		if l.State.URLOrigin != "" {
-			l.PreviousOrigins[l.State.URLOrigin] = l.State.URLAt
+			addOrigin(l.SeenGroup(), l.originKey(l.State.URLOrigin), l.State.URLAt)
 		}
 
 		// Copy the origins from this stanza to the PreviousOrigins map.
-		maps.Copy(l.PreviousOrigins, l.State.StanzaOrigins)
+		// They are added in line order, since several origins can have the same key with -cross-scheme-origins.
+		origins := slices.Collect(maps.Keys(l.State.StanzaOrigins))
+		slices.SortFunc(origins, func(a, b string) int {
+			_, aLine := SplitAt(l.State.StanzaOrigins[a])
+			_, bLine := SplitAt(l.State.StanzaOrigins[b])
+			return cmp.Compare(aLine, bLine)
+		})
+		for _, origin := range origins {
+			addOrigin(l.SeenGroup(), l.originKey(origin), l.State.StanzaOrigins[origin])
+		}
+
+		l.EndIncludeFileBlock()
+		l.EndIPRangeBlock()
+
+		if l.Pedantic {
+			m = append(m, l.BlankLineChecks(line, at)...)
+		}
+
+		// A block of lines without a Title ends the run of stanzas checked by StanzaOrderCheck.
+		if l.State.Title == "" && (l.State.Label != "" || len(l.State.HeaderComments) > 0) {
+			l.PreviousStanzaTitle = ""
+		}
 
 		// Reset the stanza state.
-		l.State = State{LastLineEmpty: true}
+		l.State = State{LastLineEmpty: true, BlankLine: line == ""}
 
 		return m
 	}
 
 	l.State.LastLineEmpty = false
+	l.State.BlankLine = false
 
 	// Is the line a comment?
 	if strings.HasPrefix(line, "#") {
+		if strings.HasPrefix(line, "# Source - ") {
+			l.State.HasSourceComment = true
+		}
+		if date, ok := ReviewDate(line); ok && date.After(l.State.ReviewedOn) {
+			l.State.ReviewedOn = date
+		}
+		// Comments before the first directive are the stanza's header.
+		if l.State.Label == "" {
+			l.State.HeaderComments = append(l.State.HeaderComments, line)
+		}
 		if l.Source && strings.HasPrefix(line, "# Source - ") {
-			source, oclcTitle, err := processSourceLine(line)
-			if err != nil {
+			source, stanzas, err := l.processSourceLine(line)
+			if errors.Is(err, errSourceUnavailable) && !l.SourceStrict {
+				// Network problems and changes to the page are not problems with the config.
+				m = append(m, fmt.Sprintf("Unable to check the Source page, the stanza's title was not compared (L9013): %v", err))
+			} else if err != nil {
 				m = append(m, fmt.Sprintf("Error processsing Source line (L9003): %v", err))
 			} else {
 				l.State.Source = source
-				l.State.OCLCTitle = oclcTitle
+				l.State.SourceStanzas = stanzas
+				// The stanza is selected again when the Title directive is seen, since the page can have several stanzas.
+				if selected, ok := SelectSourceStanza(stanzas); ok {
+					l.State.OCLCTitle = selected.Title
+					l.State.SourceLines = selected.Lines
+				}
 			}
 		}
 		return m
@@ -302,20 +801,28 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 
 	// Line isn't a comment or empty.
 
+	// Store the lines of the stanza to compare with the community version or the Source page.
+	if l.CommunityRepo != "" || l.SourceCompare {
+		l.State.Lines = append(l.State.Lines, CommunityLine(ResolveLine(line, at).Line))
+	}
+
 	// Reset the IsSeparator flag to false.
 	l.State.IsSeparator = false
 
-	// Split the line by spaces to find the label.
-	split := strings.Split(line, " ")
-	label := split[0]
+	// Split the line by spaces or tabs to find the label.
+	label, argument := SplitLabel(line)
 
-	// Option directives have two parts.
-	if label == "Option" {
-		if len(split) != 2 {
+	// Option directives have two parts, except for a few options with longer names.
+	if strings.EqualFold(label, "Option") {
+		_, known := LowercaseLabelToDirective[strings.ToLower(line)]
+		if !known && (argument == "" || strings.ContainsAny(argument, " \t")) {
 			m = append(m, "Option directive not in the form \"Option OPTIONNAME\" (L3008)")
 			return m
 		}
 		label = line
+		if !known {
+			label += " " + argument
+		}
 	}
 
 	// Find the Directive which matches this label.
@@ -327,12 +834,41 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 			return m
 		}
 		if l.DirectiveCase {
-			m = append(m, fmt.Sprintf("%q directive does not have the right letter casing. It should be replaced by %q (L5001)", label, directive))
+			canonical := LowercaseLabelToLabel[strings.ToLower(label)]
+			m = append(m, fmt.Sprintf("%q directive does not have the right letter casing. It should be replaced by %q (L5001)", label, canonical))
+			l.AddFix(at, Fix{Old: label, New: canonical})
 		}
 	}
+	if l.Pedantic && l.State.Label == "" && l.StanzaBreakAt != "" && slices.Contains(StanzaDirectives(), directive) {
+		m = append(m, fmt.Sprintf("Blank line at %q splits the stanza before it, so this %q directive does not apply to that stanza (L3019)",
+			l.StanzaBreakAt, directive))
+		l.AddFix(l.StanzaBreakAt, Fix{Delete: true})
+	}
 	l.State.Current = directive
 	l.State.Label = label
 
+	if l.LabelStyle != "" {
+		m = append(m, l.LabelStyleCheck(label, at)...)
+	}
+
+	if l.Pedantic {
+		m = append(m, l.IncludeFileOrderCheck(directive, line, at)...)
+		m = append(m, l.IPRangeMergeCheck(directive, line, at)...)
+	}
+
+	if directive != Title && directive != URL {
+		l.State.HasOtherDirectives = true
+	}
+
+	// Track stanza directives which are not part of a stanza with a Title or URL.
+	// If no other stanza follows them in the file, they are reported when the file is done.
+	if directive == Title || directive == URL {
+		l.TrailingDirectives = l.TrailingDirectives[:0]
+	} else if l.State.Title == "" && l.State.URL == "" && slices.Contains(StanzaDirectives(), directive) {
+		l.TrailingDirectives = append(l.TrailingDirectives,
+			fmt.Sprintf("%q directive at %q is after the final stanza, and does not apply to any stanza (L1016)", directive, at))
+	}
+
 	// Short-circuit check for Find/Replace pairs.
 	// Without this, we would need to check that the previous
 	// directive was not Find on every directive other than Replace.
@@ -361,6 +897,16 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 		}
 	}
 
+	if l.ServerConfig {
+		m = append(m, l.ProcessServerDirective(at)...)
+	}
+
+	m = append(m, l.DuplicateLineCheck(line, at)...)
+
+	if slices.Contains(SingleArgumentDirectives(), directive) {
+		m = append(m, l.SingleArgumentCheck(line)...)
+	}
+
 	// Process Option Pair directives.
 	if slices.Contains(openers, directive) {
 		m = append(m, l.ProcessOptionOpener(line)...)
@@ -370,7 +916,14 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 
 	// Process other directives.
 	switch directive {
+	case OptionProxyByHostname:
+		m = append(m, l.ProcessProxyByHostname()...)
+	case OptionIChooseToUseDomainLinesThatThreatenTheSecurityOfMyNetwork:
+		l.DomainThreatAt = at
+	case OptionHttpsHyphens, OptionNoHttpsHyphens:
+		m = append(m, l.ProcessRequiresProxyByHostname(at)...)
 	case ProxyHostnameEdit:
+		m = append(m, l.ProcessRequiresProxyByHostname(at)...)
 		m = append(m, l.ProcessProxyHostnameEdit(line)...)
 	case AddUserHeader:
 		m = append(m, l.ProcessAddUserHeader(line)...)
@@ -378,6 +931,9 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 		m = append(m, l.ProcessAnonymousURL(line)...)
 	case Title:
 		m = append(m, l.ProcessTitle(line, at)...)
+		if l.Pedantic {
+			m = append(m, l.StanzaOrderCheck()...)
+		}
 	case Description:
 		m = append(m, l.ProcessDescription(line, at)...)
 	case URL:
@@ -385,12 +941,129 @@ func (l *Linter) ProcessLineAt(line, at string) (m []string) {
 	case Host, HostJavaScript:
 		m = append(m, l.ProcessHostAndHostJavaScript(line, at)...)
 	case Domain, DomainJavaScript:
-		m = append(m, l.ProcessDomainAndDomainJavaScript(line)...)
+		m = append(m, l.ProcessDomainAndDomainJavaScript(line, at)...)
+	case AutoLoginIPBanner, ExcludeIPBanner:
+		m = append(m, l.ProcessBanner(line, at)...)
+		if l.FileReferences {
+			m = append(m, l.ProcessFileReference(line)...)
+		}
+	case Validate, Identifier:
+		m = append(m, l.ProcessValidateAndIdentifier(line)...)
+	case ByteServe, PDFRefresh, PDFRefreshPre, PDFRefreshPost:
+		m = append(m, l.ProcessByteServeAndPDFRefresh(line, at)...)
+	case MetaFind:
+		m = append(m, l.ProcessMetaFind(line)...)
+	case FormSelect, FormVariable, FormSubmit:
+		m = append(m, l.ProcessForm()...)
+	case DbVar0, DbVar1, DbVar2, DbVar3, DbVar4, DbVar5, DbVar6, DbVar7, DbVar8, DbVar9:
+		m = append(m, l.ProcessDbVar(line, at)...)
+	case AllowVars:
+		for _, v := range strings.Fields(TrimLabel(line, l.State.Label)) {
+			if !strings.HasPrefix(v, "-") {
+				l.State.AllowedVars = append(l.State.AllowedVars, v)
+			}
+		}
+	case EncryptVar:
+		m = append(m, l.ProcessEncryptVar(line)...)
+	case Location, Charset:
+		m = append(m, l.ProcessLocationAndCharset()...)
+	case AutoLoginIP, ExcludeIP:
+		if _, seen := l.BannerDirectivesAt[l.State.Current]; !seen {
+			l.BannerDirectivesAt[l.State.Current] = at
+		}
+	case MessagesFile, LoginMenu, ShibbolethMetadata, LogFile:
+		if l.FileReferences {
+			m = append(m, l.ProcessFileReference(line)...)
+		}
+	case Name:
+		m = append(m, l.ProcessName(line, at)...)
+	case Interface:
+		if value := TrimLabel(line, l.State.Label); IsHostname(value) {
+			m = append(m, l.AddNameReference(value, at)...)
+		}
+	case Group:
+		l.Group = TrimLabel(line, l.State.Label)
+	case HAName, HAPeer, LBPeer:
+		m = append(m, l.ProcessPeer(line, at)...)
+	case LoginCookieName, LoginCookieDomain, ExtraLoginCookie:
+		m = append(m, l.ProcessLoginCookie(line, at)...)
+	case LoginPort, LoginPortSSL:
+		m = append(m, l.ProcessLoginPort(line, at)...)
+	case RunAs:
+		l.RunAsAt = at
+	case SkipPort:
+		m = append(m, l.ProcessSkipPort(line, at)...)
 	}
 	l.State.Previous = directive
 	return m
 }
 
+// SeenGroup returns the group used to store titles and origins seen in previous stanzas.
+// When GroupScoped is set, it is the current Group, so that stanzas in different
+// Group contexts can use the same titles and origins. Stanzas before any Group directive are in the Default group.
+// EZproxy group names are not case sensitive.
+func (l *Linter) SeenGroup() string {
+	if !l.GroupScoped {
+		return ""
+	}
+	return strings.ToLower(cmp.Or(l.Group, "Default"))
+}
+
+// StanzaDirectives returns the directives which only have an effect as part of a database stanza.
+func StanzaDirectives() []Directive {
+	return []Directive{
+		Description,
+		Domain,
+		DomainJavaScript,
+		Find,
+		Host,
+		HostJavaScript,
+		Replace,
+	}
+}
+
+// TrailingDirectiveChecks reports on stanza directives which appear after the final stanza in a file.
+// They do not apply to any stanza, and are usually left behind when a stanza is deleted.
+func (l *Linter) TrailingDirectiveChecks() (m []string) {
+	return l.TrailingDirectives
+}
+
+// DuplicateLineCheck reports on Host, HostJavaScript, Domain, DomainJavaScript, and Option lines
+// which are exact duplicates of an earlier line in the same stanza.
+// Repeating these lines has no effect, and usually happens when updated vendor stanzas are merged by hand.
+// In fix mode, the duplicate lines are removed. A line which is repeated before and after the stanza's Title and URL
+// isn't fixed, since only one of them is in the right place, and it might be the later one.
+func (l *Linter) DuplicateLineCheck(line, at string) (m []string) {
+	isHostLine := slices.Contains([]Directive{Host, HostJavaScript, Domain, DomainJavaScript}, l.State.Current)
+	if !isHostLine && !strings.HasPrefix(l.State.Current.String(), "Option") {
+		return m
+	}
+	key := line
+	if !isHostLine {
+		// Opening an option makes a later closer meaningful again, and the reverse,
+		// so only repeats without the paired option in between are duplicates.
+		key = l.State.Current.String()
+		for opener, closer := range OptionPairs() {
+			if opener == l.State.Current {
+				delete(l.State.StanzaLines, closer.String())
+			} else if closer == l.State.Current {
+				delete(l.State.StanzaLines, opener.String())
+			}
+		}
+	}
+	beforeStanza := l.State.Title == "" && l.State.URL == ""
+	first, seen := l.State.StanzaLines[key]
+	if seen {
+		m = append(m, fmt.Sprintf("Duplicate line, already seen at %q (L2009)", first.At))
+		if first.BeforeStanza == beforeStanza {
+			l.AddFix(at, Fix{Delete: true})
+		}
+		return m
+	}
+	l.State.StanzaLines[key] = StanzaLine{At: at, BeforeStanza: beforeStanza}
+	return m
+}
+
 // ProcessOptionOpener processes the line containing an Option which will need to be closed later.
 func (l *Linter) ProcessOptionOpener(line string) (m []string) {
 	allowedPreviousDirectives := []Directive{
@@ -434,6 +1107,11 @@ func (l *Linter) ProcessOptionCloser(line string) (m []string) {
 		AddUserHeader,
 		AnonymousURL,
 		NeverProxy,
+		MetaFind,
+		ByteServe,
+		PDFRefresh,
+		PDFRefreshPre,
+		PDFRefreshPost,
 	}
 	allowedPreviousDirectives = append(allowedPreviousDirectives, CloserOptions()...)
 	if !slices.Contains(allowedPreviousDirectives, l.State.Previous) {
@@ -442,6 +1120,7 @@ func (l *Linter) ProcessOptionCloser(line string) (m []string) {
 	l.State.OpenOptions = slices.DeleteFunc(l.State.OpenOptions, func(d Directive) bool {
 		return optionPairs[d] == l.State.Current
 	})
+	l.State.ClosedOptions = append(l.State.ClosedOptions, l.State.Current)
 	return m
 }
 
@@ -502,6 +1181,26 @@ func (l *Linter) ProcessProxyHostnameEdit(line string) (m []string) {
 		re := regexp.MustCompile(`[.]` + regexp.QuoteMeta(find) + `$`)
 		l.State.ProxyHostnameEditPatterns[find] = re
 	}
+
+	m = append(m, l.NoHttpsHyphensCheck(findReplacePair[0], findReplacePair[1])...)
+	return m
+}
+
+// NoHttpsHyphensCheck reports on a ProxyHostnameEdit directive which replaces the periods in a hostname with hyphens
+// inside an Option NoHttpsHyphens block, which keeps the periods in proxied https hostnames. The two directives
+// contradict each other, so http and https links to the site might be proxied with different hostnames.
+// Configs which are reported for not proxying by hostname at all aren't reported again.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Option_HttpsHyphens_Option_NoHttpsHyphens
+func (l *Linter) NoHttpsHyphensCheck(find, replace string) (m []string) {
+	if !slices.Contains(l.State.OpenOptions, OptionNoHttpsHyphens) || (l.ServerConfig && !l.ProxyByHostname) {
+		return m
+	}
+	find = strings.TrimSuffix(find, "$")
+	if strings.Contains(find, ".") && strings.ReplaceAll(find, ".", "-") == replace {
+		m = append(m, fmt.Sprintf("\"ProxyHostnameEdit\" directive replaces the periods in %q with hyphens, "+
+			"but \"Option NoHttpsHyphens\" keeps the periods in proxied https hostnames (L9016)", find))
+	}
 	return m
 }
 
@@ -645,16 +1344,29 @@ func (l *Linter) ProcessTitle(line, at string) (m []string) {
 	if l.State.Title != "" {
 		m = append(m, "Duplicate \"Title\" directive in stanza (L2001)")
 	}
+	if l.FirstStanzaAt == "" {
+		l.FirstStanzaAt = at
+	}
 	l.State.Title = TrimLabel(line, l.State.Label)
-	titleSeenAt, titleSeen := l.PreviousTitles[l.State.Title]
+	titleSeenAt, titleSeen := l.PreviousTitles.Seen(l.SeenGroup(), l.State.Title)
 	if titleSeen {
 		m = append(m, fmt.Sprintf("\"Title\" directive value already seen at %q (L2004)", titleSeenAt))
+		m = append(m, l.IncludedDuplicateCheck("title", l.State.Title, titleSeenAt, at)...)
 	} else {
-		l.PreviousTitles[l.State.Title] = at
+		l.PreviousTitles.Add(l.SeenGroup(), l.State.Title, at)
 	}
 
 	titleWithHideRemoved := strings.TrimPrefix(l.State.Title, "-Hide ")
-	if l.State.OCLCTitle != "" && l.State.Title != l.State.OCLCTitle && titleWithHideRemoved != l.State.OCLCTitle {
+	if selected, ok := SelectSourceStanza(l.State.SourceStanzas, titleWithHideRemoved, l.Config.TitleAliases[titleWithHideRemoved]); ok {
+		l.State.OCLCTitle = selected.Title
+		l.State.SourceLines = selected.Lines
+	}
+	if alias, renamed := l.Config.TitleAliases[titleWithHideRemoved]; renamed && l.State.OCLCTitle != "" {
+		// The stanza was intentionally renamed, so only report if the OCLC title changed.
+		if alias != l.State.OCLCTitle {
+			m = append(m, fmt.Sprintf("Source title doesn't match the title %q this stanza was renamed from, you might need to update this stanza (L9002)", alias))
+		}
+	} else if l.State.OCLCTitle != "" && l.State.Title != l.State.OCLCTitle && titleWithHideRemoved != l.State.OCLCTitle {
 		m = append(m, "Source title doesn't match, you might need to update this stanza (L9002)")
 	}
 	return m
@@ -672,6 +1384,19 @@ func (l *Linter) ProcessDescription(line, at string) (m []string) {
 		m = append(m, fmt.Sprintf("\"Description\" directive is out of order, previous directive: %q (L1013)", l.State.Previous))
 	}
 
+	if l.Pedantic {
+		description := TrimLabel(line, l.State.Label)
+		if l.MaxDescriptionLength > 0 && len(description) > l.MaxDescriptionLength {
+			m = append(m, fmt.Sprintf("\"Description\" directive value is %v characters long, more than %v (L5007)", len(description), l.MaxDescriptionLength))
+		}
+		descriptionSeenAt, descriptionSeen := l.PreviousDescriptions.Seen(l.SeenGroup(), description)
+		if descriptionSeen {
+			m = append(m, fmt.Sprintf("\"Description\" directive value already seen at %q (L2011)", descriptionSeenAt))
+		} else {
+			l.PreviousDescriptions.Add(l.SeenGroup(), description, at)
+		}
+	}
+
 	// From the documentation: "EZproxy supports a special database stanza comprised of only a
 	// single Title directive and one or more Description directives."
 	// That special stanza designation is stored in l.State.IsSeparator.
@@ -684,13 +1409,15 @@ func (l *Linter) ProcessDescription(line, at string) (m []string) {
 // https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Host_H
 // https://help.oclc.org/Library_Management/EZproxy/Configure_resources/HostJavaScript_HJ
 func (l *Linter) ProcessHostAndHostJavaScript(line, at string) (m []string) {
+	defer l.Profile.Time(ProfileHosts)()
 	trimmed := TrimLabel(line, l.State.Label)
 	parsedURL, err := url.Parse(trimmed)
 	if err != nil {
 		m = append(m, fmt.Sprintf("Unable to parse URL, might be malformed: %v (L3005)", err))
 		return
 	}
-	if parsedURL.Host == "" {
+	explicitScheme := parsedURL.Host != ""
+	if !explicitScheme {
 		// This H/HJ line did not have a scheme.
 		// Per the EZproxy docs, http:// is assumed.
 		parsedURL, err = url.Parse("http://" + trimmed)
@@ -699,10 +1426,30 @@ func (l *Linter) ProcessHostAndHostJavaScript(line, at string) (m []string) {
 			return
 		}
 	}
-	origin := fmt.Sprintf("%v://%v", parsedURL.Scheme, parsedURL.Host)
+	// EZproxy does not support wildcards in Host lines, a Domain line should be used instead.
+	if strings.Contains(parsedURL.Hostname(), "*") {
+		suggestion := "Domain"
+		if l.State.Current == HostJavaScript {
+			suggestion = "DomainJavaScript"
+		}
+		if len(l.State.Label) <= 2 {
+			suggestion = LabelAbbreviations()[LabelToDirective[suggestion]]
+		}
+		suggestion += " " + strings.TrimLeft(parsedURL.Hostname(), "*.")
+		m = append(m, fmt.Sprintf("Wildcards are not supported in %q directives, it should be replaced by %q (L3012)", l.State.Current, suggestion))
+		l.AddFix(at, Fix{Old: line, New: suggestion})
+		return m
+	}
+	m = append(m, PortChecks(parsedURL)...)
+	m = append(m, StartingPointURLChecks(parsedURL)...)
+	m = append(m, HostnameChecks(parsedURL.Hostname())...)
+	if l.Pedantic {
+		m = append(m, l.NormalizedURLCheck(trimmed, false, at)...)
+	}
+	origin := NormalizeOrigin(parsedURL)
 	// Check the origin against origins seen in other stanzas.
-	originSeenAt, originSeen := l.PreviousOrigins[origin]
-	if originSeen {
+	originSeenAt, originSeen := l.PreviousOrigins.Seen(l.SeenGroup(), l.originKey(origin))
+	if originSeen && !l.Config.SharedOrigin(origin) {
 		m = append(m, fmt.Sprintf("Origin already seen at %q (L2002)", originSeenAt))
 	}
 	// Check the origin against origins seen in the current stanza.
@@ -713,6 +1460,14 @@ func (l *Linter) ProcessHostAndHostJavaScript(line, at string) (m []string) {
 	if !originSeen {
 		l.State.StanzaOrigins[origin] = at
 	}
+	l.State.HostLines = append(l.State.HostLines, HostLine{
+		Directive:      l.State.Current,
+		Scheme:         parsedURL.Scheme,
+		ExplicitScheme: explicitScheme,
+		Host:           strings.ToLower(parsedURL.Hostname()),
+		Port:           parsedURL.Port(),
+		At:             at,
+	})
 
 	return m
 }
@@ -721,7 +1476,8 @@ func (l *Linter) ProcessHostAndHostJavaScript(line, at string) (m []string) {
 // OCLC documentation:
 // https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Domain_D
 // https://help.oclc.org/Library_Management/EZproxy/Configure_resources/DomainJavaScript_DJ
-func (l *Linter) ProcessDomainAndDomainJavaScript(line string) (m []string) {
+func (l *Linter) ProcessDomainAndDomainJavaScript(line, at string) (m []string) {
+	defer l.Profile.Time(ProfileHosts)()
 	parsedURL, err := url.Parse(TrimLabel(line, l.State.Label))
 	if err != nil {
 		m = append(m, fmt.Sprintf("Unable to parse URL, might be malformed: %v (L3005)", err))
@@ -730,78 +1486,1125 @@ func (l *Linter) ProcessDomainAndDomainJavaScript(line string) (m []string) {
 	if parsedURL.Scheme != "" || strings.Contains(parsedURL.Path, "/") {
 		m = append(m, "Domain and DomainJavaScript directives should only specify domains (L3004)")
 	}
+	domain := strings.ToLower(strings.TrimPrefix(TrimLabel(line, l.State.Label), "."))
+	m = append(m, HostnameChecks(domain)...)
+	// Count the Domain lines which are only allowed because of the security threat option.
+	if suffix, _ := publicsuffix.PublicSuffix(domain); l.DomainThreatAt != "" && suffix == domain {
+		l.DomainThreatCount++
+	}
+	l.State.HostLines = append(l.State.HostLines, HostLine{Directive: l.State.Current, Host: domain, At: at})
 	return m
 }
 
-// ProcessURL processes the line containing a URL directive.
-// OCLC documention:
-// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_1
-// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_2
-// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_3
-func (l *Linter) ProcessURL(line, at string) (m []string) {
-	allowedPreviousDirectives := []Directive{
-		AllowVars,
-		Description,
-		EBLSecret,
-		EbrarySite,
-		EncryptVar,
-		HTTPHeader,
-		MimeFilter,
-		Title,
+// JavaScriptDuplicateChecks reports on hostnames which appear in both a Host and HostJavaScript line,
+// or in both a Domain and DomainJavaScript line, in the same stanza. Only the JavaScript variant is needed,
+// so in fix mode the other line is removed.
+func (l *Linter) JavaScriptDuplicateChecks() (m []string) {
+	javaScriptVariants := map[Directive]Directive{
+		Host:   HostJavaScript,
+		Domain: DomainJavaScript,
 	}
-	if !slices.Contains(allowedPreviousDirectives, l.State.Previous) {
-		m = append(m, fmt.Sprintf("\"URL\" directive is out of order, previous directive: %q (L1002)", l.State.Previous))
+	for _, h := range l.State.HostLines {
+		variant, ok := javaScriptVariants[h.Directive]
+		if !ok {
+			continue
+		}
+		for _, j := range l.State.HostLines {
+			if j.Directive == variant && j.Scheme == h.Scheme && j.Host == h.Host && j.Port == h.Port {
+				m = append(m, fmt.Sprintf("%q directive for %q at %q is duplicated by %q directive at %q, "+
+					"only the %q directive is needed (L2008)", h.Directive, h.Host, h.At, j.Directive, j.At, j.Directive))
+				l.AddFix(h.At, Fix{Delete: true})
+				break
+			}
+		}
 	}
+	return m
+}
 
-	if l.State.Title == "" {
-		m = append(m, "\"URL\" directive is before \"Title\" directive (L1010)")
+// HTTPSHostChecks reports on Host and HostJavaScript lines which explicitly use the http scheme
+// when an https variant of the same hostname is present in the stanza, either in the URL
+// or in another Host or HostJavaScript line.
+func (l *Linter) HTTPSHostChecks() (m []string) {
+	httpsAt := map[string]string{}
+	if strings.HasPrefix(l.State.URLOrigin, "https://") {
+		if u, err := url.Parse(l.State.URLOrigin); err == nil {
+			httpsAt[strings.ToLower(u.Hostname())] = l.State.URLAt
+		}
 	}
-	if l.State.URL != "" {
-		m = append(m, "Duplicate \"URL\" directive in stanza (L2003)")
+	for _, h := range l.State.HostLines {
+		if _, seen := httpsAt[h.Host]; !seen && h.Scheme == "https" {
+			httpsAt[h.Host] = h.At
+		}
 	}
-
-	urlFromLine := FindURLFromLine(line)
-	if urlFromLine == "" {
-		m = append(m, "\"URL\" directive is not in the right format. (L3009)")
+	for _, h := range l.State.HostLines {
+		if !h.ExplicitScheme || h.Scheme != "http" {
+			continue
+		}
+		if at, seen := httpsAt[h.Host]; seen {
+			m = append(m, fmt.Sprintf("%q directive at %q is not using HTTPS scheme, an https variant of %q is at %q (L3007)",
+				h.Directive, h.At, h.Host, at))
+		}
 	}
-	l.State.URL = urlFromLine
-	parsedURL, err := url.Parse(l.State.URL)
-	if err != nil {
-		m = append(m, fmt.Sprintf("Unable to parse URL, might be malformed: %v (L3005)", err))
-		return
+	return m
+}
+
+// HTTPSCounterpartChecks reports on Host and HostJavaScript lines for a host which is only proxied with one
+// of the http and https schemes in the stanza. Vendor platforms which mix protocols commonly need both.
+// Hosts in the URL directive, and hosts covered by a Domain or DomainJavaScript line, which proxies both schemes, count as proxied.
+func (l *Linter) HTTPSCounterpartChecks() (m []string) {
+	schemes := map[string]map[string]bool{}
+	add := func(host, scheme string) {
+		if schemes[host] == nil {
+			schemes[host] = map[string]bool{}
+		}
+		schemes[host][scheme] = true
 	}
-	if parsedURL.Host == "" {
-		m = append(m, "URL does not start with http or https (L3006)")
-		return
+	if u, err := url.Parse(l.State.URLOrigin); err == nil && l.State.URLOrigin != "" {
+		add(strings.ToLower(u.Hostname()), u.Scheme)
 	}
-	if l.HTTPS && parsedURL.Scheme != "https" {
-		m = append(m, "URL is not using HTTPS scheme (L3007)")
+	for _, h := range l.State.HostLines {
+		if h.Directive == Host || h.Directive == HostJavaScript {
+			add(h.Host, h.Scheme)
+		}
 	}
-	// According to the EZproxy docs at
-	// https://help.oclc.org/Library_Management/EZproxy/EZproxy_configuration/Starting_point_URLs_and_config_txt,
-	// URL, Host, and HostJavaScript directives are checked for starting point URLs.
-	// So many stanzas duplicate the URL in an HJ or H line that adding the URL's
-	// origin to PreviousOrigins would add a lot of noise to the output.
-	// Instead, we add the URL's origin and the filename/line combination (the 'at')
-	// to the Linter's State so that we can add it to PreviousOrigins when we're done
-	// processing the stanza.
-	l.State.URLOrigin = fmt.Sprintf("%v://%v", parsedURL.Scheme, parsedURL.Host)
-	l.State.URLAt = at
-	originSeenAt, originSeen := l.PreviousOrigins[l.State.URLOrigin]
-	if originSeen {
-		m = append(m, fmt.Sprintf("Origin already seen at %q (L2002)", originSeenAt))
+	for _, h := range l.State.HostLines {
+		if h.Directive != Host && h.Directive != HostJavaScript {
+			continue
+		}
+		counterpart := "https"
+		if h.Scheme == "https" {
+			counterpart = "http"
+		}
+		if schemes[h.Host][counterpart] || slices.ContainsFunc(l.State.HostLines, func(d HostLine) bool {
+			return (d.Directive == Domain || d.Directive == DomainJavaScript) && h.CoveredBy(d)
+		}) {
+			continue
+		}
+		// Only suggest each counterpart once, even if the host is repeated.
+		add(h.Host, counterpart)
+		m = append(m, fmt.Sprintf("%q directive for %q at %q does not have an %v counterpart, consider adding \"%v %v://%v\" (L4017)",
+			h.Directive, h.Host, h.At, counterpart, h.Directive, counterpart, h.Host))
 	}
 	return m
 }
 
-func FindURLFromLine(line string) string {
-	regexes := []*regexp.Regexp{URLV1Regex, URLV2Regex, URLV3Regex}
-	for _, re := range regexes {
-		if match := re.FindStringSubmatch(line); match != nil {
-			return match[len(match)-1]
+// RedundantHostChecks reports on Host and HostJavaScript lines which are already covered
+// by a Domain or DomainJavaScript line in the same stanza. In fix mode, the redundant lines are removed.
+func (l *Linter) RedundantHostChecks() (m []string) {
+	for _, h := range l.State.HostLines {
+		if h.Directive != Host && h.Directive != HostJavaScript {
+			continue
+		}
+		for _, d := range l.State.HostLines {
+			if (d.Directive == Domain || d.Directive == DomainJavaScript) && h.CoveredBy(d) {
+				m = append(m, fmt.Sprintf("%q directive for %q at %q is already covered by %q directive for %q at %q (L2007)",
+					h.Directive, h.Host, h.At, d.Directive, d.Host, d.At))
+				l.AddFix(h.At, Fix{Delete: true})
+				break
+			}
+		}
+	}
+	return m
+}
+
+// DomainHostChecks suggests replacing each Domain or DomainJavaScript line with Host or HostJavaScript lines
+// for the hostnames it covers which are used by the stanza's URL, Host, and HostJavaScript lines.
+// A Domain line proxies every subdomain, OCLC recommends proxying only the hostnames a resource needs.
+// Domain lines which don't cover any of the stanza's hostnames are not reported, since the hostnames they are needed for aren't known.
+// In fix mode, the Domain line is replaced by Host lines for the hostnames which don't already have one.
+func (l *Linter) DomainHostChecks() (m []string) {
+	var used []HostLine
+	if u, err := url.Parse(l.State.URLOrigin); err == nil && l.State.URLOrigin != "" {
+		used = append(used, HostLine{Directive: URL, Scheme: u.Scheme, Host: strings.ToLower(u.Hostname()), Port: u.Port()})
+	}
+	for _, h := range l.State.HostLines {
+		if h.Directive == Host || h.Directive == HostJavaScript {
+			used = append(used, h)
+		}
+	}
+	for _, d := range l.State.HostLines {
+		if d.Directive != Domain && d.Directive != DomainJavaScript {
+			continue
+		}
+		replacement := Host
+		if d.Directive == DomainJavaScript {
+			replacement = HostJavaScript
+		}
+		var hostnames, lines []string
+		explicit := map[string]bool{}
+		for _, h := range used {
+			if h.Host != d.Host && !strings.HasSuffix(h.Host, "."+d.Host) {
+				continue
+			}
+			if !slices.Contains(hostnames, h.Host) {
+				hostnames = append(hostnames, h.Host)
+			}
+			// Host lines are only needed for the origins which aren't already in one.
+			if h.Directive == replacement || (h.Directive == HostJavaScript && replacement == Host) {
+				explicit[hostLineOrigin(h)] = true
+			}
+		}
+		for _, h := range used {
+			origin := hostLineOrigin(h)
+			line := fmt.Sprintf("%v %v", replacement, origin)
+			if slices.Contains(hostnames, h.Host) && !explicit[origin] && !slices.Contains(lines, line) {
+				lines = append(lines, line)
+			}
+		}
+		if len(hostnames) == 0 {
+			continue
+		}
+		if len(lines) == 0 {
+			m = append(m, fmt.Sprintf("%q directive for %q at %q covers every subdomain, but the stanza only uses %v, "+
+				"which already have %q directives, consider removing it (L9012)", d.Directive, d.Host, d.At, strings.Join(hostnames, ", "), replacement))
+			l.AddFix(d.At, Fix{Delete: true})
+			continue
+		}
+		m = append(m, fmt.Sprintf("%q directive for %q at %q covers every subdomain, but the stanza only uses %v, "+
+			"consider replacing it with \"%v\" (L9012)", d.Directive, d.Host, d.At, strings.Join(hostnames, ", "), strings.Join(lines, "\", \"")))
+		l.AddFix(d.At, Fix{Lines: lines})
+	}
+	return m
+}
+
+// hostLineOrigin returns the origin of a URL, Host, or HostJavaScript line.
+func hostLineOrigin(h HostLine) string {
+	if h.Port != "" {
+		return h.Scheme + "://" + h.Host + ":" + h.Port
+	}
+	return h.Scheme + "://" + h.Host
+}
+
+// ImplicitBoundary reports whether the trimmed line starts a new stanza in a config which doesn't separate stanzas
+// with blank lines, when ImplicitBoundaries is set. A header comment matching the StanzaHeader setting after a directive,
+// or a Title directive after a stanza's URL directive, starts a new stanza.
+func (l *Linter) ImplicitBoundary(line string) bool {
+	if !l.ImplicitBoundaries || l.State.Label == "" || l.State.InMultiline {
+		return false
+	}
+	if strings.HasPrefix(line, "#") {
+		return l.Config.StanzaHeaderPattern().MatchString(line)
+	}
+	return l.titleAfterURL(line)
+}
+
+// titleAfterURL reports whether the trimmed line is a Title directive after the URL directive of the current stanza,
+// which starts a new stanza since EZproxy stanzas start with a Title.
+func (l *Linter) titleAfterURL(line string) bool {
+	if l.State.Label == "" || l.State.InMultiline {
+		return false
+	}
+	label, _ := SplitLabel(line)
+	directive, ok := LabelDirective(label)
+	return ok && directive == Title && l.State.URL != ""
+}
+
+// BlankLineChecks checks the empty line or "#" line which just ended a block of lines.
+// Blocks should be separated by exactly one empty line. In fix mode, extra empty lines are removed.
+// The location of an empty line after a stanza is kept, so that StanzaDirectives after it can be reported.
+func (l *Linter) BlankLineChecks(line, at string) (m []string) {
+	if line == "" && l.State.BlankLine {
+		m = append(m, "Extra blank line, stanzas should be separated by one blank line (L5005)")
+		l.AddFix(at, Fix{Delete: true})
+	}
+	switch {
+	case l.State.Title != "" || l.State.URL != "":
+		l.StanzaBreakAt = ""
+		if line == "" {
+			l.StanzaBreakAt = at
+		}
+	case l.State.Label != "" || len(l.State.HeaderComments) > 0 || line == "#":
+		l.StanzaBreakAt = ""
+	}
+	return m
+}
+
+// FileSizeChecks reports files with more than MaxFileStanzas stanzas, or more than MaxFileLines lines.
+// Large files are hard to review, and many institutions keep each database in its own file, included with IncludeFile.
+// Zero limits are not checked.
+func (l *Linter) FileSizeChecks(lines, stanzas int) (m []string) {
+	if l.MaxFileStanzas > 0 && stanzas > l.MaxFileStanzas {
+		m = append(m, fmt.Sprintf("File has %v stanzas, more than %v. Consider moving stanzas into their own files, "+
+			"included with IncludeFile directives (L5009)", stanzas, l.MaxFileStanzas))
+	}
+	if l.MaxFileLines > 0 && lines > l.MaxFileLines {
+		m = append(m, fmt.Sprintf("File has %v lines, more than %v. Consider moving stanzas into their own files, "+
+			"included with IncludeFile directives (L5009)", lines, l.MaxFileLines))
+	}
+	return m
+}
+
+// DefaultStanzaFileName is the pattern for the names of files with one stanza, if the config doesn't set one.
+const DefaultStanzaFileName = "{slug}.txt"
+
+// TitleSlug returns the title without the -Hide qualifier, in lowercase, with each run of characters
+// other than letters and digits replaced by a "-". For example, the slug of "-Hide JSTOR (Arts & Sciences)"
+// is "jstor-arts-sciences".
+func TitleSlug(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(strings.TrimPrefix(title, "-Hide ")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
+
+// StanzaFileChecks reports files read with IncludeFile which don't have exactly one stanza,
+// or whose name doesn't match the StanzaFileName pattern in the config with the slug of the stanza's title.
+// Keeping one stanza in each file, named after its title, makes the layout of the config predictable.
+func (l *Linter) StanzaFileChecks(filePath, title string, stanzas int) (m []string) {
+	if stanzas != 1 {
+		m = append(m, fmt.Sprintf("File read with IncludeFile has %v stanzas, it should have exactly one (L5010)", stanzas))
+	}
+	if title == "" {
+		return m
+	}
+	pattern := strings.ReplaceAll(cmp.Or(l.Config.StanzaFileName, DefaultStanzaFileName), "{slug}", TitleSlug(title))
+	name := filepath.Base(filePath)
+	if matched, _ := filepath.Match(strings.ToLower(pattern), strings.ToLower(name)); !matched {
+		m = append(m, fmt.Sprintf("File name %q does not match %q, made from the title of its stanza %q (L5010)", name, pattern, title))
+	}
+	return m
+}
+
+// StanzaSizeCheck reports stanzas with more than MaxStanzaHosts Host, HostJavaScript, Domain, and DomainJavaScript directives.
+// Long lists of hosts are hard to maintain, and can often be replaced by a few Domain directives.
+func (l *Linter) StanzaSizeCheck() (m []string) {
+	if len(l.State.HostLines) > l.MaxStanzaHosts {
+		m = append(m, fmt.Sprintf("Stanza %q has %v Host and Domain directives, more than %v. Consider consolidating them into Domain directives, "+
+			"or check if the vendor provides a consolidated stanza (L5004)", cmp.Or(l.State.Title, l.State.URL), len(l.State.HostLines), l.MaxStanzaHosts))
+	}
+	return m
+}
+
+// ProcessURL processes the line containing a URL directive.
+// OCLC documention:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_1
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_2
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_3
+func (l *Linter) ProcessURL(line, at string) (m []string) {
+	defer l.Profile.Time(ProfileURLs)()
+	allowedPreviousDirectives := []Directive{
+		AllowVars,
+		Charset,
+		Description,
+		EBLSecret,
+		EbrarySite,
+		EncryptVar,
+		HTTPHeader,
+		Location,
+		MimeFilter,
+		Title,
+	}
+	if !slices.Contains(allowedPreviousDirectives, l.State.Previous) {
+		m = append(m, fmt.Sprintf("\"URL\" directive is out of order, previous directive: %q (L1002)", l.State.Previous))
+	}
+
+	if l.State.Title == "" {
+		m = append(m, "\"URL\" directive is before \"Title\" directive (L1010)")
+	}
+	if l.State.URL != "" {
+		m = append(m, "Duplicate \"URL\" directive in stanza (L2003)")
+	}
+
+	urlDirective, err := ParseURLDirective(line)
+	if err != nil {
+		m = append(m, fmt.Sprintf("\"URL\" directive is not in the right format: %v (L3009)", err))
+		return m
+	}
+	l.State.URL = urlDirective.URL
+	l.State.URLForm = urlDirective.Form != ""
+	parsedURL, err := url.Parse(l.State.URL)
+	if err != nil {
+		m = append(m, fmt.Sprintf("Unable to parse URL, might be malformed: %v (L3005)", err))
+		return
+	}
+	if parsedURL.Host == "" {
+		m = append(m, "URL does not start with http or https (L3006)")
+		return
+	}
+	if l.HTTPS && parsedURL.Scheme != "https" {
+		m = append(m, "URL is not using HTTPS scheme (L3007)")
+	}
+	m = append(m, PortChecks(parsedURL)...)
+	m = append(m, StartingPointURLChecks(parsedURL)...)
+	m = append(m, HostnameChecks(parsedURL.Hostname())...)
+	if l.Pedantic {
+		m = append(m, l.NormalizedURLCheck(l.State.URL, true, at)...)
+	}
+	// According to the EZproxy docs at
+	// https://help.oclc.org/Library_Management/EZproxy/EZproxy_configuration/Starting_point_URLs_and_config_txt,
+	// URL, Host, and HostJavaScript directives are checked for starting point URLs.
+	// So many stanzas duplicate the URL in an HJ or H line that adding the URL's
+	// origin to PreviousOrigins would add a lot of noise to the output.
+	// Instead, we add the URL's origin and the filename/line combination (the 'at')
+	// to the Linter's State so that we can add it to PreviousOrigins when we're done
+	// processing the stanza.
+	l.State.URLOrigin = NormalizeOrigin(parsedURL)
+	l.State.URLAt = at
+	originSeenAt, originSeen := l.PreviousOrigins.Seen(l.SeenGroup(), l.originKey(l.State.URLOrigin))
+	if originSeen && !l.Config.SharedOrigin(l.State.URLOrigin) {
+		m = append(m, fmt.Sprintf("Origin already seen at %q (L2002)", originSeenAt))
+		m = append(m, l.IncludedDuplicateCheck("URL origin", l.State.URLOrigin, originSeenAt, at)...)
+	}
+	return m
+}
+
+// IncludedDuplicateCheck reports a stanza title or URL origin which was already seen, when one of the
+// locations is in a file given on the command line, like config.txt, and the other is in a file read with IncludeFile.
+// This is a common leftover from moving stanzas out of config.txt into their own files.
+func (l *Linter) IncludedDuplicateCheck(kind, value, seenAt, at string) (m []string) {
+	seenFile, _ := SplitAt(seenAt)
+	file, _ := SplitAt(at)
+	if l.TopLevelFiles[seenFile] == l.TopLevelFiles[file] {
+		return m
+	}
+	configAt, includedAt := seenAt, at
+	if l.TopLevelFiles[file] {
+		configAt, includedAt = at, seenAt
+	}
+	m = append(m, fmt.Sprintf("Stanza %v %q is at %q and in the included file at %q, one of the stanzas might be left over "+
+		"from moving stanzas out of the config file (L2013)", kind, value, configAt, includedAt))
+	return m
+}
+
+// ProcessFileReference processes the line containing a directive which references a local file.
+// Relative paths are resolved from the EZproxy directory, which is the IncludeFileDirectory.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/MessagesFile
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LoginMenu
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/AutoLoginIPBanner
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/ExcludeIPBanner
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/ShibbolethMetadata
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LogFile
+func (l *Linter) ProcessFileReference(line string) (m []string) {
+	defer l.Profile.Time(ProfileFiles)()
+	args := strings.Fields(TrimLabel(line, l.State.Label))
+	if len(args) == 0 {
+		return m
+	}
+	path := ""
+	switch l.State.Current {
+	case ShibbolethMetadata:
+		for _, arg := range args {
+			if value, found := CutPrefixFold(arg, "-File="); found {
+				path = value
+			}
+		}
+	case LogFile:
+		// EZproxy creates the log file, so only the directory needs to exist.
+		// The path is the last argument, after any qualifiers like -strftime.
+		path = filepath.Dir(args[len(args)-1])
+	default:
+		path = args[len(args)-1]
+	}
+	// Skip paths which are URLs or which use strftime patterns,
+	// and paths in files read from URLs, which can't be checked.
+	if path == "" || strings.Contains(path, "://") || strings.Contains(path, "%") || IsURL(l.IncludeFileDirectory) {
+		return m
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.IncludeFileDirectory, path)
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		m = append(m, fmt.Sprintf("%q directive references %q, which does not exist (L9004)", l.State.Current, path))
+	}
+	return m
+}
+
+// ProcessServerDirective checks that server directives appear before the first stanza.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/EZproxy_configuration/Starting_point_URLs_and_config_txt
+func (l *Linter) ProcessServerDirective(at string) (m []string) {
+	if l.FirstStanzaAt != "" && slices.Contains(ServerDirectives(), l.State.Current) {
+		m = append(m, fmt.Sprintf("%q is a server directive, but appears after the first stanza at %q (L1014)", l.State.Current, l.FirstStanzaAt))
+	}
+	return m
+}
+
+// ServerConfigChecks reports on essential server directives which are missing from the config.
+func (l *Linter) ServerConfigChecks() (m []string) {
+	if l.Name == "" {
+		m = append(m, "Config is missing the essential \"Name\" directive (L4007)")
+	}
+	if len(l.LoginPorts) == 0 {
+		m = append(m, "Config is missing the essential \"LoginPort\" or \"LoginPortSSL\" directive (L4007)")
+	}
+	m = append(m, l.RunAsCheck()...)
+	m = append(m, l.BannerChecks()...)
+	return m
+}
+
+// RunAsCheck reports on a config which listens on a privileged port, below 1024, without a RunAs directive.
+// On Unix systems, EZproxy has to be started as root to listen on these ports, and keeps running as root
+// unless RunAs tells it which user to switch to.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/RunAs
+func (l *Linter) RunAsCheck() (m []string) {
+	if l.RunAsAt != "" || len(l.LoginPorts) == 0 {
+		return m
+	}
+	if port := slices.Min(slices.Collect(maps.Keys(l.LoginPorts))); port < 1024 {
+		m = append(m, fmt.Sprintf("Login port %v at %q is a privileged port, but there is no \"RunAs\" directive, "+
+			"so EZproxy keeps running as the user which started it, usually root (L4018)", port, l.LoginPorts[port]))
+	}
+	return m
+}
+
+// QualifierRegex matches a directive qualifier, like "-Hide" or "-Expires=60".
+var QualifierRegex = regexp.MustCompile(`^-[A-Za-z][A-Za-z0-9]*(=.*)?$`)
+
+// ProcessValidateAndIdentifier processes the line containing a Validate or Identifier directive.
+// Both directives apply to the database stanza they are in, and need a value after any qualifiers.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Validate
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Identifier
+func (l *Linter) ProcessValidateAndIdentifier(line string) (m []string) {
+	if l.State.Title == "" && l.State.URL == "" {
+		m = append(m, fmt.Sprintf("%q directive is not in a stanza with a \"Title\" or \"URL\" directive, so it does not apply to any database (L1019)",
+			l.State.Current))
+	}
+	m = append(m, l.QualifiedArgumentChecks(line)...)
+	return m
+}
+
+// SingleArgumentCheck reports on lines with extra arguments after the directive's one argument.
+func (l *Linter) SingleArgumentCheck(line string) (m []string) {
+	if args := strings.Fields(TrimLabel(line, l.State.Label)); len(args) > 1 {
+		m = append(m, fmt.Sprintf("%q directive should have one argument, but has %v arguments, it might have been accidentally joined with the next line (L3024)",
+			l.State.Current, len(args)))
+	}
+	return m
+}
+
+// QualifiedArgumentChecks checks the argument of a directive which has optional qualifiers, followed by a value.
+func (l *Linter) QualifiedArgumentChecks(line string) (m []string) {
+	value := false
+	for _, arg := range strings.Fields(TrimLabel(line, l.State.Label)) {
+		if !strings.HasPrefix(arg, "-") || value {
+			value = true
+			continue
+		}
+		if !QualifierRegex.MatchString(arg) {
+			m = append(m, fmt.Sprintf("%q directive has a malformed qualifier %q, qualifiers look like \"-Name\" or \"-Name=value\" (L3021)",
+				l.State.Current, arg))
 		}
 	}
-	return ""
+	if !value {
+		m = append(m, fmt.Sprintf("%q directive is missing its value (L3021)", l.State.Current))
+	}
+	return m
+}
+
+// ProcessByteServeAndPDFRefresh processes the line containing a ByteServe, PDFRefresh, PDFRefreshPre, or PDFRefreshPost directive.
+// ByteServe needs a hostname, and PDFRefresh needs a URL, after any qualifiers. PDFRefreshPre and PDFRefreshPost need a value.
+// The hosts referenced by ByteServe and PDFRefresh are checked against the stanza's hosts at the end of the stanza.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/ByteServe
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/PDFRefresh
+func (l *Linter) ProcessByteServeAndPDFRefresh(line, at string) (m []string) {
+	if l.State.Title == "" && l.State.URL == "" {
+		m = append(m, fmt.Sprintf("%q directive is not in a stanza with a \"Title\" or \"URL\" directive, so it does not apply to any database (L1019)",
+			l.State.Current))
+	}
+	m = append(m, l.QualifiedArgumentChecks(line)...)
+	fields := strings.Fields(TrimLabel(line, l.State.Label))
+	if len(fields) == 0 || strings.HasPrefix(fields[len(fields)-1], "-") {
+		return m
+	}
+	value := fields[len(fields)-1]
+	var host string
+	switch l.State.Current {
+	case ByteServe:
+		host = value
+		if h, port, found := strings.Cut(value, ":"); found {
+			if _, err := strconv.Atoi(port); err == nil {
+				host = h
+			}
+		}
+		if !IsHostname(host) {
+			m = append(m, fmt.Sprintf("\"ByteServe\" directive should have a hostname, found %q (L3022)", value))
+			return m
+		}
+	case PDFRefresh:
+		parsedURL, err := url.Parse(value)
+		if err != nil || parsedURL.Hostname() == "" {
+			m = append(m, fmt.Sprintf("\"PDFRefresh\" directive should have a URL with a hostname, found %q (L3022)", value))
+			return m
+		}
+		host = parsedURL.Hostname()
+		if strings.Contains(host, "*") {
+			return m
+		}
+	default:
+		return m
+	}
+	l.State.HostReferences = append(l.State.HostReferences, HostLine{Directive: l.State.Current, Host: strings.ToLower(host), At: at})
+	return m
+}
+
+// HostReferenceChecks reports on ByteServe and PDFRefresh directives which reference a host that is not
+// the stanza's URL host, and is not covered by the stanza's Host, HostJavaScript, Domain, or DomainJavaScript directives.
+// EZproxy only applies these directives to hosts it proxies for the stanza.
+func (l *Linter) HostReferenceChecks() (m []string) {
+	urlHost := ""
+	if u, err := url.Parse(l.State.URLOrigin); err == nil {
+		urlHost = strings.ToLower(u.Hostname())
+	}
+	for _, r := range l.State.HostReferences {
+		covered := r.Host == urlHost
+		for _, h := range l.State.HostLines {
+			if covered {
+				break
+			}
+			switch h.Directive {
+			case Host, HostJavaScript:
+				covered = r.Host == h.Host
+			case Domain, DomainJavaScript:
+				covered = r.CoveredBy(HostLine{Directive: DomainJavaScript, Host: h.Host})
+			}
+		}
+		if !covered {
+			m = append(m, fmt.Sprintf("%q directive at %q references %q, which is not covered by the stanza's URL, Host, or Domain directives (L4015)",
+				r.Directive, r.At, r.Host))
+		}
+	}
+	return m
+}
+
+// ProcessForm processes the line containing a FormSelect, FormVariable, or FormSubmit directive.
+// These directives describe the form submitted by a "URL -Form" directive, so they should directly follow it,
+// with any FormSelect directives first, then the FormVariable directives, then any FormSubmit directives.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_3
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/FormSelect
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/FormSubmit
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/FormVariable
+func (l *Linter) ProcessForm() (m []string) {
+	order := map[Directive]int{FormSelect: 0, FormVariable: 1, FormSubmit: 2}
+	switch {
+	case !l.State.URLForm:
+		m = append(m, fmt.Sprintf("%q directive is not after a \"URL -Form\" directive, so it is not part of a form (L1021)", l.State.Current))
+	case l.State.Previous != URL && !slices.Contains([]Directive{FormSelect, FormVariable, FormSubmit}, l.State.Previous):
+		m = append(m, fmt.Sprintf("%q directive should directly follow the \"URL -Form\" directive or other form directives, previous directive: %q (L1021)",
+			l.State.Current, l.State.Previous))
+	case l.State.LastForm != Undefined && order[l.State.Current] < order[l.State.LastForm]:
+		m = append(m, fmt.Sprintf("%q directive should be before %q directives (L1021)", l.State.Current, l.State.LastForm))
+	}
+	if l.State.LastForm == Undefined || order[l.State.Current] > order[l.State.LastForm] {
+		l.State.LastForm = l.State.Current
+	}
+	return m
+}
+
+// ProcessDbVar processes the line containing a DbVar0 through DbVar9 directive.
+// Each database variable should only be set once in a block of lines, a later line replaces the earlier value.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/DbVar0_DbVar9
+func (l *Linter) ProcessDbVar(line, at string) (m []string) {
+	if l.State.DbVarsAt == nil {
+		l.State.DbVarsAt = make(map[Directive]string)
+	}
+	if seenAt, seen := l.State.DbVarsAt[l.State.Current]; seen {
+		m = append(m, fmt.Sprintf("%q directive is already set at %q, the earlier value is replaced (L2010)", l.State.Current, seenAt))
+	} else {
+		l.State.DbVarsAt[l.State.Current] = at
+	}
+	m = append(m, l.QualifiedArgumentChecks(line)...)
+	return m
+}
+
+// ProcessEncryptVar processes the line containing an EncryptVar directive, which needs a variable name and a key.
+// Only variables allowed by a preceding AllowVars directive in the stanza can be passed to the database, so the
+// variable should be listed in one.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/EncryptVar
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/AllowVars
+func (l *Linter) ProcessEncryptVar(line string) (m []string) {
+	args := strings.Fields(TrimLabel(line, l.State.Label))
+	if len(args) != 2 {
+		m = append(m, fmt.Sprintf("\"EncryptVar\" directive should have a variable name and a key, found %v arguments (L3021)", len(args)))
+		return m
+	}
+	if !slices.Contains(l.State.AllowedVars, args[0]) {
+		m = append(m, fmt.Sprintf("\"EncryptVar\" variable %q is not listed in a preceding \"AllowVars\" directive in the stanza (L4016)", args[0]))
+	}
+	return m
+}
+
+// ProcessMetaFind processes the line containing a MetaFind directive, which needs a pattern to find.
+// MetaFind only has an effect in stanzas where "Option MetaEZproxyRewriting" is enabled.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/MetaFind
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Option_MetaEZproxyRewriting
+func (l *Linter) ProcessMetaFind(line string) (m []string) {
+	if slices.Contains(l.State.ClosedOptions, OptionNoMetaEZproxyRewriting) && !slices.Contains(l.State.OpenOptions, OptionMetaEZproxyRewriting) {
+		m = append(m, fmt.Sprintf("\"MetaFind\" directive is after %q, so it is outside the region where meta tag rewriting is enabled (L4014)", OptionNoMetaEZproxyRewriting))
+	} else if !slices.Contains(l.State.OpenOptions, OptionMetaEZproxyRewriting) {
+		m = append(m, fmt.Sprintf("\"MetaFind\" directive has no effect without a preceding %q directive (L4014)", OptionMetaEZproxyRewriting))
+	}
+	m = append(m, l.QualifiedArgumentChecks(line)...)
+	return m
+}
+
+// ProcessLocationAndCharset processes the line containing a Location or Charset directive.
+// They change how EZproxy handles the URL of the stanza, so they should be before the stanza's URL directive.
+// After the URL, they have no effect on the stanza.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Location
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Charset
+func (l *Linter) ProcessLocationAndCharset() (m []string) {
+	if l.State.URL != "" {
+		m = append(m, fmt.Sprintf("%q directive is after the stanza's \"URL\" directive, so it has no effect on the stanza (L1020)", l.State.Current))
+	}
+	return m
+}
+
+// BannerPairs returns the banner directives, and the directives with the IP ranges the banners are shown to.
+func BannerPairs() map[Directive]Directive {
+	return map[Directive]Directive{
+		AutoLoginIPBanner: AutoLoginIP,
+		ExcludeIPBanner:   ExcludeIP,
+	}
+}
+
+// ProcessBanner processes the line containing a banner directive, which should have one file or URL.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/AutoLoginIPBanner
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/ExcludeIPBanner
+func (l *Linter) ProcessBanner(line, at string) (m []string) {
+	if _, seen := l.BannerDirectivesAt[l.State.Current]; !seen {
+		l.BannerDirectivesAt[l.State.Current] = at
+	}
+	args := strings.Fields(TrimLabel(line, l.State.Label))
+	switch {
+	case len(args) == 0:
+		m = append(m, fmt.Sprintf("%q directive is missing the file or URL of the banner (L3020)", l.State.Current))
+	case len(args) > 1:
+		m = append(m, fmt.Sprintf("%q directive should have one file or URL, but has %v arguments (L3020)", l.State.Current, len(args)))
+	case strings.Contains(args[0], "://"):
+		u, err := url.Parse(args[0])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			m = append(m, fmt.Sprintf("%q directive's URL %q is not a valid http or https URL (L3020)", l.State.Current, args[0]))
+		}
+	}
+	return m
+}
+
+// BannerChecks reports on banner directives without the IP ranges they are shown to, and on IP ranges
+// without a banner if the banner is required by the RequireBanners setting.
+// These checks need the whole config, so they are only run in server config mode.
+func (l *Linter) BannerChecks() (m []string) {
+	for _, banner := range slices.Sorted(maps.Keys(BannerPairs())) {
+		ranges := BannerPairs()[banner]
+		bannerAt, bannerSeen := l.BannerDirectivesAt[banner]
+		rangesAt, rangesSeen := l.BannerDirectivesAt[ranges]
+		if bannerSeen && !rangesSeen {
+			m = append(m, fmt.Sprintf("%q directive at %q has no effect, because there are no %q ranges (L4012)", banner, bannerAt, ranges))
+		}
+		if rangesSeen && !bannerSeen && slices.Contains(l.Config.RequireBanners, banner.String()) {
+			m = append(m, fmt.Sprintf("%q ranges are used at %q, but there is no %q directive (L4013)", ranges, rangesAt, banner))
+		}
+	}
+	return m
+}
+
+// ProcessProxyByHostname processes the line containing the Option ProxyByHostname directive.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Option_ProxyByHostname
+func (l *Linter) ProcessProxyByHostname() (m []string) {
+	if l.FirstStanzaAt != "" {
+		m = append(m, fmt.Sprintf("\"Option ProxyByHostname\" appears after the first stanza at %q, "+
+			"stanzas before this line are proxied by port (L1015)", l.FirstStanzaAt))
+	}
+	if !l.ProxyByHostname && l.HostnameDirectiveAt != "" {
+		m = append(m, fmt.Sprintf("\"Option ProxyByHostname\" appears after the %q directive at %q, "+
+			"which has no effect without a preceding \"Option ProxyByHostname\" directive (L4008)", l.HostnameDirective, l.HostnameDirectiveAt))
+	}
+	l.ProxyByHostname = true
+	return m
+}
+
+// ProcessRequiresProxyByHostname processes lines containing directives which only have an effect
+// when EZproxy is proxying by hostname. Files read without the -server-config option might be included
+// in a config.txt which has the Option ProxyByHostname directive, so the directive is only reported right away
+// in server config mode. Otherwise, the first one is reported if Option ProxyByHostname appears after it.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/ProxyHostnameEdit
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Option_HttpsHyphens_Option_NoHttpsHyphens
+func (l *Linter) ProcessRequiresProxyByHostname(at string) (m []string) {
+	if l.ProxyByHostname {
+		return m
+	}
+	if l.ServerConfig {
+		m = append(m, fmt.Sprintf("%q directive has no effect without a preceding \"Option ProxyByHostname\" directive (L4008)", l.State.Current))
+	} else if l.HostnameDirectiveAt == "" {
+		l.HostnameDirective, l.HostnameDirectiveAt = l.State.Current, at
+	}
+	return m
+}
+
+// DomainThreatCheck reports on the use of the option which allows Domain lines that threaten network security.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Option_I_choose_to_use_Domain_lines_that_threaten_the_security_of_my_network
+func (l *Linter) DomainThreatCheck() string {
+	return fmt.Sprintf("Security: \"%v\" is used, which enables %v Domain lines that threaten the security of the network (L9006)",
+		OptionIChooseToUseDomainLinesThatThreatenTheSecurityOfMyNetwork, l.DomainThreatCount)
+}
+
+// ProcessPeer processes the line containing an HAName, HAPeer, or LBPeer directive.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/HAName
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/HAPeer
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LBPeer
+func (l *Linter) ProcessPeer(line, at string) (m []string) {
+	value := TrimLabel(line, l.State.Label)
+	if l.State.Current == HAName {
+		if !IsHostname(value) {
+			m = append(m, "\"HAName\" directive should only specify a hostname (L3010)")
+		}
+		l.HAName = value
+		if IsHostname(value) {
+			m = append(m, l.AddNameReference(value, at)...)
+		}
+		return m
+	}
+
+	if l.State.Current == HAPeer && l.HAName == "" {
+		m = append(m, "\"HAPeer\" directive without a preceding \"HAName\" directive (L4006)")
+	}
+	parsedURL, err := url.Parse(value)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || !IsHostname(parsedURL.Hostname()) {
+		m = append(m, fmt.Sprintf("%q directive should specify a URL with an http or https scheme (L3010)", l.State.Current))
+		return m
+	}
+	if l.Name != "" && strings.EqualFold(parsedURL.Hostname(), l.Name) {
+		m = append(m, fmt.Sprintf("%q directive hostname is the same as the \"Name\" directive, which causes a proxy loop (L9005)", l.State.Current))
+	}
+	m = append(m, l.AddNameReference(parsedURL.Hostname(), at)...)
+	return m
+}
+
+// ProcessName processes the line containing the Name directive.
+// Server hostnames which appeared before the Name directive are checked against it.
+// A config should only have one Name directive, a later one replaces the earlier value.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/Name
+func (l *Linter) ProcessName(line, at string) (m []string) {
+	if l.Pedantic && l.NameAt != "" {
+		m = append(m, fmt.Sprintf("\"Name\" directive already seen at %q, the earlier value %q is replaced (L2012)", l.NameAt, l.Name))
+	}
+	l.Name, l.NameAt = TrimLabel(line, l.State.Label), at
+	for _, r := range l.NameReferences {
+		m = append(m, l.NameReferenceCheck(r)...)
+	}
+	l.NameReferences = nil
+	return m
+}
+
+// AddNameReference adds the hostname from the current server directive to the hostnames checked against the Name directive.
+// If the Name directive was already seen, the hostname is checked right away.
+func (l *Linter) AddNameReference(host, at string) (m []string) {
+	r := HostLine{Directive: l.State.Current, Host: strings.ToLower(host), At: at}
+	if l.Name != "" {
+		return l.NameReferenceCheck(r)
+	}
+	l.NameReferences = append(l.NameReferences, r)
+	return m
+}
+
+// NameReferenceCheck checks a server hostname against the Name directive.
+// The LoginCookieDomain has to contain the Name, and the other hostnames should be in the same registrable domain.
+// IP addresses, which are common in Interface directives, are not checked.
+func (l *Linter) NameReferenceCheck(r HostLine) (m []string) {
+	name := strings.ToLower(l.Name)
+	if r.Directive == LoginCookieDomain {
+		if name != r.Host && !strings.HasSuffix(name, "."+r.Host) {
+			m = append(m, fmt.Sprintf("\"LoginCookieDomain\" domain %q at %q does not contain the \"Name\" %q, so browsers will not send the login cookie (L9009)",
+				r.Host, r.At, l.Name))
+		}
+		return m
+	}
+	if _, err := netip.ParseAddr(r.Host); err == nil {
+		return m
+	}
+	nameDomain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return m
+	}
+	hostDomain, err := publicsuffix.EffectiveTLDPlusOne(r.Host)
+	if err == nil && hostDomain != nameDomain {
+		m = append(m, fmt.Sprintf("%q hostname %q at %q is not in the same domain as the \"Name\" %q (L9010)", r.Directive, r.Host, r.At, l.Name))
+	}
+	return m
+}
+
+// ServerHostnameChecks reports on the URL, Host, HostJavaScript, Domain, and DomainJavaScript directives in the stanza
+// which proxy the hostname of the EZproxy server, from the Name directive or the ServerHostname option.
+// EZproxy would send its own pages back through the proxy, which causes redirect loops.
+func (l *Linter) ServerHostnameChecks() (m []string) {
+	var servers []string
+	for _, s := range []string{l.Name, l.ServerHostname} {
+		if s = strings.ToLower(strings.TrimSuffix(s, ".")); s != "" && !slices.Contains(servers, s) {
+			servers = append(servers, s)
+		}
+	}
+	if len(servers) == 0 {
+		return m
+	}
+	lines := slices.Clone(l.State.HostLines)
+	if u, err := url.Parse(l.State.URLOrigin); err == nil && u.Hostname() != "" {
+		lines = slices.Insert(lines, 0, HostLine{Directive: URL, Host: strings.ToLower(u.Hostname()), At: l.State.URLAt})
+	}
+	for _, h := range lines {
+		for _, server := range servers {
+			proxied := h.Host == server
+			if h.Directive == Domain || h.Directive == DomainJavaScript {
+				proxied = proxied || strings.HasSuffix(server, "."+h.Host)
+			}
+			if proxied {
+				m = append(m, fmt.Sprintf("%q directive at %q proxies the EZproxy server hostname %q, which causes a proxy loop (L9011)",
+					h.Directive, h.At, server))
+			}
+		}
+	}
+	return m
+}
+
+// ProcessLoginCookie processes the line containing a LoginCookieName, LoginCookieDomain, or ExtraLoginCookie directive.
+// EZproxy sets these cookies when users log in, so mistakes in these lines break every login.
+// The login cookie domain has to contain the Name of the server, or browsers will not send the cookie back.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LoginCookieName
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LoginCookieDomain
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/ExtraLoginCookie
+func (l *Linter) ProcessLoginCookie(line, at string) (m []string) {
+	value := TrimLabel(line, l.State.Label)
+	switch l.State.Current {
+	case LoginCookieName:
+		if !IsCookieName(value) {
+			m = append(m, fmt.Sprintf("\"LoginCookieName\" directive should specify a cookie name, found %q (L3023)", value))
+		}
+	case LoginCookieDomain:
+		domain := strings.ToLower(strings.TrimPrefix(value, "."))
+		if !IsHostname(domain) {
+			m = append(m, fmt.Sprintf("\"LoginCookieDomain\" directive should specify a domain, found %q (L3023)", value))
+			return m
+		}
+		m = append(m, l.AddNameReference(domain, at)...)
+	case ExtraLoginCookie:
+		m = append(m, ExtraLoginCookieChecks(value)...)
+	}
+	return m
+}
+
+// ExtraLoginCookieChecks checks the value of an ExtraLoginCookie directive, which is a Set-Cookie header value
+// like "name=value; Domain=.example.com; Path=/".
+func ExtraLoginCookieChecks(value string) (m []string) {
+	attributes := []string{"domain", "path", "expires", "max-age", "secure", "httponly", "samesite"}
+	parts := strings.Split(value, ";")
+	name, _, found := strings.Cut(strings.TrimSpace(parts[0]), "=")
+	if !found || !IsCookieName(name) {
+		m = append(m, fmt.Sprintf("\"ExtraLoginCookie\" directive should start with a cookie like \"name=value\", found %q (L3023)", strings.TrimSpace(parts[0])))
+	}
+	for _, part := range parts[1:] {
+		attribute, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if !slices.Contains(attributes, strings.ToLower(attribute)) {
+			m = append(m, fmt.Sprintf("\"ExtraLoginCookie\" directive has an unknown cookie attribute %q (L3023)", strings.TrimSpace(part)))
+		}
+	}
+	return m
+}
+
+// IsCookieName reports whether s is a valid cookie name, which is an RFC 6265 token.
+func IsCookieName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProcessLoginPort processes the line containing a LoginPort or LoginPortSSL directive.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LoginPort
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/LoginPortSSL
+func (l *Linter) ProcessLoginPort(line, at string) (m []string) {
+	args := strings.Fields(TrimLabel(line, l.State.Label))
+	if len(args) == 0 {
+		m = append(m, fmt.Sprintf("%q directive does not specify a valid port (L3011)", l.State.Current))
+		return m
+	}
+	// The port is the last argument, after any qualifiers,
+	// and might be prefixed with an IP address.
+	value := args[len(args)-1]
+	if i := strings.LastIndex(value, ":"); i != -1 {
+		value = value[i+1:]
+	}
+	low, high, ok := ParsePortRange(value)
+	if !ok || low != high {
+		m = append(m, fmt.Sprintf("%q directive does not specify a valid port (L3011)", l.State.Current))
+		return m
+	}
+	// SkipPort directives before the login port are checked here, and the ones after it are checked by ProcessSkipPort.
+	for _, skip := range slices.Sorted(maps.Keys(l.SkipPorts)) {
+		if skipLow, skipHigh, _ := ParsePortRange(skip); low >= skipLow && low <= skipHigh {
+			m = append(m, fmt.Sprintf("%q port %v overlaps with \"SkipPort\" value %q at %q (L2006)", l.State.Current, low, skip, l.SkipPorts[skip]))
+		}
+	}
+	l.LoginPorts[low] = at
+	return m
+}
+
+// ProcessSkipPort processes the line containing a SkipPort directive.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/SkipPort
+func (l *Linter) ProcessSkipPort(line, at string) (m []string) {
+	args := strings.Fields(TrimLabel(line, l.State.Label))
+	if len(args) == 0 {
+		m = append(m, "\"SkipPort\" directive does not specify a valid port or port range (L3011)")
+	}
+	for _, arg := range args {
+		low, high, ok := ParsePortRange(arg)
+		if !ok {
+			m = append(m, fmt.Sprintf("\"SkipPort\" value %q is not a valid port or port range (L3011)", arg))
+			continue
+		}
+		l.SkipPorts[arg] = at
+		for _, port := range slices.Sorted(maps.Keys(l.LoginPorts)) {
+			if port >= low && port <= high {
+				m = append(m, fmt.Sprintf("\"SkipPort\" value %q overlaps with login port %v at %q (L2006)", arg, port, l.LoginPorts[port]))
+			}
+		}
+	}
+	return m
+}
+
+// PortChecks reports on an explicit port in u which is not valid,
+// or which is the default port of the other scheme, like https://example.com:80.
+func PortChecks(u *url.URL) (m []string) {
+	port := u.Port()
+	if port == "" {
+		return m
+	}
+	low, _, ok := ParsePortRange(port)
+	if !ok || strings.Contains(port, "-") {
+		m = append(m, fmt.Sprintf("Port %q is not between 1 and 65535 (L3011)", port))
+		return m
+	}
+	if (u.Scheme == "https" && low == 80) || (u.Scheme == "http" && low == 443) {
+		m = append(m, fmt.Sprintf("Port %v is used with the %v scheme, the scheme might be wrong (L3013)", low, u.Scheme))
+	}
+	return m
+}
+
+// StartingPointURLChecks reports on starting point URLs which include credentials, or use an IP address
+// or localhost instead of a hostname. These usually mean test config or secrets have leaked into a shared file.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/EZproxy_configuration/Starting_point_URLs_and_config_txt
+func StartingPointURLChecks(u *url.URL) (m []string) {
+	if u.User != nil {
+		m = append(m, "URL includes credentials, which should not be stored in the config (L3014)")
+	}
+	hostname := strings.ToLower(u.Hostname())
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		m = append(m, fmt.Sprintf("URL uses %q instead of a public hostname (L3015)", hostname))
+	} else if addr, err := netip.ParseAddr(hostname); err == nil {
+		if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+			m = append(m, fmt.Sprintf("URL uses the private IP address %q instead of a public hostname (L3015)", hostname))
+		} else {
+			m = append(m, fmt.Sprintf("URL uses the IP address %q instead of a hostname (L3015)", hostname))
+		}
+	}
+	return m
+}
+
+// NormalizedURLCheck reports on a URL which is not normalized. In fix mode, the URL is replaced with its normalized form.
+func (l *Linter) NormalizedURLCheck(rawURL string, trailingSlash bool, at string) (m []string) {
+	normalized, reasons := NormalizeURL(rawURL, trailingSlash)
+	if len(reasons) > 0 {
+		m = append(m, fmt.Sprintf("URL is not normalized (%v), it should be %q (L5003)", strings.Join(reasons, ", "), normalized))
+		l.AddFix(at, Fix{Old: rawURL, New: normalized})
+	}
+	return m
+}
+
+// NormalizeURL returns the normalized form of rawURL, and the reasons rawURL was not already normalized.
+// The hostname is lowercased, a default port is removed, and percent-encoding is made consistent.
+// If trailingSlash is true, a slash is added to a URL which has no path.
+func NormalizeURL(rawURL string, trailingSlash bool) (normalized string, reasons []string) {
+	prefix := ""
+	rest := rawURL
+	scheme, afterScheme, found := strings.Cut(rawURL, "://")
+	if found {
+		prefix = scheme + "://"
+		rest = afterScheme
+	}
+	hostEnd := strings.IndexAny(rest, "/?#")
+	if hostEnd == -1 {
+		hostEnd = len(rest)
+	}
+	host, tail := rest[:hostEnd], rest[hostEnd:]
+	if i := strings.LastIndex(host, "@"); i != -1 {
+		prefix += host[:i+1]
+		host = host[i+1:]
+	}
+
+	if lower := strings.ToLower(host); lower != host {
+		reasons = append(reasons, "uppercase hostname")
+		host = lower
+	}
+	scheme = strings.ToLower(scheme)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		reasons = append(reasons, "default port")
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	if trailingSlash && (tail == "" || tail[0] == '?' || tail[0] == '#') {
+		reasons = append(reasons, "missing trailing slash")
+		tail = "/" + tail
+	}
+	if normalizedTail := NormalizePercentEncoding(tail); normalizedTail != tail {
+		reasons = append(reasons, "inconsistent percent-encoding")
+		tail = normalizedTail
+	}
+	return prefix + host + tail, reasons
+}
+
+// NormalizePercentEncoding uppercases the hex digits in percent-encoded bytes,
+// and decodes percent-encoded unreserved characters, which should not be encoded.
+func NormalizePercentEncoding(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		decoded, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := byte(decoded)
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-._~", c) != -1 {
+			b.WriteByte(c)
+		} else {
+			b.WriteString("%" + strings.ToUpper(s[i+1:i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// FindURLFromLine returns the starting point URL from a URL directive line,
+// or an empty string if the line is not in the right format.
+func FindURLFromLine(line string) string {
+	u, err := ParseURLDirective(line)
+	if err != nil {
+		return ""
+	}
+	return u.URL
 }
 
 func TrailingSpaceOrTabCheck(line string) bool {
@@ -811,15 +2614,85 @@ func TrailingSpaceOrTabCheck(line string) bool {
 	return false
 }
 
+// ParsePortRange parses a port like "2048" or a port range like "2050-2060".
+// Ports must be between 1 and 65535, and ranges must not be reversed.
+func ParsePortRange(s string) (low, high int, ok bool) {
+	lowString, highString, isRange := strings.Cut(s, "-")
+	low, err := strconv.Atoi(lowString)
+	if err != nil {
+		return 0, 0, false
+	}
+	high = low
+	if isRange {
+		high, err = strconv.Atoi(highString)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+	if low < 1 || high > 65535 || low > high {
+		return 0, 0, false
+	}
+	return low, high, true
+}
+
+// IsHostname reports whether s is a syntactically valid hostname.
+func IsHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for label := range strings.SplitSeq(s, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CutPrefixFold is like strings.CutPrefix, but the prefix is matched without regard to case.
+func CutPrefixFold(s, prefix string) (after string, found bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
 func TrimLabel(line, label string) string {
 	return strings.TrimSpace(strings.TrimPrefix(line, label))
 }
 
+// SplitLabel splits a line into its label and the argument which follows it.
+// Leading whitespace is ignored, and the label can be separated from the argument by spaces or tabs.
+func SplitLabel(line string) (label, argument string) {
+	line = strings.TrimSpace(line)
+	i := strings.IndexAny(line, " \t")
+	if i == -1 {
+		return line, ""
+	}
+	return line[:i], strings.TrimSpace(line[i+1:])
+}
+
+// LabelDirective returns the Directive for a label, like Title for "T" or "title".
+func LabelDirective(label string) (directive Directive, ok bool) {
+	directive, ok = LabelToDirective[label]
+	if !ok {
+		directive, ok = LowercaseLabelToDirective[strings.ToLower(label)]
+	}
+	return directive, ok
+}
+
+// TrimDirective returns the argument of the line if the line's label is a label for directiveToTrim,
+// including abbreviated labels and labels with the wrong case.
+// Otherwise, the line is returned with surrounding whitespace trimmed.
 func TrimDirective(line string, directiveToTrim Directive) string {
-	for label, directive := range LabelToDirective {
-		if directive == directiveToTrim {
-			line = strings.TrimPrefix(line, label+" ")
-		}
+	label, argument := SplitLabel(line)
+	if directive, ok := LabelDirective(label); ok && directive == directiveToTrim {
+		return argument
 	}
 	return strings.TrimSpace(line)
 }
@@ -834,61 +2707,47 @@ func newScanner(r io.Reader) *bufio.Scanner {
 	return scanner
 }
 
-func processSourceLine(sourceLine string) (string, string, error) {
-	oclcTitle := ""
+func (l *Linter) processSourceLine(sourceLine string) (string, []SourceStanza, error) {
+	defer l.Profile.Time(ProfileSource)()
 	splitSourceLine := strings.Split(sourceLine, " ")
 	if len(splitSourceLine) != 4 {
-		return "", "", errors.New("source line is malformed")
+		return "", nil, errors.New("source line is malformed")
 	}
 	source := splitSourceLine[3]
 	parsedSourceURL, err := url.Parse(source)
 	if err != nil {
-		return "", "", err
+		return "", nil, err
 	}
 	if parsedSourceURL.Scheme != "https" {
-		return "", "", errors.New("source line isn't using https")
+		return "", nil, errors.New("source line isn't using https")
 	}
-	if parsedSourceURL.Host != "help.oclc.org" {
-		return "", "", errors.New("source line isn't pointing to OCLC")
+	// OCLC's stanzas are always checked, other hosts are only checked if they are in the SourceHosts setting.
+	verifyTitle, allowed := l.Config.SourceHosts[strings.ToLower(parsedSourceURL.Host)]
+	if parsedSourceURL.Host == "help.oclc.org" {
+		verifyTitle, allowed = true, true
 	}
-	// Make a GET request, waiting no more than 10 second for the results.
-	ctx, cancel := context.WithTimeout(context.Background(), OCLCHTTPTimeout)
-	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedSourceURL.String(), nil)
-	if err != nil {
-		return "", "", err
+	if !allowed {
+		return "", nil, errors.New("source line isn't pointing to OCLC or a host in the SourceHosts setting")
+	}
+	if !verifyTitle {
+		return source, nil, nil
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := l.Get(parsedSourceURL.String())
 	if err != nil {
-		return "", "", err
+		return "", nil, fmt.Errorf("%w: %w", errSourceUnavailable, err)
 	}
 	defer resp.Body.Close()
 	time.Sleep(OCLCRequestDelay)
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("%w: unexpected response status %q", errSourceUnavailable, resp.Status)
+	}
 	doc, err := html.Parse(resp.Body)
 	if err != nil {
-		return "", "", err
-	}
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "pre" {
-			if n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
-				scanner := newScanner(strings.NewReader(n.FirstChild.Data))
-				for scanner.Scan() {
-					line := scanner.Text()
-					if strings.HasPrefix(line, "Title ") || strings.HasPrefix(line, "T ") {
-						oclcTitle = TrimDirective(line, Title)
-						break
-					}
-				}
-				if err := scanner.Err(); err != nil {
-					log.Printf("Error scanning OCLC stanza source: %v\n", err)
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil && oclcTitle == ""; c = c.NextSibling {
-			f(c)
-		}
+		return "", nil, fmt.Errorf("%w: %w", errSourceUnavailable, err)
+	}
+	stanzas := ParseSourceStanzas(doc)
+	if len(stanzas) == 0 {
+		return "", nil, fmt.Errorf("%w: no Title directive was found on the page, its layout might have changed", errSourceUnavailable)
 	}
-	f(doc)
-	return source, oclcTitle, nil
+	return source, stanzas, nil
 }
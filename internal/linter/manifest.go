@@ -0,0 +1,111 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// A Manifest lists config roots which are linted in one run, like the configs of a consortium's member institutions.
+type Manifest struct {
+	Roots []ManifestRoot
+}
+
+// A ManifestRoot is a config which is linted with its own options.
+// Files are the files to lint, and IncludeFileDirectory is used like the -includefile-directory option.
+// Config is the path to a -config file for the root. Options are command line options, without the leading "-",
+// which are used for the root instead of the options given on the command line, like {"pedantic": "true"}.
+// Relative paths are relative to the manifest's directory.
+type ManifestRoot struct {
+	Name                 string
+	Files                []string
+	IncludeFileDirectory string            `json:",omitempty"`
+	Config               string            `json:",omitempty"`
+	Options              map[string]string `json:",omitempty"`
+}
+
+// ManifestRootOptions returns the options which can be set for a manifest root. They are the options used to make a linter.
+// The others, like -format or -cache, apply to the whole run, so they can't be set for one root.
+func ManifestRootOptions() []string {
+	return []string{
+		"annotate", "backup-dir", "case", "collapse", "community-repo", "cross-scheme-origins", "diff", "domain-hosts", "fail-fast",
+		"fail-fast-severity", "files", "fix", "follow-includefile", "group-scoped", "highlight", "https", "implicit-boundaries",
+		"label-style", "max-description-length", "max-file-lines", "max-file-stanzas", "max-stanza-hosts", "min-category",
+		"one-stanza-per-file", "origins", "pedantic", "phe", "redundant-hosts", "retries", "server-config", "server-hostname",
+		"skeleton-stanzas", "source", "source-compare", "source-strict", "stale-days", "timeout", "user-agent", "values", "verbose", "whitespace",
+	}
+}
+
+// ReadManifest reads the manifest at manifestPath. Unknown settings are an error, to catch typos.
+func ReadManifest(manifestPath string) (m Manifest, err error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return m, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&m); err != nil {
+		return m, fmt.Errorf("unable to read manifest %v: %w", manifestPath, err)
+	}
+	if len(m.Roots) == 0 {
+		return m, fmt.Errorf("manifest %v doesn't have any Roots", manifestPath)
+	}
+	dir := filepath.Dir(manifestPath)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) || IsURL(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	var names []string
+	for i, root := range m.Roots {
+		if root.Name == "" || slices.Contains(names, root.Name) {
+			return m, fmt.Errorf("root %v in manifest %v should have a unique Name", i+1, manifestPath)
+		}
+		names = append(names, root.Name)
+		if len(root.Files) == 0 {
+			return m, fmt.Errorf("root %q in manifest %v doesn't have any Files", root.Name, manifestPath)
+		}
+		for option := range root.Options {
+			if !slices.Contains(ManifestRootOptions(), option) {
+				return m, fmt.Errorf("option %q of root %q in manifest %v can't be set for a root", option, root.Name, manifestPath)
+			}
+		}
+		for j, file := range root.Files {
+			m.Roots[i].Files[j] = resolve(file)
+		}
+		m.Roots[i].IncludeFileDirectory = resolve(root.IncludeFileDirectory)
+		m.Roots[i].Config = resolve(root.Config)
+	}
+	return m, nil
+}
+
+// A RootReport is the report for one root of a manifest.
+type RootReport struct {
+	Name string
+	Report
+}
+
+// A ManifestReport holds the reports for the roots of a manifest, for the structured output formats.
+type ManifestReport struct {
+	Roots []RootReport
+}
+
+// SARIF returns the reports as a SARIF log, with a run for each root.
+// The automation details of each run have the root's name, so code scanning tools can tell the roots apart.
+func (r ManifestReport) SARIF() SARIFLog {
+	log := Report{}.SARIF()
+	log.Runs = nil
+	for _, root := range r.Roots {
+		run := root.Report.SARIF().Runs[0]
+		run.AutomationDetails = &SARIFAutomationDetails{ID: root.Name + "/"}
+		log.Runs = append(log.Runs, run)
+	}
+	return log
+}
@@ -0,0 +1,82 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	var tests = []struct {
+		content string
+		valid   bool
+	}{
+		{`{"Roots": [{"Name": "main", "Files": ["config.txt"], "Options": {"pedantic": "true"}}]}`, true},
+		{`{"Roots": []}`, false},
+		{`{"Roots": [{"Name": "main"}]}`, false},
+		{`{"Roots": [{"Files": ["config.txt"]}]}`, false},
+		{`{"Roots": [{"Name": "main", "Files": ["a.txt"]}, {"Name": "main", "Files": ["b.txt"]}]}`, false},
+		{`{"Roots": [{"Name": "main", "Files": ["config.txt"], "Options": {"format": "json"}}]}`, false},
+		{`{"Roots": [{"Name": "main", "Files": ["config.txt"], "Options": {"progress": "false"}}]}`, false},
+		{`{"Roots": [{"Name": "main", "Files": ["config.txt"], "Options": {"pedantc": "true"}}]}`, false},
+		{`{"Roots": [{"Name": "main", "File": ["config.txt"]}]}`, false},
+		{`not json`, false},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "manifest.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := ReadManifest(path)
+		if (err == nil) != tt.valid {
+			t.Fatalf("test %v: unexpected error value %v", i, err)
+		}
+	}
+}
+
+func TestReadManifestPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.json")
+	content := `{"Roots": [{"Name": "main", "Files": ["main/config.txt", "/etc/ezproxy/config.txt"], "IncludeFileDirectory": "main", "Config": "lint.json"}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ManifestRoot{
+		Name:                 "main",
+		Files:                []string{filepath.Join(dir, "main/config.txt"), "/etc/ezproxy/config.txt"},
+		IncludeFileDirectory: filepath.Join(dir, "main"),
+		Config:               filepath.Join(dir, "lint.json"),
+	}
+	if !reflect.DeepEqual(m.Roots[0], expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, m.Roots[0])
+	}
+}
+
+func TestManifestReportSARIF(t *testing.T) {
+	report := ManifestReport{Roots: []RootReport{
+		{Name: "main", Report: Report{Findings: []Finding{{File: "config.txt", Line: 2, Code: "L1001", Severity: SeverityWarning, Message: "Unknown directive (L1001)"}}}},
+		{Name: "branch"},
+	}}
+	log := report.SARIF()
+	if len(log.Runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %v", len(log.Runs))
+	}
+	for i, name := range []string{"main/", "branch/"} {
+		if log.Runs[i].AutomationDetails == nil || log.Runs[i].AutomationDetails.ID != name {
+			t.Fatalf("Expected run %v to have the ID %q, got %+v", i, name, log.Runs[i].AutomationDetails)
+		}
+	}
+	if len(log.Runs[0].Results) != 1 || len(log.Runs[1].Results) != 0 {
+		t.Fatalf("Unexpected results %+v", log.Runs)
+	}
+}
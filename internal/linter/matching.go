@@ -0,0 +1,147 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"net/url"
+	"strings"
+)
+
+// An AffectedLine is a line in a stanza which matches a hostname or URL, found by Affects and MatchURL.
+type AffectedLine struct {
+	File      string
+	Line      int
+	Title     string
+	Directive Directive
+	Text      string
+}
+
+// A HostPattern is the part of a URL, Host, HostJavaScript, Domain, or DomainJavaScript line which EZproxy
+// compares to the hosts of URLs. Domain patterns match the domain and its subdomains with any scheme and port,
+// the other patterns match one host, and the scheme and port if they are given.
+type HostPattern struct {
+	Scheme string
+	Host   string
+	Port   string
+	Domain bool
+}
+
+// ParseHostPattern returns the host pattern of a line, if the line is a URL, Host, HostJavaScript, Domain, or DomainJavaScript line.
+// Host lines without a scheme use http, like EZproxy.
+func ParseHostPattern(line ResolvedLine) (p HostPattern, ok bool) {
+	if !line.Known {
+		return p, false
+	}
+	var rawURL string
+	switch line.Directive {
+	case URL:
+		u, err := ParseURLDirective(line.Line)
+		if err != nil {
+			return p, false
+		}
+		rawURL = u.URL
+	case Host, HostJavaScript:
+		rawURL = TrimDirective(line.Line, line.Directive)
+		if !strings.Contains(rawURL, "://") {
+			rawURL = "http://" + rawURL
+		}
+	case Domain, DomainJavaScript:
+		domain := strings.ToLower(strings.TrimPrefix(TrimDirective(line.Line, line.Directive), "."))
+		return HostPattern{Host: domain, Domain: true}, domain != ""
+	default:
+		return p, false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return p, false
+	}
+	return HostPattern{Scheme: strings.ToLower(parsed.Scheme), Host: strings.ToLower(parsed.Hostname()), Port: parsed.Port()}, true
+}
+
+// MatchesHost reports whether the pattern matches the hostname, with any scheme and port.
+func (p HostPattern) MatchesHost(hostname string) bool {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if p.Domain {
+		return hostname == p.Host || strings.HasSuffix(hostname, "."+p.Host)
+	}
+	return hostname == p.Host
+}
+
+// DefaultPort returns the port used by a scheme when a URL doesn't have one.
+func DefaultPort(scheme string) string {
+	if strings.EqualFold(scheme, "https") {
+		return "443"
+	}
+	return "80"
+}
+
+// MatchesURL reports whether the pattern matches the URL. Domain patterns match any scheme and port,
+// the other patterns need the same scheme and port, with the scheme's default port if there isn't one.
+func (p HostPattern) MatchesURL(u *url.URL) bool {
+	if !p.MatchesHost(u.Hostname()) {
+		return false
+	}
+	if p.Domain {
+		return true
+	}
+	return p.Scheme == strings.ToLower(u.Scheme) && cmp.Or(p.Port, DefaultPort(p.Scheme)) == cmp.Or(u.Port(), DefaultPort(u.Scheme))
+}
+
+// MatchURL walks the stanzas in config order, like EZproxy, and returns the first line whose host pattern matches the URL.
+// If a NeverProxy line matches the URL's host, EZproxy doesn't proxy the URL even if a stanza matches,
+// so the NeverProxy line is returned instead, and neverProxy is true.
+func MatchURL(stanzas [][]ResolvedLine, u *url.URL) (match AffectedLine, neverProxy, found bool) {
+	for _, stanza := range stanzas {
+		for _, line := range stanza {
+			if !line.Known || line.Directive != NeverProxy {
+				continue
+			}
+			fields := strings.Fields(TrimDirective(line.Line, NeverProxy))
+			if len(fields) == 0 {
+				continue
+			}
+			if pattern, err := GlobRegexp(fields[len(fields)-1]); err == nil && pattern.MatchString(u.Hostname()) {
+				return newAffectedLine(stanza, line), true, true
+			}
+		}
+	}
+	for _, stanza := range stanzas {
+		for _, line := range stanza {
+			if p, ok := ParseHostPattern(line); ok && p.MatchesURL(u) {
+				return newAffectedLine(stanza, line), false, true
+			}
+		}
+	}
+	return match, false, false
+}
+
+// newAffectedLine returns the AffectedLine for a line in a stanza.
+func newAffectedLine(stanza []ResolvedLine, line ResolvedLine) AffectedLine {
+	file, lineNum := SplitAt(line.At)
+	return AffectedLine{File: file, Line: lineNum, Title: stanzaTitle(stanza), Directive: line.Directive, Text: line.Text}
+}
+
+// Affects returns the lines in the stanzas whose host patterns match the hostname, in config order.
+// These are the stanzas which change how EZproxy proxies the host.
+func Affects(stanzas [][]ResolvedLine, hostname string) (found []AffectedLine) {
+	for _, stanza := range stanzas {
+		for _, line := range stanza {
+			if p, ok := ParseHostPattern(line); ok && p.MatchesHost(hostname) {
+				found = append(found, newAffectedLine(stanza, line))
+			}
+		}
+	}
+	return found
+}
+
+// stanzaTitle returns the value of the stanza's Title directive, or an empty string if it doesn't have one.
+func stanzaTitle(stanza []ResolvedLine) string {
+	for _, line := range stanza {
+		if line.Known && line.Directive == Title {
+			return TrimDirective(line.Line, Title)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func resolveLines(lines ...string) (resolved []ResolvedLine) {
+	for i, line := range lines {
+		resolved = append(resolved, ResolveLine(line, "config.txt:"+strconv.Itoa(i+1)))
+	}
+	return resolved
+}
+
+func TestAffects(t *testing.T) {
+	stanzas := ResolvedStanzas(resolveLines(
+		"Title ScienceDirect",
+		"URL https://www.sciencedirect.com",
+		"D sciencedirect.com",
+		"",
+		"Title Elsevier Journals",
+		"URL https://journals.elsevier.com",
+		"HJ https://cdn.sciencedirect.com:8443",
+		"DJ .elsevier.com",
+	))
+	var tests = []struct {
+		hostname string
+		expected []AffectedLine
+	}{
+		{"www.jstor.org", nil},
+		{"notsciencedirect.com", nil},
+		{"cdn.SCIENCEDIRECT.com", []AffectedLine{
+			{File: "config.txt", Line: 3, Title: "ScienceDirect", Directive: Domain, Text: "D sciencedirect.com"},
+			{File: "config.txt", Line: 7, Title: "Elsevier Journals", Directive: HostJavaScript, Text: "HJ https://cdn.sciencedirect.com:8443"},
+		}},
+		{"journals.elsevier.com", []AffectedLine{
+			{File: "config.txt", Line: 6, Title: "Elsevier Journals", Directive: URL, Text: "URL https://journals.elsevier.com"},
+			{File: "config.txt", Line: 8, Title: "Elsevier Journals", Directive: DomainJavaScript, Text: "DJ .elsevier.com"},
+		}},
+	}
+	for _, tt := range tests {
+		if found := Affects(stanzas, tt.hostname); !reflect.DeepEqual(found, tt.expected) {
+			t.Fatalf("incorrect lines %+v instead of %+v for %q", found, tt.expected, tt.hostname)
+		}
+	}
+}
+
+func TestMatchURL(t *testing.T) {
+	stanzas := ResolvedStanzas(resolveLines(
+		"NeverProxy *.cloudfront.net",
+		"",
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"H http://www.jstor.org",
+		"",
+		"Title ScienceDirect",
+		"URL https://www.sciencedirect.com",
+		"DJ sciencedirect.com",
+		"",
+		"Title Everything Else",
+		"HJ https://www.jstor.org:8443",
+		"D jstor.org",
+	))
+	var tests = []struct {
+		rawURL     string
+		expected   AffectedLine
+		neverProxy bool
+		found      bool
+	}{
+		{"https://www.jstor.org/stable/123", AffectedLine{File: "config.txt", Line: 4, Title: "JSTOR", Directive: URL, Text: "URL https://www.jstor.org"}, false, true},
+		{"http://WWW.JSTOR.ORG:80/", AffectedLine{File: "config.txt", Line: 5, Title: "JSTOR", Directive: Host, Text: "H http://www.jstor.org"}, false, true},
+		{"https://www.jstor.org:8443/", AffectedLine{File: "config.txt", Line: 12, Title: "Everything Else", Directive: HostJavaScript, Text: "HJ https://www.jstor.org:8443"}, false, true},
+		{"https://about.jstor.org/", AffectedLine{File: "config.txt", Line: 13, Title: "Everything Else", Directive: Domain, Text: "D jstor.org"}, false, true},
+		{"http://cdn.sciencedirect.com/", AffectedLine{File: "config.txt", Line: 9, Title: "ScienceDirect", Directive: DomainJavaScript, Text: "DJ sciencedirect.com"}, false, true},
+		{"https://d1.cloudfront.net/", AffectedLine{File: "config.txt", Line: 1, Directive: NeverProxy, Text: "NeverProxy *.cloudfront.net"}, true, true},
+		{"https://www.example.com/", AffectedLine{}, false, false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		match, neverProxy, found := MatchURL(stanzas, u)
+		if match != tt.expected || neverProxy != tt.neverProxy || found != tt.found {
+			t.Fatalf("incorrect match %+v, %v, %v instead of %+v, %v, %v for %q", match, neverProxy, found, tt.expected, tt.neverProxy, tt.found, tt.rawURL)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// MessageData is the data for the templates in the Messages setting.
+// Args are the quoted values in the linter's message, like directives and hostnames, in order and without quotes,
+// so a translated message can include them, like {{index .Args 0}}.
+type MessageData struct {
+	Code    string
+	Title   string
+	Message string
+	Args    []string
+}
+
+// quotedRegex matches the values quoted with %q in a message.
+var quotedRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// parseMessageTemplates parses the Messages setting. Templates must be for a rule in the registry, so the codes stay stable.
+func parseMessageTemplates(messages map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(messages))
+	for code, text := range messages {
+		if _, ok := findRule(code); !ok {
+			return nil, fmt.Errorf("%q is not the code of a rule", code)
+		}
+		t, err := template.New(code).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("the message for %v is not a valid template: %w", code, err)
+		}
+		// Check the template with example data, so mistakes like a misspelled field are found when the config is read.
+		if err := t.Execute(&strings.Builder{}, MessageData{Code: code, Args: make([]string, 10)}); err != nil {
+			return nil, fmt.Errorf("the message for %v is not a valid template: %w", code, err)
+		}
+		templates[code] = t
+	}
+	return templates, nil
+}
+
+// findRule returns the rule in the registry with the code.
+func findRule(code string) (Rule, bool) {
+	rules := Rules()
+	i := slices.IndexFunc(rules, func(r Rule) bool { return r.Code == code })
+	if i == -1 {
+		return Rule{}, false
+	}
+	return rules[i], true
+}
+
+// CatalogMessage returns the message from the Messages setting for the message's rule, if there is one,
+// with the rule's code at the end. Otherwise, the message is returned as it is.
+func (c Config) CatalogMessage(message string) string {
+	code := MessageCode(message)
+	t, ok := c.messageTemplates[code]
+	if !ok {
+		return message
+	}
+	rule, _ := findRule(code)
+	data := MessageData{
+		Code:    code,
+		Title:   rule.Title,
+		Message: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(message), "("+code+")")),
+		Args:    []string{},
+	}
+	for _, quoted := range quotedRegex.FindAllString(data.Message, -1) {
+		if arg, err := strconv.Unquote(quoted); err == nil {
+			data.Args = append(data.Args, arg)
+		}
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		// An Args index past the end of the list only fails for some messages, so those keep the linter's message.
+		return message
+	}
+	return fmt.Sprintf("%v (%v)", strings.TrimSpace(b.String()), code)
+}
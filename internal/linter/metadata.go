@@ -0,0 +1,56 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"flag"
+	"time"
+)
+
+// Metadata describes a run of the linter, so the structured output formats are self-describing
+// and the run can be reproduced. Flags has the value of every command line flag, set or not.
+type Metadata struct {
+	Tool     string
+	Version  string
+	Flags    map[string]string
+	Config   Config
+	Files    []ProcessedFile
+	Started  time.Time
+	Finished time.Time
+	Seconds  float64
+}
+
+// A ProcessedFile is a file the linter processed, and how long it took,
+// including the time spent on the files it includes.
+type ProcessedFile struct {
+	Path    string
+	Seconds float64
+}
+
+// NewMetadata returns the metadata for a run of the linter which starts now,
+// with the values of the flags in the flag set.
+func NewMetadata(tool, version string, flags *flag.FlagSet, config Config) *Metadata {
+	m := &Metadata{Tool: tool, Version: version, Flags: map[string]string{}, Config: config, Files: []ProcessedFile{}, Started: time.Now()}
+	flags.VisitAll(func(f *flag.Flag) {
+		m.Flags[f.Name] = f.Value.String()
+	})
+	return m
+}
+
+// StartFile records that the linter has started processing the file.
+// The returned function records how long it took, and should be called when processing ends.
+func (m *Metadata) StartFile(filePath string) (done func()) {
+	i := len(m.Files)
+	m.Files = append(m.Files, ProcessedFile{Path: filePath})
+	start := time.Now()
+	return func() {
+		m.Files[i].Seconds = time.Since(start).Seconds()
+	}
+}
+
+// Finish records the end of the run.
+func (m *Metadata) Finish() {
+	m.Finished = time.Now()
+	m.Seconds = m.Finished.Sub(m.Started).Seconds()
+}
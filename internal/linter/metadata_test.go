@@ -0,0 +1,55 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	if err := os.WriteFile(path, []byte("IncludeFile databases.txt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "databases.txt"), []byte("Title JSTOR\nURL https://www.jstor.org\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Bool("pedantic", false, "")
+	if err := flags.Parse([]string{"-pedantic"}); err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	linter := Linter{FollowIncludeFile: true, Format: FormatJSON, Output: &output,
+		Metadata: NewMetadata("ezproxy-config-lint", "v1.2.3", flags, Config{})}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := linter.WriteReport(); err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(output.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%v", err, output.String())
+	}
+	m := report.Metadata
+	if m == nil {
+		t.Fatalf("report has no metadata: %v", output.String())
+	}
+	if m.Version != "v1.2.3" || m.Flags["pedantic"] != "true" {
+		t.Fatalf("incorrect metadata %+v", m)
+	}
+	if len(m.Files) != 2 || m.Files[0].Path != path || m.Files[1].Path != filepath.Join(dir, "databases.txt") {
+		t.Fatalf("incorrect processed files %+v", m.Files)
+	}
+	if m.Finished.Before(m.Started) || m.Seconds < m.Files[0].Seconds {
+		t.Fatalf("incorrect times %+v", m)
+	}
+}
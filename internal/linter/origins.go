@@ -0,0 +1,119 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NormalizeOrigin returns the origin of a URL as EZproxy compares it, with the scheme and host in lower case,
+// and without the port if it is the scheme's default port. So "http://HOST:80" and "http://host" have the same origin.
+func NormalizeOrigin(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if port := u.Port(); port != "" && port != DefaultPort(scheme) {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+// originKey returns the key an origin is stored under in PreviousOrigins.
+// With CrossSchemeOrigins, the scheme is left out, so the http and https origins of a host are duplicates.
+func (l *Linter) originKey(origin string) string {
+	if !l.CrossSchemeOrigins {
+		return origin
+	}
+	_, host, _ := strings.Cut(origin, "://")
+	return host
+}
+
+// An OriginIndexEntry maps an origin to the stanza which claims it.
+// Domain and DomainJavaScript lines claim a domain and its subdomains, so their origin is the domain.
+type OriginIndexEntry struct {
+	Origin    string
+	Directive Directive
+	Title     string
+	File      string
+	Line      int
+}
+
+// OriginIndex returns the origins claimed by the URL, Host, HostJavaScript, Domain, and DomainJavaScript lines
+// in the stanzas, sorted by origin, file, and line. It answers "why is this site being proxied?" questions.
+func OriginIndex(stanzas [][]ResolvedLine) (index []OriginIndexEntry) {
+	for _, stanza := range stanzas {
+		title := ""
+		for _, line := range stanza {
+			if line.Known && line.Directive == Title {
+				title = TrimDirective(line.Line, Title)
+				break
+			}
+		}
+		for _, line := range stanza {
+			if !line.Known {
+				continue
+			}
+			origin := ""
+			switch line.Directive {
+			case URL:
+				u, err := ParseURLDirective(line.Line)
+				if err != nil {
+					continue
+				}
+				if parsed, err := url.Parse(u.URL); err == nil && parsed.Host != "" {
+					origin = strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+				}
+			case Host, HostJavaScript:
+				host := TrimDirective(line.Line, line.Directive)
+				parsed, err := url.Parse(host)
+				if err != nil || parsed.Host == "" {
+					parsed, err = url.Parse("http://" + host)
+				}
+				if err == nil && parsed.Host != "" {
+					origin = strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+				}
+			case Domain, DomainJavaScript:
+				origin = strings.ToLower(strings.TrimPrefix(TrimDirective(line.Line, line.Directive), "."))
+			}
+			if origin == "" {
+				continue
+			}
+			file, lineNumber := SplitAt(line.At)
+			index = append(index, OriginIndexEntry{Origin: origin, Directive: line.Directive, Title: title, File: file, Line: lineNumber})
+		}
+	}
+	slices.SortStableFunc(index, func(a, b OriginIndexEntry) int {
+		return cmp.Or(cmp.Compare(a.Origin, b.Origin), cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return index
+}
+
+// WriteOriginIndex writes the index as CSV if path ends in ".csv", and as JSON otherwise.
+func WriteOriginIndex(w io.Writer, path string, index []OriginIndexEntry) error {
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(index)
+	}
+	writer := csv.NewWriter(w)
+	records := [][]string{{"Origin", "Directive", "Title", "File", "Line"}}
+	for _, e := range index {
+		records = append(records, []string{e.Origin, e.Directive.String(), e.Title, e.File, strconv.Itoa(e.Line)})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("error writing origin index: %w", err)
+	}
+	return nil
+}
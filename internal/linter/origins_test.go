@@ -0,0 +1,54 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOriginIndex(t *testing.T) {
+	var lines []ResolvedLine
+	for i, text := range []string{
+		"Title JSTOR",
+		"URL https://www.JSTOR.org/",
+		"HJ www.jstor.org",
+		"DJ .jstor.org",
+		"",
+		"T Wiley",
+		"U -Refresh wiley https://onlinelibrary.wiley.com",
+		"H https://www.jstor.org:8443",
+	} {
+		lines = append(lines, ResolveLine(text, fmt.Sprintf("a.txt:%v", i+1)))
+	}
+	expected := []OriginIndexEntry{
+		{Origin: "http://www.jstor.org", Directive: HostJavaScript, Title: "JSTOR", File: "a.txt", Line: 3},
+		{Origin: "https://onlinelibrary.wiley.com", Directive: URL, Title: "Wiley", File: "a.txt", Line: 7},
+		{Origin: "https://www.jstor.org", Directive: URL, Title: "JSTOR", File: "a.txt", Line: 2},
+		{Origin: "https://www.jstor.org:8443", Directive: Host, Title: "Wiley", File: "a.txt", Line: 8},
+		{Origin: "jstor.org", Directive: DomainJavaScript, Title: "JSTOR", File: "a.txt", Line: 4},
+	}
+	index := OriginIndex(ResolvedStanzas(lines))
+	if !reflect.DeepEqual(index, expected) {
+		t.Fatalf("incorrect index %+v", index)
+	}
+
+	var csv strings.Builder
+	if err := WriteOriginIndex(&csv, "index.csv", index[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Origin,Directive,Title,File,Line\nhttp://www.jstor.org,HostJavaScript,JSTOR,a.txt,3\n"; csv.String() != expected {
+		t.Fatalf("incorrect CSV %q instead of %q", csv.String(), expected)
+	}
+	var json strings.Builder
+	if err := WriteOriginIndex(&json, "index.json", index[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(json.String(), `"Directive": "HostJavaScript"`) {
+		t.Fatalf("incorrect JSON %q", json.String())
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// The sections of the linter which are timed when profiling.
+const (
+	ProfileReading   = "Reading files"
+	ProfileLines     = "Line checks"
+	ProfileStanzas   = "Stanza checks"
+	ProfileURLs      = "URL checks"
+	ProfileHosts     = "Host and Domain checks"
+	ProfileSource    = "Source comments"
+	ProfileNetwork   = "Network requests"
+	ProfileCommunity = "Community stanzas"
+	ProfileFiles     = "File references"
+	ProfileFixes     = "Fixes"
+)
+
+// A Profile records the time spent processing each file, and in each section of the linter.
+// The time for a file includes the time spent on the files it includes.
+// The time for a section does not include the time spent in the sections it uses,
+// so the time for Source comments does not include the network requests made to OCLC.
+// The methods can be called on a nil Profile, which records nothing.
+type Profile struct {
+	Files    []ProfileEntry
+	Sections map[string]time.Duration
+	stack    []string
+	since    time.Time
+}
+
+// A ProfileEntry is the time spent on a file or section.
+type ProfileEntry struct {
+	Name     string
+	Duration time.Duration
+}
+
+// NewProfile returns an empty Profile.
+func NewProfile() *Profile {
+	return &Profile{Sections: map[string]time.Duration{}}
+}
+
+// File starts timing a file. The returned function stops timing it.
+func (p *Profile) File(filePath string) (done func()) {
+	if p == nil {
+		return func() {}
+	}
+	i := len(p.Files)
+	p.Files = append(p.Files, ProfileEntry{Name: filePath})
+	start := time.Now()
+	return func() {
+		p.Files[i].Duration = time.Since(start)
+	}
+}
+
+// Time starts timing a section, pausing the section which is being timed. The returned function
+// stops timing the section, and resumes the paused section.
+func (p *Profile) Time(section string) (done func()) {
+	if p == nil {
+		return func() {}
+	}
+	p.charge()
+	p.stack = append(p.stack, section)
+	return func() {
+		p.charge()
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+}
+
+// charge adds the time since the last change to the section being timed.
+func (p *Profile) charge() {
+	now := time.Now()
+	if len(p.stack) > 0 {
+		p.Sections[p.stack[len(p.stack)-1]] += now.Sub(p.since)
+	}
+	p.since = now
+}
+
+// Write prints the time spent on each file and section, slowest first.
+func (p *Profile) Write(w io.Writer) {
+	if p == nil {
+		return
+	}
+	sections := []ProfileEntry{}
+	for name, duration := range p.Sections {
+		sections = append(sections, ProfileEntry{Name: name, Duration: duration})
+	}
+	for _, group := range []struct {
+		heading string
+		entries []ProfileEntry
+	}{{"Time per file", slices.Clone(p.Files)}, {"Time per section", sections}} {
+		slices.SortStableFunc(group.entries, func(a, b ProfileEntry) int {
+			return cmp.Or(cmp.Compare(b.Duration, a.Duration), cmp.Compare(a.Name, b.Name))
+		})
+		fmt.Fprintf(w, "%v:\n", group.heading)
+		for _, entry := range group.entries {
+			fmt.Fprintf(w, "  %12v  %v\n", entry.Duration.Round(time.Microsecond), entry.Name)
+		}
+	}
+}
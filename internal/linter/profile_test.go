@@ -0,0 +1,44 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	p := NewProfile()
+	doneFile := p.File("config.txt")
+	doneLines := p.Time(ProfileLines)
+	doneNetwork := p.Time(ProfileNetwork)
+	time.Sleep(20 * time.Millisecond)
+	doneNetwork()
+	doneLines()
+	doneFile()
+	if p.Sections[ProfileNetwork] < 20*time.Millisecond {
+		t.Fatalf("network time %v is too short", p.Sections[ProfileNetwork])
+	}
+	if p.Sections[ProfileLines] >= 20*time.Millisecond {
+		t.Fatalf("line checks time %v includes the network time", p.Sections[ProfileLines])
+	}
+	if len(p.Files) != 1 || p.Files[0].Duration < 20*time.Millisecond {
+		t.Fatalf("incorrect file times %+v", p.Files)
+	}
+	var output bytes.Buffer
+	p.Write(&output)
+	lines := strings.Split(output.String(), "\n")
+	if len(lines) != 6 || lines[0] != "Time per file:" || lines[2] != "Time per section:" || !strings.HasSuffix(lines[3], ProfileNetwork) {
+		t.Fatalf("incorrect output %q", output.String())
+	}
+}
+
+func TestNilProfile(t *testing.T) {
+	var p *Profile
+	p.File("config.txt")()
+	p.Time(ProfileLines)()
+	p.Write(nil)
+}
@@ -0,0 +1,72 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ProgressInterval is how often the status of a long run is printed.
+const ProgressInterval = 5 * time.Second
+
+// A Progress prints the status of a long run every ProgressInterval, so users know it hasn't hung.
+// Runs which finish before the first interval print nothing.
+// The methods can be called on a nil Progress, which prints nothing.
+type Progress struct {
+	Output   io.Writer
+	Files    int
+	Lines    int
+	Requests int
+	File     string
+	started  time.Time
+	printed  time.Time
+	now      func() time.Time
+}
+
+// NewProgress returns a Progress which prints to w, starting now.
+func NewProgress(w io.Writer) *Progress {
+	started := time.Now()
+	return &Progress{Output: w, started: started, printed: started, now: time.Now}
+}
+
+// StartFile records that a file is being processed.
+func (p *Progress) StartFile(filePath string) {
+	if p == nil {
+		return
+	}
+	p.Files++
+	p.File = filePath
+	p.update()
+}
+
+// Line records that a line was processed.
+func (p *Progress) Line() {
+	if p == nil {
+		return
+	}
+	p.Lines++
+	p.update()
+}
+
+// Request records that a network request is being made.
+func (p *Progress) Request() {
+	if p == nil {
+		return
+	}
+	p.Requests++
+	p.update()
+}
+
+// update prints the status if it hasn't been printed for ProgressInterval.
+func (p *Progress) update() {
+	now := p.now()
+	if now.Sub(p.printed) < ProgressInterval {
+		return
+	}
+	p.printed = now
+	fmt.Fprintf(p.Output, "Processed %v files, %v lines, and %v network requests in %v, now processing %v\n",
+		p.Files, p.Lines, p.Requests, now.Sub(p.started).Round(time.Second), p.File)
+}
@@ -0,0 +1,40 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	var output bytes.Buffer
+	p := NewProgress(&output)
+	now := p.started
+	p.now = func() time.Time { return now }
+
+	p.StartFile("config.txt")
+	p.Line()
+	if output.Len() != 0 {
+		t.Fatalf("progress printed before the interval: %q", output.String())
+	}
+	now = now.Add(ProgressInterval)
+	p.Request()
+	expected := "Processed 1 files, 1 lines, and 1 network requests in 5s, now processing config.txt\n"
+	if output.String() != expected {
+		t.Fatalf("incorrect progress %q instead of %q", output.String(), expected)
+	}
+	p.Line()
+	if output.String() != expected {
+		t.Fatalf("progress printed again before the interval: %q", output.String())
+	}
+}
+
+func TestNilProgress(t *testing.T) {
+	var p *Progress
+	p.StartFile("config.txt")
+	p.Line()
+	p.Request()
+}
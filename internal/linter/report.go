@@ -0,0 +1,257 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The formats the linter can use for its output.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// errFailFast is returned by processFile when FailFast is set, and a finding at or above that severity was found.
+var errFailFast = errors.New("stopped at the first finding")
+
+// Formats returns the output formats the linter supports.
+func Formats() []string {
+	return []string{FormatText, FormatJSON, FormatSARIF}
+}
+
+// CodeRegex matches the code of a rule in a message, like "(L2002)".
+var CodeRegex = regexp.MustCompile(`\((L\d{4})\)`)
+
+// A Finding is an issue found by the linter, for the structured output formats.
+// Line is zero when the finding applies to the whole file.
+// Text is the content of the line, and is empty when the finding applies to a whole stanza or file.
+// Stanza is the title of the stanza the finding is in, without the -Hide qualifier, so findings can be grouped by resource.
+// It is empty when the title is not known, like for findings before the stanza's Title directive.
+// Column and EndColumn are the span of the line's content, without surrounding whitespace, counted in bytes from one.
+// EndColumn is the column after the content. They are zero when Text is empty.
+// Fix is the fix for the line, if the finding's rule is fixable and a fix was recorded,
+// and Edits are the changes to the file which apply it, for editors and other tools.
+// Documentation is the address of OCLC's documentation for the directive on the line, if it has one.
+// Fingerprint identifies the finding without its location, so it can be tracked as lines are added or removed.
+// Count and Lines are set when -collapse merges repeated findings in a file, with the number of findings and their lines.
+// Security is set for security issues, which the text format prints more prominently.
+type Finding struct {
+	File          string
+	Line          int
+	Column        int    `json:",omitempty"`
+	EndColumn     int    `json:",omitempty"`
+	Text          string `json:",omitempty"`
+	Stanza        string `json:",omitempty"`
+	Code          string
+	Category      Category `json:",omitempty"`
+	Severity      Severity
+	Message       string
+	Fix           *Fix   `json:",omitempty"`
+	Edits         []Edit `json:",omitempty"`
+	Documentation string `json:",omitempty"`
+	Fingerprint   string
+	Count         int   `json:",omitempty"`
+	Lines         []int `json:",omitempty"`
+	Security      bool  `json:"-"`
+}
+
+// A ProcessingError is an error which stopped the linter from processing a file.
+type ProcessingError struct {
+	File    string
+	Message string
+}
+
+// A Report holds the findings and errors for the structured output formats.
+// Metadata is included if the caller set the linter's Metadata.
+type Report struct {
+	Metadata *Metadata `json:",omitempty"`
+	Findings []Finding
+	Errors   []ProcessingError
+}
+
+// SplitAt splits an "at" location like "config.txt:12" into the file path and the line number.
+// If the location does not end in a line number, the line number is zero.
+func SplitAt(at string) (file string, line int) {
+	i := strings.LastIndex(at, ":")
+	if i == -1 {
+		return at, 0
+	}
+	line, err := strconv.Atoi(at[i+1:])
+	if err != nil {
+		return at, 0
+	}
+	return at[:i], line
+}
+
+// MessageCode returns the code of the rule which created a message, or an empty string if the message has no code.
+func MessageCode(message string) string {
+	if match := CodeRegex.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// MessageCategory uses the rule registry to find the category of a message.
+// Messages without a known code have no category.
+func MessageCategory(message string) Category {
+	code := MessageCode(message)
+	for _, rule := range Rules() {
+		if rule.Code == code {
+			return rule.Category
+		}
+	}
+	return ""
+}
+
+// CategoryFilter returns the messages whose category is at least the MinCategory.
+// Messages without a category are always kept.
+func (l *Linter) CategoryFilter(messages []string) []string {
+	if l.MinCategory == "" {
+		return messages
+	}
+	var kept []string
+	for _, message := range messages {
+		if category := MessageCategory(message); category == "" || category.AtLeast(l.MinCategory) {
+			kept = append(kept, message)
+		}
+	}
+	return kept
+}
+
+// MessageSeverity uses the rule registry to find the severity of a message.
+// Messages without a known code are warnings.
+func MessageSeverity(message string) Severity {
+	code := MessageCode(message)
+	for _, rule := range Rules() {
+		if rule.Code == code {
+			return rule.Severity
+		}
+	}
+	return SeverityWarning
+}
+
+// IssueCount returns the number of messages which are issues. Info messages are reported, but they aren't issues.
+func IssueCount(messages []string) (count int) {
+	for _, message := range messages {
+		if MessageSeverity(message) != SeverityInfo {
+			count++
+		}
+	}
+	return count
+}
+
+// NewFinding makes a Finding from a message. The title is the title of the stanza the finding is in, if known.
+func NewFinding(at, title, text, message string) Finding {
+	file, line := SplitAt(at)
+	column, endColumn := 0, 0
+	if content := strings.TrimSpace(text); content != "" {
+		column = len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace)) + 1
+		endColumn = column + len(content)
+	}
+	return Finding{
+		File:          file,
+		Line:          line,
+		Column:        column,
+		EndColumn:     endColumn,
+		Text:          text,
+		Stanza:        strings.TrimPrefix(title, "-Hide "),
+		Code:          MessageCode(message),
+		Category:      MessageCategory(message),
+		Severity:      MessageSeverity(message),
+		Message:       message,
+		Documentation: LineDocumentationURL(text),
+		Fingerprint:   Fingerprint(MessageCode(message), title, text, message),
+	}
+}
+
+// NewFindings makes the Findings for the messages about a line. Findings from fixable rules
+// have the fix recorded for the line, if there is one.
+func (l *Linter) NewFindings(at, title, text string, messages []string) (findings []Finding) {
+	for _, message := range messages {
+		f := NewFinding(at, title, text, message)
+		f.Message = l.Config.CatalogMessage(message)
+		if fix, ok := l.Fixes[at]; ok && RuleFixable(f.Code) && text != "" {
+			f.Fix = &fix
+			f.Edits = fix.Edits(text, f.Line)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// Messages returns the messages of the findings.
+func Messages(findings []Finding) (messages []string) {
+	for _, f := range findings {
+		messages = append(messages, f.Message)
+	}
+	return messages
+}
+
+// Fingerprint returns a stable identifier for a finding, made from the rule code, the stanza title,
+// and the content of the line with whitespace normalized. Findings which apply to a whole stanza or file
+// use the message, without any locations, instead of the line content.
+func Fingerprint(code, title, text, message string) string {
+	content := strings.Join(strings.Fields(text), " ")
+	if content == "" {
+		content = LocationRegex.ReplaceAllString(message, `"`)
+	}
+	sum := sha256.Sum256([]byte(code + "\x00" + title + "\x00" + content))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Structured reports whether the linter is using a structured output format.
+func (l *Linter) Structured() bool {
+	return l.Format == FormatJSON || l.Format == FormatSARIF
+}
+
+// FailFastCheck reports whether processing should stop because one of the messages
+// is at or above the FailFast severity.
+func (l *Linter) FailFastCheck(messages []string) bool {
+	if l.FailFast == "" {
+		return false
+	}
+	for _, message := range messages {
+		if MessageSeverity(message).AtLeast(l.FailFast) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportLine reports the findings for a line in the stanza with the title. If the line is empty,
+// the findings apply to the whole stanza or file which ended at the location.
+func (l *Linter) ReportLine(at, title, line string, messages []string) {
+	l.recordCacheReport(at, title, line, messages)
+	l.writeErr = cmp.Or(l.writeErr, l.FindingWriter().WriteFindings(l.NewFindings(at, title, line, messages)))
+}
+
+// ReportSecurity reports a finding which is a security issue.
+// In the text format, it is printed more prominently than other findings.
+func (l *Linter) ReportSecurity(at, message string) {
+	finding := NewFinding(at, "", "", message)
+	finding.Message = l.Config.CatalogMessage(message)
+	finding.Security = true
+	l.writeErr = cmp.Or(l.writeErr, l.FindingWriter().WriteFindings([]Finding{finding}))
+}
+
+// ReportError reports an error which stopped the linter from processing a file.
+func (l *Linter) ReportError(filePath string, err error) {
+	l.writeErr = cmp.Or(l.writeErr, l.FindingWriter().WriteError(ProcessingError{File: filePath, Message: err.Error()}))
+}
+
+// WriteReport closes the linter's FindingWriter, which writes the findings and errors collected for the structured output formats.
+// Nothing more is written in the text format, which is printed as files are processed.
+// The first error from the writer, if any, is returned.
+func (l *Linter) WriteReport() error {
+	return cmp.Or(l.writeErr, l.FindingWriter().Close())
+}
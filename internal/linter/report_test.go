@@ -0,0 +1,217 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitAt(t *testing.T) {
+	var tests = []struct {
+		at   string
+		file string
+		line int
+	}{
+		{"config.txt:12", "config.txt", 12},
+		{"config.txt", "config.txt", 0},
+		{`C:\ezproxy\config.txt:3`, `C:\ezproxy\config.txt`, 3},
+		{`C:\ezproxy\config.txt`, `C:\ezproxy\config.txt`, 0},
+	}
+
+	for _, tt := range tests {
+		file, line := SplitAt(tt.at)
+		if file != tt.file || line != tt.line {
+			t.Fatalf("SplitAt() fails on %q, wanted %q and %v, got %q and %v.\n", tt.at, tt.file, tt.line, file, line)
+		}
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	content := "Title JSTOR\nURL https://www.jstor.org\nFooBar baz\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	linter := Linter{Format: FormatJSON, Output: &output}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	linter.ReportError("missing.txt", errors.New("file not found"))
+	if err := linter.WriteReport(); err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(output.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%v", err, output.String())
+	}
+	expected := Report{
+		Findings: []Finding{{
+			File:        path,
+			Line:        3,
+			Column:      1,
+			EndColumn:   11,
+			Text:        "FooBar baz",
+			Stanza:      "JSTOR",
+			Code:        "L9001",
+			Category:    CategoryOther,
+			Severity:    SeverityWarning,
+			Message:     "Unknown directive \"FooBar\" (L9001)",
+			Fingerprint: Fingerprint("L9001", "JSTOR", "FooBar baz", ""),
+		}},
+		Errors: []ProcessingError{{File: "missing.txt", Message: "file not found"}},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("incorrect report %+v instead of %+v", report, expected)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	content := "Title JSTOR\nURL https://www.jstor.org\nFooBar baz\n\nTitle Wiley\nURL https://www.wiley.com\nBazFoo bar\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		failFast Severity
+		count    int
+		stopped  bool
+	}{
+		{"", 2, false},
+		{SeverityWarning, 1, true},
+		{SeverityError, 2, false},
+	}
+
+	for _, tt := range tests {
+		linter := Linter{FailFast: tt.failFast, Format: FormatJSON, Output: io.Discard}
+		count, err := linter.ProcessFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tt.count || linter.Stopped != tt.stopped {
+			t.Fatalf("FailFast %q found %v issues and stopped %v, wanted %v issues and stopped %v",
+				tt.failFast, count, linter.Stopped, tt.count, tt.stopped)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("L9001", "JSTOR", "FooBar  baz", "Unknown directive \"FooBar\" (L9001)")
+	if fingerprint != Fingerprint("L9001", "JSTOR", "FooBar baz ", "Unknown directive \"FooBar\" (L9001)") {
+		t.Fatal("fingerprints should ignore whitespace changes")
+	}
+	if fingerprint == Fingerprint("L9001", "Wiley", "FooBar baz", "Unknown directive \"FooBar\" (L9001)") {
+		t.Fatal("fingerprints should be different in different stanzas")
+	}
+	if Fingerprint("L2002", "JSTOR", "", "Origin already seen at \"config.txt:2\" (L2002)") !=
+		Fingerprint("L2002", "JSTOR", "", "Origin already seen at \"config.txt:12\" (L2002)") {
+		t.Fatal("fingerprints for stanzas should ignore locations in messages")
+	}
+}
+
+func TestFindingStanza(t *testing.T) {
+	linter := Linter{Format: FormatSARIF}
+	linter.ReportLine("config.txt:3", "-Hide JSTOR", "HJ www.jstor.org", []string{"Unknown directive \"FooBar\" (L9001)"})
+	linter.ReportLine("config.txt:9", "", "FooBar baz", []string{"Unknown directive \"FooBar\" (L9001)"})
+	if stanza := linter.Report.Findings[0].Stanza; stanza != "JSTOR" {
+		t.Fatalf("incorrect stanza %q instead of %q", stanza, "JSTOR")
+	}
+	results := linter.SARIF().Runs[0].Results
+	if results[0].Properties == nil || results[0].Properties.Stanza != "JSTOR" {
+		t.Fatalf("incorrect SARIF properties %+v for a finding in a stanza", results[0].Properties)
+	}
+	if results[1].Properties == nil || results[1].Properties.Stanza != "" || results[1].Properties.Category != CategoryOther {
+		t.Fatalf("incorrect SARIF properties %+v for a finding outside a stanza", results[1].Properties)
+	}
+}
+
+func TestSARIFFixes(t *testing.T) {
+	linter := Linter{DirectiveCase: true, Format: FormatSARIF, Output: io.Discard}
+	for i, line := range []string{"Title JSTOR", "url https://www.jstor.org"} {
+		at := fmt.Sprintf("config.txt:%v", i+1)
+		linter.ReportLine(at, "JSTOR", line, linter.CategoryFilter(Messages(linter.ProcessLineAt(line, at))))
+	}
+	results := linter.SARIF().Runs[0].Results
+	expected := []SARIFFix{{
+		Description: SARIFMessage{Text: "Apply the fix from -fix"},
+		ArtifactChanges: []SARIFArtifactChange{{
+			ArtifactLocation: SARIFArtifactLocation{URI: "config.txt"},
+			Replacements: []SARIFReplacement{{
+				DeletedRegion:   SARIFRegion{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 4},
+				InsertedContent: &SARIFArtifactContent{Text: "URL"},
+			}},
+		}},
+	}}
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Fixes, expected) {
+		t.Fatalf("incorrect SARIF results %+v, the fixes should be %+v", results, expected)
+	}
+}
+
+func TestCategoryFilter(t *testing.T) {
+	messages := []string{
+		"Duplicate line, already seen at \"test:1\" (L2009)",
+		"Line ends in a space or tab character (L5002)",
+		"Unknown directive \"FooBar\" (L9001)",
+		"A message without a code",
+	}
+	var tests = []struct {
+		min      Category
+		expected []string
+	}{
+		{"", messages},
+		{CategoryStyling, messages},
+		{CategoryDuplication, []string{messages[0], messages[2], messages[3]}},
+		{CategoryOther, []string{messages[2], messages[3]}},
+	}
+	for _, tt := range tests {
+		linter := Linter{MinCategory: tt.min}
+		if kept := linter.CategoryFilter(messages); !reflect.DeepEqual(kept, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q for %q", kept, tt.expected, tt.min)
+		}
+	}
+}
+
+func TestProcessLineAtFindings(t *testing.T) {
+	linter := Linter{RedundantHosts: true, Fix: true}
+	var findings []Finding
+	for i, line := range []string{"Title JSTOR", "URL https://www.jstor.org", "  HJ www.jstor.org  ", "DJ jstor.org", ""} {
+		findings = append(findings, linter.ProcessLineAt(line, fmt.Sprintf("config.txt:%v", i+1))...)
+	}
+	message := "\"HostJavaScript\" directive for \"www.jstor.org\" at \"config.txt:3\" is already covered by " +
+		"\"DomainJavaScript\" directive for \"jstor.org\" at \"config.txt:4\" (L2007)"
+	expected := []Finding{{
+		File:        "config.txt",
+		Line:        5,
+		Stanza:      "JSTOR",
+		Code:        "L2007",
+		Category:    CategoryDuplication,
+		Severity:    SeverityWarning,
+		Message:     message,
+		Fingerprint: Fingerprint("L2007", "JSTOR", "", message),
+	}}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Fatalf("incorrect findings %+v instead of %+v", findings, expected)
+	}
+
+	linter = Linter{}
+	findings = linter.ProcessLineAt("  FooBar baz ", "config.txt:1")
+	if len(findings) != 1 || findings[0].Column != 3 || findings[0].EndColumn != 13 || findings[0].Code != "L9001" {
+		t.Fatalf("incorrect findings %+v for an unknown directive", findings)
+	}
+
+	linter = Linter{DirectiveCase: true, Fix: true}
+	findings = linter.ProcessLineAt("TITLE Foo", "config.txt:1")
+	if len(findings) != 1 || !reflect.DeepEqual(findings[0].Fix, &Fix{Old: "TITLE", New: "Title"}) {
+		t.Fatalf("incorrect findings %+v for a misstyled directive", findings)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import "slices"
+
+// A Category groups rules by the kind of issue they report.
+// The category is the first digit of the rule's code.
+type Category string
+
+const (
+	CategoryOrdering     Category = "Ordering"     // L1
+	CategoryDuplication  Category = "Duplication"  // L2
+	CategoryMalformation Category = "Malformation" // L3
+	CategoryMissing      Category = "Missing"      // L4
+	CategoryStyling      Category = "Styling"      // L5
+	CategoryOther        Category = "Other"        // L9
+)
+
+// Categories returns the categories, from least to most important.
+// Styling issues don't change how EZproxy behaves, while Malformation issues and the Other issues,
+// like unknown directives and outdated stanzas, usually do.
+func Categories() []Category {
+	return []Category{CategoryStyling, CategoryOrdering, CategoryDuplication, CategoryMissing, CategoryMalformation, CategoryOther}
+}
+
+// AtLeast reports whether the category c is as important as the category d, or more important.
+func (c Category) AtLeast(d Category) bool {
+	return slices.Index(Categories(), c) >= slices.Index(Categories(), d)
+}
+
+// A Severity describes how serious the issues reported by a rule are.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "Info"
+	SeverityWarning Severity = "Warning"
+	SeverityError   Severity = "Error"
+)
+
+// Severities returns the severities, from least to most serious.
+// Info findings are reported, but are not counted as issues.
+func Severities() []Severity {
+	return []Severity{SeverityInfo, SeverityWarning, SeverityError}
+}
+
+// AtLeast reports whether the severity s is as serious as the severity t, or more serious.
+func (s Severity) AtLeast(t Severity) bool {
+	return slices.Index(Severities(), s) >= slices.Index(Severities(), t)
+}
+
+// A Rule describes a check performed by the linter.
+// Flag is the command line flag which enables the rule, if it is not enabled by default.
+type Rule struct {
+	Code     string
+	Title    string
+	Category Category
+	Severity Severity
+	Fixable  bool
+	Flag     string `json:",omitempty"`
+}
+
+// Rules returns the registry of rules, in code order.
+// Each rule is explained in more detail in CHECKS.md.
+func Rules() []Rule {
+	return []Rule{
+		{Code: "L1001", Title: "Title directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1002", Title: "URL directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1003", Title: "AnonymousURL -* directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1004", Title: "AnonymousURL directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1005", Title: "An Option 'opener' directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1006", Title: "An Option 'closer' directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1008", Title: "ProxyHostnameEdit directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1009", Title: "ProxyHostnameEdit domains should be placed in deepest-to-shallowest order", Category: CategoryOrdering, Severity: SeverityWarning, Flag: "-phe"},
+		{Code: "L1010", Title: "URL directive is before Title directive", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1011", Title: "AddUserHeader directive with no qualifiers is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1012", Title: "AddUserHeader directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1013", Title: "Description directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1014", Title: "Server directive appears after the first stanza", Category: CategoryOrdering, Severity: SeverityWarning, Flag: "-server-config"},
+		{Code: "L1015", Title: "Option ProxyByHostname appears after the first stanza", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1016", Title: "Stanza directive after the final stanza", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1017", Title: "IncludeFile directives are not in alphabetical order", Category: CategoryOrdering, Severity: SeverityWarning, Fixable: true, Flag: "-pedantic"},
+		{Code: "L1018", Title: "Stanzas are not in alphabetical order", Category: CategoryOrdering, Severity: SeverityWarning, Fixable: true, Flag: "-pedantic"},
+		{Code: "L1019", Title: "Directive is not in a stanza", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1020", Title: "Location or Charset directive is after URL", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L1021", Title: "Form directive is out of order", Category: CategoryOrdering, Severity: SeverityWarning},
+		{Code: "L2001", Title: "Duplicate Title directive in stanza", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2002", Title: "Origin already seen in another stanza", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2003", Title: "Duplicate URL directive in stanza", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2004", Title: "Title value already seen", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2005", Title: "Origin already seen in this stanza", Category: CategoryDuplication, Severity: SeverityWarning, Flag: "-origins"},
+		{Code: "L2006", Title: "SkipPort overlaps with LoginPort or LoginPortSSL", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2007", Title: "Host directive is already covered by a Domain directive", Category: CategoryDuplication, Severity: SeverityWarning, Fixable: true, Flag: "-redundant-hosts"},
+		{Code: "L2008", Title: "Hostname is in both a directive and its JavaScript variant", Category: CategoryDuplication, Severity: SeverityWarning, Fixable: true, Flag: "-redundant-hosts"},
+		{Code: "L2009", Title: "Duplicate line in stanza", Category: CategoryDuplication, Severity: SeverityWarning, Fixable: true},
+		{Code: "L2010", Title: "Database variable is set twice", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2011", Title: "Description value already seen", Category: CategoryDuplication, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L2012", Title: "Name directive already seen", Category: CategoryDuplication, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L2013", Title: "Stanza is in both the config file and an included file", Category: CategoryDuplication, Severity: SeverityWarning},
+		{Code: "L2014", Title: "Vendor platform is proxied by several stanzas", Category: CategoryDuplication, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L3001", Title: "ProxyHostnameEdit directive must have both a find and replace qualifier", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3002", Title: "Find part of ProxyHostnameEdit directive should end with a $", Category: CategoryMalformation, Severity: SeverityWarning, Flag: "-phe"},
+		{Code: "L3003", Title: "Replace part of ProxyHostnameEdit directive is malformed", Category: CategoryMalformation, Severity: SeverityWarning, Flag: "-phe"},
+		{Code: "L3004", Title: "Domain and DomainJavaScript directives should only specify domains", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3005", Title: "Unable to parse URL", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3006", Title: "URL does not start with http or https", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3007", Title: "URL is not using HTTPS scheme", Category: CategoryMalformation, Severity: SeverityWarning, Flag: "-https"},
+		{Code: "L3008", Title: "Option directive not in the form Option OPTIONNAME", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3009", Title: "URL directive is not in the right format", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3010", Title: "HAName, HAPeer, or LBPeer directive is malformed", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3011", Title: "Port or port range is not valid", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3012", Title: "Wildcard in Host or HostJavaScript directive", Category: CategoryMalformation, Severity: SeverityWarning, Fixable: true},
+		{Code: "L3013", Title: "Port does not match the scheme", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3014", Title: "URL includes credentials", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3015", Title: "URL uses an IP address or localhost", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3016", Title: "Hostname is not valid punycode", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3017", Title: "Hostname mixes scripts", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3018", Title: "Stanza header comment has an invalid value", Category: CategoryMalformation, Severity: SeverityWarning, Flag: "-config"},
+		{Code: "L3019", Title: "Blank line inside stanza", Category: CategoryMalformation, Severity: SeverityWarning, Fixable: true, Flag: "-pedantic"},
+		{Code: "L3020", Title: "Banner directive is malformed", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3021", Title: "Directive argument is malformed", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3022", Title: "ByteServe or PDFRefresh host is malformed", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L3023", Title: "Login cookie directive is malformed", Category: CategoryMalformation, Severity: SeverityError},
+		{Code: "L3024", Title: "Directive has extra arguments", Category: CategoryMalformation, Severity: SeverityWarning},
+		{Code: "L4001", Title: "Missing AnonymousURL -* at end of stanza", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4002", Title: "Missing Option at end of stanza", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4003", Title: "Stanza has Title but no URL", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4004", Title: "Find directive must be immediately proceeded with a Replace directive", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4005", Title: "Missing AddUserHeader at end of stanza", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4006", Title: "HAPeer directive without HAName directive", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4007", Title: "Missing essential server directive", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-server-config"},
+		{Code: "L4008", Title: "Directive requires Option ProxyByHostname", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4009", Title: "Stanza doesn't have a Source comment", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L4010", Title: "Stanza header doesn't match the template", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-config"},
+		{Code: "L4011", Title: "Stanza only has Title and URL directives", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-skeleton-stanzas"},
+		{Code: "L4012", Title: "Banner directive without IP ranges", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-server-config"},
+		{Code: "L4013", Title: "IP ranges without a required banner directive", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-server-config"},
+		{Code: "L4014", Title: "MetaFind without Option MetaEZproxyRewriting", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4015", Title: "ByteServe or PDFRefresh host is not in the stanza", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4016", Title: "EncryptVar variable is not in AllowVars", Category: CategoryMissing, Severity: SeverityWarning},
+		{Code: "L4017", Title: "Host is missing its HTTP or HTTPS counterpart", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L4018", Title: "Privileged login port without RunAs directive", Category: CategoryMissing, Severity: SeverityWarning, Flag: "-server-config"},
+		{Code: "L5001", Title: "Directive uses the wrong case", Category: CategoryStyling, Severity: SeverityWarning, Fixable: true, Flag: "-case"},
+		{Code: "L5002", Title: "Line ends in a space or tab character", Category: CategoryStyling, Severity: SeverityWarning, Flag: "-whitespace"},
+		{Code: "L5003", Title: "URL is not normalized", Category: CategoryStyling, Severity: SeverityWarning, Fixable: true, Flag: "-pedantic"},
+		{Code: "L5004", Title: "Stanza has too many Host and Domain directives", Category: CategoryStyling, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L5005", Title: "Extra blank line", Category: CategoryStyling, Severity: SeverityWarning, Fixable: true, Flag: "-pedantic"},
+		{Code: "L5006", Title: "IP ranges can be sorted and merged", Category: CategoryStyling, Severity: SeverityWarning, Fixable: true, Flag: "-pedantic"},
+		{Code: "L5007", Title: "Description is too long", Category: CategoryStyling, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L5008", Title: "Directive label does not match the label style", Category: CategoryStyling, Severity: SeverityWarning, Fixable: true, Flag: "-label-style"},
+		{Code: "L5009", Title: "File is too large", Category: CategoryStyling, Severity: SeverityWarning, Flag: "-pedantic"},
+		{Code: "L5010", Title: "Included file does not have one stanza named after its title", Category: CategoryStyling, Severity: SeverityWarning, Flag: "-one-stanza-per-file"},
+		{Code: "L9001", Title: "Unknown directive", Category: CategoryOther, Severity: SeverityWarning},
+		{Code: "L9002", Title: "Source title doesn't match", Category: CategoryOther, Severity: SeverityWarning},
+		{Code: "L9003", Title: "Error processing Source line", Category: CategoryOther, Severity: SeverityWarning},
+		{Code: "L9004", Title: "Referenced file does not exist", Category: CategoryOther, Severity: SeverityWarning, Flag: "-files"},
+		{Code: "L9005", Title: "Peer hostname is the same as Name", Category: CategoryOther, Severity: SeverityWarning},
+		{Code: "L9006", Title: "Option enabling Domain lines that threaten network security is used", Category: CategoryOther, Severity: SeverityError},
+		{Code: "L9007", Title: "Stanza differs from the community version", Category: CategoryOther, Severity: SeverityWarning, Flag: "-community-repo"},
+		{Code: "L9008", Title: "Stanza hasn't been updated or reviewed recently", Category: CategoryOther, Severity: SeverityWarning, Flag: "-stale-days"},
+		{Code: "L9009", Title: "LoginCookieDomain does not contain Name", Category: CategoryOther, Severity: SeverityError},
+		{Code: "L9010", Title: "Server hostname is not in the same domain as Name", Category: CategoryOther, Severity: SeverityWarning},
+		{Code: "L9011", Title: "Stanza proxies the EZproxy server", Category: CategoryOther, Severity: SeverityError},
+		{Code: "L9012", Title: "Domain directive could be replaced by Host directives", Category: CategoryOther, Severity: SeverityWarning, Fixable: true, Flag: "-domain-hosts"},
+		{Code: "L9013", Title: "Source page could not be checked", Category: CategoryOther, Severity: SeverityInfo},
+		{Code: "L9014", Title: "Template placeholder without a value", Category: CategoryOther, Severity: SeverityError, Flag: "-values"},
+		{Code: "L9015", Title: "Stanza differs from its Source page", Category: CategoryOther, Severity: SeverityWarning, Flag: "-source-compare"},
+		{Code: "L9016", Title: "ProxyHostnameEdit conflicts with Option NoHttpsHyphens", Category: CategoryOther, Severity: SeverityWarning},
+	}
+}
+
+// RuleFixable reports whether the rule with the code can be fixed with the -fix option.
+func RuleFixable(code string) bool {
+	for _, rule := range Rules() {
+		if rule.Code == code {
+			return rule.Fixable
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestRulesMatchChecks(t *testing.T) {
+	checks, err := os.ReadFile(filepath.Join("..", "..", "CHECKS.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var documented []string
+	for _, match := range regexp.MustCompile(`(?m)^### (L\d{4}) - `).FindAllSubmatch(checks, -1) {
+		documented = append(documented, string(match[1]))
+	}
+	var codes []string
+	categories := map[byte]Category{
+		'1': CategoryOrdering,
+		'2': CategoryDuplication,
+		'3': CategoryMalformation,
+		'4': CategoryMissing,
+		'5': CategoryStyling,
+		'9': CategoryOther,
+	}
+	for _, rule := range Rules() {
+		codes = append(codes, rule.Code)
+		if categories[rule.Code[1]] != rule.Category {
+			t.Errorf("rule %v has category %q", rule.Code, rule.Category)
+		}
+	}
+	if !slices.Equal(codes, documented) {
+		t.Fatalf("rules %q do not match the checks documented in CHECKS.md %q", codes, documented)
+	}
+}
+
+func TestRulesMatchSource(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := map[string]bool{}
+	for _, rule := range Rules() {
+		codes[rule.Code] = true
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range regexp.MustCompile(`\((L\d{4})\)`).FindAllSubmatch(source, -1) {
+			if !codes[string(match[1])] {
+				t.Errorf("code %v used in %v is not in the rule registry", match[1], file)
+			}
+		}
+	}
+}
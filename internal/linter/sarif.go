@@ -0,0 +1,236 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The SARIF types are a small subset of the SARIF 2.1.0 format,
+// enough for code scanning tools to show findings next to the lines in a file.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+// A SARIFLog is the top level object of a SARIF file.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// A SARIFRun describes one run of the linter.
+type SARIFRun struct {
+	Tool              SARIFTool               `json:"tool"`
+	AutomationDetails *SARIFAutomationDetails `json:"automationDetails,omitempty"`
+	Invocations       []SARIFInvocation       `json:"invocations"`
+	Results           []SARIFResult           `json:"results"`
+}
+
+// SARIFAutomationDetails identify a run, like the run for one root of a manifest.
+type SARIFAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// A SARIFTool describes the linter and its rules.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// A SARIFDriver describes the linter and its rules.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// A SARIFRule describes one of the linter's rules.
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// A SARIFInvocation describes whether the run was successful, and any errors which stopped processing.
+// If the run's metadata is known, the start and end times are set, and the properties hold the rest of the metadata.
+type SARIFInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	StartTimeUTC               string              `json:"startTimeUtc,omitempty"`
+	EndTimeUTC                 string              `json:"endTimeUtc,omitempty"`
+	ToolExecutionNotifications []SARIFNotification `json:"toolExecutionNotifications,omitempty"`
+	Properties                 *Metadata           `json:"properties,omitempty"`
+}
+
+// A SARIFNotification is an error which stopped the linter from processing a file.
+type SARIFNotification struct {
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// A SARIFResult is a finding.
+// Partial fingerprints let code scanning tools track a finding as lines are added or removed.
+// The properties hold the category of the finding's rule, and the title of the stanza the finding is in, if known.
+type SARIFResult struct {
+	RuleID              string                 `json:"ruleId,omitempty"`
+	Level               string                 `json:"level"`
+	Message             SARIFMessage           `json:"message"`
+	Locations           []SARIFLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Fixes               []SARIFFix             `json:"fixes,omitempty"`
+	Properties          *SARIFResultProperties `json:"properties,omitempty"`
+}
+
+// A SARIFFix is a fix for a finding, made from the finding's edits.
+type SARIFFix struct {
+	Description     SARIFMessage          `json:"description"`
+	ArtifactChanges []SARIFArtifactChange `json:"artifactChanges"`
+}
+
+// A SARIFArtifactChange is the changes to one file.
+type SARIFArtifactChange struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Replacements     []SARIFReplacement    `json:"replacements"`
+}
+
+// A SARIFReplacement replaces a region of a file with new content.
+type SARIFReplacement struct {
+	DeletedRegion   SARIFRegion           `json:"deletedRegion"`
+	InsertedContent *SARIFArtifactContent `json:"insertedContent,omitempty"`
+}
+
+// A SARIFArtifactContent is the text inserted by a replacement.
+type SARIFArtifactContent struct {
+	Text string `json:"text"`
+}
+
+// SARIFResultProperties are the properties of a finding which are not part of the SARIF format.
+type SARIFResultProperties struct {
+	Category      Category `json:"category,omitempty"`
+	Stanza        string   `json:"stanza,omitempty"`
+	Documentation string   `json:"documentation,omitempty"`
+}
+
+// A SARIFMessage is the text of a message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// A SARIFLocation is the location of a finding or error.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// A SARIFPhysicalLocation is a file, and optionally a line in the file.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// A SARIFArtifactLocation is the URI of a file.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// A SARIFRegion is a line in a file, or a span of bytes in one or more lines.
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// SARIFFingerprintKey is the name of the partial fingerprint in SARIF results.
+// The version should be incremented if the way fingerprints are made changes.
+const SARIFFingerprintKey = "ezproxyConfigLintFingerprint/v1"
+
+// SARIFLevel returns the SARIF level for a severity.
+func SARIFLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityInfo:
+		return "note"
+	}
+	return "warning"
+}
+
+// NewSARIFLocation returns the SARIF location for a file and line. Lines less than one are omitted.
+func NewSARIFLocation(file string, line int) SARIFLocation {
+	location := SARIFLocation{PhysicalLocation: SARIFPhysicalLocation{
+		ArtifactLocation: SARIFArtifactLocation{URI: filepath.ToSlash(file)},
+	}}
+	if line > 0 {
+		location.PhysicalLocation.Region = &SARIFRegion{StartLine: line}
+	}
+	return location
+}
+
+// SARIF returns the collected findings and errors as a SARIF log.
+func (l *Linter) SARIF() SARIFLog {
+	return Report{Metadata: l.Metadata, Findings: l.Report.Findings, Errors: l.Report.Errors}.SARIF()
+}
+
+// SARIF returns the report's findings and errors as a SARIF log.
+func (r Report) SARIF() SARIFLog {
+	driver := SARIFDriver{
+		Name:           "ezproxy-config-lint",
+		InformationURI: "https://github.com/cu-library/ezproxy-config-lint",
+		Rules:          []SARIFRule{},
+	}
+	for _, rule := range Rules() {
+		driver.Rules = append(driver.Rules, SARIFRule{ID: rule.Code, ShortDescription: SARIFMessage{Text: rule.Title}})
+	}
+	invocation := SARIFInvocation{ExecutionSuccessful: len(r.Errors) == 0}
+	if r.Metadata != nil {
+		driver.Version = r.Metadata.Version
+		invocation.StartTimeUTC = r.Metadata.Started.UTC().Format(time.RFC3339Nano)
+		invocation.EndTimeUTC = r.Metadata.Finished.UTC().Format(time.RFC3339Nano)
+		invocation.Properties = r.Metadata
+	}
+	for _, e := range r.Errors {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, SARIFNotification{
+			Level:     "error",
+			Message:   SARIFMessage{Text: e.Message},
+			Locations: []SARIFLocation{NewSARIFLocation(e.File, 0)},
+		})
+	}
+	results := []SARIFResult{}
+	for _, f := range r.Findings {
+		result := SARIFResult{
+			RuleID:    f.Code,
+			Level:     SARIFLevel(f.Severity),
+			Message:   SARIFMessage{Text: strings.TrimSpace(f.Message + f.Repeats())},
+			Locations: []SARIFLocation{NewSARIFLocation(f.File, f.Line)},
+			PartialFingerprints: map[string]string{
+				SARIFFingerprintKey: f.Fingerprint,
+			},
+		}
+		if region := result.Locations[0].PhysicalLocation.Region; region != nil {
+			region.StartColumn, region.EndColumn = f.Column, f.EndColumn
+		}
+		if len(f.Edits) > 0 {
+			change := SARIFArtifactChange{ArtifactLocation: SARIFArtifactLocation{URI: filepath.ToSlash(f.File)}}
+			for _, e := range f.Edits {
+				replacement := SARIFReplacement{DeletedRegion: SARIFRegion{
+					StartLine: e.StartLine, StartColumn: e.StartColumn, EndLine: e.EndLine, EndColumn: e.EndColumn,
+				}}
+				if e.NewText != "" {
+					replacement.InsertedContent = &SARIFArtifactContent{Text: e.NewText}
+				}
+				change.Replacements = append(change.Replacements, replacement)
+			}
+			result.Fixes = []SARIFFix{{Description: SARIFMessage{Text: "Apply the fix from -fix"}, ArtifactChanges: []SARIFArtifactChange{change}}}
+		}
+		if f.Category != "" || f.Stanza != "" || f.Documentation != "" {
+			result.Properties = &SARIFResultProperties{Category: f.Category, Stanza: f.Stanza, Documentation: f.Documentation}
+		}
+		results = append(results, result)
+	}
+	return SARIFLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []SARIFRun{{Tool: SARIFTool{Driver: driver}, Invocations: []SARIFInvocation{invocation}, Results: results}},
+	}
+}
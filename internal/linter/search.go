@@ -0,0 +1,49 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A Match is a line found by Search.
+type Match struct {
+	File      string
+	Line      int
+	Directive Directive
+	Text      string
+	Argument  string
+}
+
+// GlobRegexp compiles a glob pattern, like "*.elsevier.com", to a regular expression which matches a whole argument.
+// A "*" matches any characters, including none, and a "?" matches any one character. Matching is not case sensitive.
+func GlobRegexp(pattern string) (*regexp.Regexp, error) {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.Compile("(?i)^" + quoted + "$")
+}
+
+// Search returns the lines with the directive whose arguments match the pattern.
+func Search(lines []ResolvedLine, directive Directive, pattern *regexp.Regexp) (matches []Match) {
+	for _, line := range lines {
+		if !line.Known || line.Directive != directive {
+			continue
+		}
+		argument := TrimLabel(line.Line, CanonicalLabel(directive))
+		if !pattern.MatchString(argument) {
+			continue
+		}
+		file, lineNum := SplitAt(line.At)
+		matches = append(matches, Match{
+			File:      file,
+			Line:      lineNum,
+			Directive: directive,
+			Text:      line.Text,
+			Argument:  argument,
+		})
+	}
+	return matches
+}
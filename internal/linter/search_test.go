@@ -0,0 +1,38 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	lines := []ResolvedLine{
+		ResolveLine("Title Elsevier", "config.txt:1"),
+		ResolveLine("D sciencedirect.com", "config.txt:2"),
+		ResolveLine("Domain www.Elsevier.com", "config.txt:3"),
+		ResolveLine("DJ cdn.elsevier.com", "config.txt:4"),
+		ResolveLine("Domain elsevier.com.example.org", "config.txt:5"),
+		ResolveLine("Option Cookie", "config.txt:6"),
+	}
+	pattern, err := GlobRegexp("*.elsevier.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := Search(lines, Domain, pattern)
+	expected := []Match{{File: "config.txt", Line: 3, Directive: Domain, Text: "Domain www.Elsevier.com", Argument: "www.Elsevier.com"}}
+	if !reflect.DeepEqual(matches, expected) {
+		t.Fatalf("incorrect matches %+v", matches)
+	}
+
+	pattern, err = GlobRegexp("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches := Search(lines, OptionCookie, pattern); len(matches) != 1 || matches[0].Argument != "" {
+		t.Fatalf("incorrect matches for an option %+v", matches)
+	}
+}
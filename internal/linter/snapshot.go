@@ -0,0 +1,81 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// SnapshotVersion is the version of the snapshot file format.
+// It should be incremented when the format changes in a way older versions can't read.
+const SnapshotVersion = 1
+
+// A Snapshot records the findings from a run of the linter, so later runs can report only what has changed.
+type Snapshot struct {
+	Version  int
+	Findings []Finding
+}
+
+// LocationRegex matches the "at" locations in messages, like "config.txt:12", so they can be ignored.
+var LocationRegex = regexp.MustCompile(`:\d+"`)
+
+// Key identifies a finding without its line number, so that a finding can be matched
+// between runs even if lines were added or removed above it.
+func (f Finding) Key() string {
+	return fmt.Sprintf("%v\x00%v\x00%v\x00%v", f.File, f.Code, f.Text, LocationRegex.ReplaceAllString(f.Message, `"`))
+}
+
+// ReadSnapshot reads the snapshot file at path.
+func ReadSnapshot(path string) (s Snapshot, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(content, &s); err != nil {
+		return s, fmt.Errorf("unable to read snapshot %v: %w", path, err)
+	}
+	if s.Version != SnapshotVersion {
+		return s, fmt.Errorf("snapshot %v has version %v, but this version of the linter reads version %v", path, s.Version, SnapshotVersion)
+	}
+	return s, nil
+}
+
+// WriteSnapshot writes the findings to a snapshot file at path.
+func WriteSnapshot(path string, findings []Finding) error {
+	s := Snapshot{Version: SnapshotVersion, Findings: append([]Finding{}, findings...)}
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0600)
+}
+
+// CompareSnapshot compares the findings from the current run against a snapshot.
+// Added findings are not in the snapshot, suppressed findings were found and are in the snapshot,
+// and fixed findings are in the snapshot but were not found.
+// Findings are matched by Key, and repeated findings are matched one for one.
+func CompareSnapshot(s Snapshot, findings []Finding) (added, suppressed, fixed []Finding) {
+	remaining := map[string]int{}
+	for _, f := range s.Findings {
+		remaining[f.Key()]++
+	}
+	for _, f := range findings {
+		if remaining[f.Key()] > 0 {
+			remaining[f.Key()]--
+			suppressed = append(suppressed, f)
+			continue
+		}
+		added = append(added, f)
+	}
+	for _, f := range s.Findings {
+		if remaining[f.Key()] > 0 {
+			remaining[f.Key()]--
+			fixed = append(fixed, f)
+		}
+	}
+	return added, suppressed, fixed
+}
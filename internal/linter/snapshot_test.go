@@ -0,0 +1,44 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompareSnapshot(t *testing.T) {
+	before := []Finding{
+		{File: "config.txt", Line: 3, Text: "FooBar", Code: "L9001", Message: "Unknown directive \"FooBar\" (L9001)"},
+		{File: "config.txt", Line: 9, Text: "URL https://www.jstor.org", Code: "L2002", Message: "Origin already seen at \"config.txt:2\" (L2002)"},
+		{File: "config.txt", Line: 12, Text: "HJ a.com ", Code: "L5002", Message: "Line ends in a space or tab character (L5002)"},
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := WriteSnapshot(path, before); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two lines were added to the top of the file, one issue was fixed, and a new one was added.
+	after := []Finding{
+		{File: "config.txt", Line: 5, Text: "FooBar", Code: "L9001", Message: "Unknown directive \"FooBar\" (L9001)"},
+		{File: "config.txt", Line: 11, Text: "URL https://www.jstor.org", Code: "L2002", Message: "Origin already seen at \"config.txt:4\" (L2002)"},
+		{File: "config.txt", Line: 20, Text: "BarFoo", Code: "L9001", Message: "Unknown directive \"BarFoo\" (L9001)"},
+	}
+	added, suppressed, fixed := CompareSnapshot(s, after)
+	if !reflect.DeepEqual(added, after[2:]) {
+		t.Fatalf("incorrect added findings %+v", added)
+	}
+	if !reflect.DeepEqual(suppressed, after[:2]) {
+		t.Fatalf("incorrect suppressed findings %+v", suppressed)
+	}
+	if !reflect.DeepEqual(fixed, before[2:]) {
+		t.Fatalf("incorrect fixed findings %+v", fixed)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TitleSortKey returns the key used to sort stanzas by title, ignoring case and the -Hide qualifier.
+func TitleSortKey(title string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(title), "-Hide "))
+}
+
+// StanzaOrderCheck reports the stanza whose Title was just processed if it is not in alphabetical order
+// with the stanza before it in the same file. A run of stanzas ends at a block of lines without a Title,
+// like server directives or a comment which starts a new section.
+func (l *Linter) StanzaOrderCheck() (m []string) {
+	if l.PreviousStanzaTitle != "" && TitleSortKey(l.State.Title) < TitleSortKey(l.PreviousStanzaTitle) {
+		m = append(m, fmt.Sprintf("Stanza %q is not in alphabetical order, it should be before %q (L1018)", l.State.Title, l.PreviousStanzaTitle))
+	}
+	l.PreviousStanzaTitle = l.State.Title
+	return m
+}
+
+// A stanzaBlock is a block of lines between empty lines or "#" lines.
+type stanzaBlock struct {
+	lines    []string
+	title    string
+	hasGroup bool
+}
+
+// SortStanzas returns the lines with each run of stanzas sorted alphabetically by Title,
+// and the number of lines which were moved. Runs of stanzas end at blocks of lines without a Title,
+// like StanzaOrderCheck. Runs with a Group directive are not sorted, because moving a stanza
+// would change the Group it belongs to.
+func SortStanzas(lines []string) (sorted []string, count int) {
+	// Split the lines into blocks. The separator lines after each block stay in place when blocks are sorted.
+	var prefix []string
+	var blocks []stanzaBlock
+	var separators [][]string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "#" {
+			if len(blocks) == 0 {
+				prefix = append(prefix, line)
+			} else {
+				separators[len(separators)-1] = append(separators[len(separators)-1], line)
+			}
+			continue
+		}
+		if len(blocks) == 0 || len(separators[len(separators)-1]) > 0 {
+			blocks = append(blocks, stanzaBlock{})
+			separators = append(separators, nil)
+		}
+		b := &blocks[len(blocks)-1]
+		b.lines = append(b.lines, line)
+		if r := ResolveLine(line, ""); r.Known && r.Directive == Title && b.title == "" {
+			b.title = TrimDirective(line, Title)
+		} else if r.Known && r.Directive == Group {
+			b.hasGroup = true
+		}
+	}
+
+	// Sort each run of blocks with titles.
+	for start := 0; start < len(blocks); {
+		end := start
+		for end < len(blocks) && blocks[end].title != "" {
+			end++
+		}
+		run := blocks[start:end]
+		if !slices.ContainsFunc(run, func(b stanzaBlock) bool { return b.hasGroup }) {
+			original := slices.Clone(run)
+			slices.SortStableFunc(run, func(a, b stanzaBlock) int {
+				return cmp.Compare(TitleSortKey(a.title), TitleSortKey(b.title))
+			})
+			for i := range run {
+				if !slices.Equal(run[i].lines, original[i].lines) {
+					count += len(run[i].lines)
+				}
+			}
+		}
+		start = max(end, start+1)
+	}
+
+	sorted = append(sorted, prefix...)
+	for i, block := range blocks {
+		sorted = append(sorted, block.lines...)
+		sorted = append(sorted, separators[i]...)
+	}
+	return sorted, count
+}
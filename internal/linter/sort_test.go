@@ -0,0 +1,98 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStanzaOrderCheck(t *testing.T) {
+	lines := []string{
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"",
+		"Title -Hide EBSCO",
+		"URL https://search.ebscohost.com",
+		"",
+		"# Databases for the law library.",
+		"",
+		"Title Westlaw",
+		"URL https://www.westlaw.com",
+		"",
+		"Title HeinOnline",
+		"URL https://heinonline.org",
+		"",
+	}
+	linter := Linter{Pedantic: true}
+	var messages []string
+	for i, line := range lines {
+		for _, message := range Messages(linter.ProcessLineAt(line, fmt.Sprintf("test:%v", i+1))) {
+			if strings.HasSuffix(message, "(L1018)") {
+				messages = append(messages, message)
+			}
+		}
+	}
+	expected := []string{
+		"Stanza \"-Hide EBSCO\" is not in alphabetical order, it should be before \"JSTOR\" (L1018)",
+		"Stanza \"HeinOnline\" is not in alphabetical order, it should be before \"Westlaw\" (L1018)",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+}
+
+func TestSortStanzas(t *testing.T) {
+	lines := []string{
+		"",
+		"# Source - https://help.oclc.org/JSTOR",
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"",
+		"",
+		"T EBSCO",
+		"U https://search.ebscohost.com",
+		"#",
+		"Title Alexander Street",
+		"URL https://search.alexanderstreet.com",
+		"",
+		"# Databases for the law library.",
+		"",
+		"Group Law",
+		"Title Westlaw",
+		"URL https://www.westlaw.com",
+		"",
+		"Title HeinOnline",
+		"URL https://heinonline.org",
+	}
+	expected := []string{
+		"",
+		"Title Alexander Street",
+		"URL https://search.alexanderstreet.com",
+		"",
+		"",
+		"T EBSCO",
+		"U https://search.ebscohost.com",
+		"#",
+		"# Source - https://help.oclc.org/JSTOR",
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"",
+		"# Databases for the law library.",
+		"",
+		"Group Law",
+		"Title Westlaw",
+		"URL https://www.westlaw.com",
+		"",
+		"Title HeinOnline",
+		"URL https://heinonline.org",
+	}
+	sorted, count := SortStanzas(lines)
+	if !reflect.DeepEqual(sorted, expected) || count != 5 {
+		t.Fatalf("incorrect sorted lines %q with count %v", sorted, count)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A SourceStanza is a stanza found on a Source page.
+type SourceStanza struct {
+	Title string
+	Lines []string
+}
+
+// ParseSourceStanzas returns the stanzas in the <pre> elements of a Source page, in page order.
+// A <pre> element can have several stanzas, and its text can be split up by other elements, like <br> or <span>.
+// Blocks of lines without a Title directive, like comments or Option directives separated from the stanza
+// by a blank line, belong to the stanza before them, or the stanza after them if they are first in the element.
+// Blank lines are not kept.
+func ParseSourceStanzas(doc *html.Node) (stanzas []SourceStanza) {
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "pre" {
+			var b strings.Builder
+			preText(n, &b)
+			stanzas = append(stanzas, splitSourceStanzas(b.String())...)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return stanzas
+}
+
+// preText writes the text in an element to b, with <br> elements as line breaks.
+func preText(n *html.Node, b *strings.Builder) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type == html.ElementNode && c.Data == "br":
+			b.WriteString("\n")
+		default:
+			preText(c, b)
+		}
+	}
+}
+
+// splitSourceStanzas splits the text of a <pre> element into stanzas.
+func splitSourceStanzas(text string) (stanzas []SourceStanza) {
+	var blocks [][]string
+	var block []string
+	for line := range strings.Lines(text) {
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == "" {
+			if len(block) > 0 {
+				blocks = append(blocks, block)
+			}
+			block = nil
+			continue
+		}
+		block = append(block, line)
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+	var pending []string
+	for _, block := range blocks {
+		if !slices.ContainsFunc(block, func(line string) bool { _, ok := sourceTitle(line); return ok }) {
+			if len(stanzas) > 0 {
+				stanzas[len(stanzas)-1].Lines = append(stanzas[len(stanzas)-1].Lines, block...)
+			} else {
+				pending = append(pending, block...)
+			}
+			continue
+		}
+		s := SourceStanza{Lines: pending}
+		pending = nil
+		for _, line := range block {
+			if title, ok := sourceTitle(line); ok {
+				// A second Title directive without a blank line before it starts another stanza.
+				if s.Title != "" {
+					stanzas = append(stanzas, s)
+					s = SourceStanza{}
+				}
+				s.Title = title
+			}
+			s.Lines = append(s.Lines, line)
+		}
+		stanzas = append(stanzas, s)
+	}
+	return stanzas
+}
+
+// sourceTitle returns the title if the line is a Title directive.
+func sourceTitle(line string) (title string, ok bool) {
+	label, argument := SplitLabel(line)
+	if directive, known := LabelDirective(label); known && directive == Title {
+		return argument, true
+	}
+	return "", false
+}
+
+// SelectSourceStanza returns the stanza on a Source page which matches a local stanza.
+// The first stanza whose title is one of the given titles, like the local title or the title the stanza was renamed from, is used.
+// Otherwise, the first stanza is used, since most Source pages only have one stanza.
+func SelectSourceStanza(stanzas []SourceStanza, titles ...string) (s SourceStanza, ok bool) {
+	if len(stanzas) == 0 {
+		return s, false
+	}
+	for _, s := range stanzas {
+		if slices.Contains(titles, strings.TrimPrefix(s.Title, "-Hide ")) {
+			return s, true
+		}
+	}
+	return stanzas[0], true
+}
+
+// SourceLines normalizes the lines of a stanza from a Source page for comparison with the local stanza,
+// like CommunityLine. Comments are skipped, multiline segments are joined, and abbreviated labels are expanded.
+func SourceLines(lines []string) (normalized []string) {
+	segments := ""
+	for _, line := range lines {
+		if strings.HasSuffix(line, "\\") {
+			segments += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		r := ResolveLine(segments+line, "")
+		segments = ""
+		if r.Comment {
+			continue
+		}
+		normalized = append(normalized, CommunityLine(r.Line))
+	}
+	return normalized
+}
+
+// SourceCompareCheck compares the stanza which just ended with the stanza on its Source page.
+// Stanzas whose title doesn't match the Source page are already reported, so they aren't compared,
+// and the Title lines are left out, since a hidden or renamed stanza's title is expected to differ.
+func (l *Linter) SourceCompareCheck() (m []string) {
+	if len(l.State.SourceLines) == 0 || l.State.OCLCTitle == "" {
+		return m
+	}
+	title := strings.TrimPrefix(l.State.Title, "-Hide ")
+	if l.State.OCLCTitle != l.State.Title && l.State.OCLCTitle != title && l.State.OCLCTitle != l.Config.TitleAliases[title] {
+		return m
+	}
+	isTitle := func(line string) bool {
+		label, _ := SplitLabel(line)
+		directive, _ := LabelDirective(label)
+		return directive == Title
+	}
+	source := slices.DeleteFunc(SourceLines(l.State.SourceLines), isTitle)
+	local := slices.DeleteFunc(slices.Clone(l.State.Lines), isTitle)
+	added, missing := lineDifference(source, local)
+	if len(missing) == 0 && len(added) == 0 {
+		return m
+	}
+	m = append(m, fmt.Sprintf("Stanza %q differs from the stanza on its Source page %q: "+
+		"%v lines are only on the Source page, and %v lines are only in this stanza (L9015)",
+		cmp.Or(l.State.Title, l.State.URL), l.State.Source, len(missing), len(added)))
+	return m
+}
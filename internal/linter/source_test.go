@@ -0,0 +1,130 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestParseSourceStanzas(t *testing.T) {
+	page := `<html><body>
+<p>Use this stanza for the main site:</p>
+<pre>Title Example<br>URL https://www.example.com<br><span>DJ example.com</span></pre>
+<p>Use these stanzas for the archives:</p>
+<pre>
+Option DomainCookieOnly
+
+T Example Archive
+URL https://archive.example.com
+Title Example Archive (Hidden)
+URL https://hidden.example.com
+
+# Close the option
+Option Cookie
+</pre>
+<pre>Not a stanza</pre>
+</body></html>`
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SourceStanza{
+		{Title: "Example", Lines: []string{"Title Example", "URL https://www.example.com", "DJ example.com"}},
+		{Title: "Example Archive", Lines: []string{"Option DomainCookieOnly", "T Example Archive", "URL https://archive.example.com"}},
+		{Title: "Example Archive (Hidden)", Lines: []string{"Title Example Archive (Hidden)", "URL https://hidden.example.com", "# Close the option", "Option Cookie"}},
+	}
+	if stanzas := ParseSourceStanzas(doc); !reflect.DeepEqual(stanzas, expected) {
+		t.Fatalf("incorrect stanzas %q instead of %q", stanzas, expected)
+	}
+}
+
+func TestSelectSourceStanza(t *testing.T) {
+	stanzas := []SourceStanza{{Title: "First"}, {Title: "Second"}, {Title: "-Hide Third"}}
+	var tests = []struct {
+		titles   []string
+		expected string
+	}{
+		{[]string{"Second"}, "Second"},
+		{[]string{"Local", "Third"}, "-Hide Third"},
+		{[]string{"Unknown"}, "First"},
+		{nil, "First"},
+	}
+	for _, tt := range tests {
+		if s, ok := SelectSourceStanza(stanzas, tt.titles...); !ok || s.Title != tt.expected {
+			t.Fatalf("selected %q instead of %q for %q", s.Title, tt.expected, tt.titles)
+		}
+	}
+	if _, ok := SelectSourceStanza(nil, "First"); ok {
+		t.Fatal("selected a stanza from an empty page")
+	}
+}
+
+func TestSourceStanzaTitle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<pre>Title Main (updated 20240101)\nURL https://www.example.com</pre>"+
+			"<pre>Title Archive (updated 20240101)\nURL https://archive.example.com</pre>")
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		title    string
+		expected []string
+	}{
+		{"Title Archive (updated 20240101)", nil},
+		{"Title -Hide Main (updated 20240101)", nil},
+		{"Title Archive (updated 20180101)", []string{"Source title doesn't match, you might need to update this stanza (L9002)"}},
+	}
+	for _, tt := range tests {
+		linter := Linter{Source: true, Client: server.Client(), Config: Config{SourceHosts: map[string]bool{strings.TrimPrefix(server.URL, "https://"): true}}}
+		if messages := Messages(linter.ProcessLineAt("# Source - "+server.URL, "test:1")); messages != nil {
+			t.Fatalf("unexpected messages %q", messages)
+		}
+		if messages := Messages(linter.ProcessLineAt(tt.title, "test:2")); !reflect.DeepEqual(messages, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q for %q", messages, tt.expected, tt.title)
+		}
+	}
+}
+
+func TestSourceCompareCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<pre>Title Main\n# Use this stanza for the main site\nURL https://www.example.com\nHJ www.example.com\nDomain example.com</pre>")
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		lines    []string
+		expected []string
+	}{
+		{[]string{"Title Main", "URL https://www.example.com", "Domain example.com", "HostJavaScript   www.example.com"}, nil},
+		{[]string{"Title -Hide Main", "URL https://www.example.com", "HJ www.example.com", "Domain example.com"}, nil},
+		{[]string{"Title Main", "URL https://www.example.com", "HJ www.example.com", "DJ example.com"},
+			[]string{"Stanza \"Main\" differs from the stanza on its Source page \"" + server.URL + "\": " +
+				"1 lines are only on the Source page, and 1 lines are only in this stanza (L9015)"}},
+		{[]string{"Title Other", "URL https://www.example.com"}, nil},
+	}
+	for _, tt := range tests {
+		linter := Linter{Source: true, SourceCompare: true, Client: server.Client(), Config: Config{SourceHosts: map[string]bool{strings.TrimPrefix(server.URL, "https://"): true}}}
+		linter.ProcessLineAt("# Source - "+server.URL, "test:1")
+		for i, line := range tt.lines {
+			linter.ProcessLineAt(line, fmt.Sprintf("test:%v", i+2))
+		}
+		var messages []string
+		for _, message := range Messages(linter.ProcessLineAt("", "test:9")) {
+			if strings.HasSuffix(message, "(L9015)") {
+				messages = append(messages, message)
+			}
+		}
+		if !reflect.DeepEqual(messages, tt.expected) {
+			t.Fatalf("incorrect messages %q instead of %q for %q", messages, tt.expected, tt.lines)
+		}
+	}
+}
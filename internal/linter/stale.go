@@ -0,0 +1,40 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ReviewDateRegex matches comments which record when a stanza was updated or reviewed,
+// like "# Updated: 2024-05-01" or "# Last reviewed 20240501".
+var ReviewDateRegex = regexp.MustCompile(`(?i)^#\s*(?:last\s+)?(?:updated|reviewed)\s*[:\-]?\s*(\d{4}-\d{2}-\d{2}|\d{8})\b`)
+
+// ReviewDate returns the date recorded in an updated or reviewed comment.
+func ReviewDate(comment string) (date time.Time, ok bool) {
+	match := ReviewDateRegex.FindStringSubmatch(comment)
+	if match == nil {
+		return date, false
+	}
+	layout := time.DateOnly
+	if len(match[1]) == 8 {
+		layout = "20060102"
+	}
+	date, err := time.Parse(layout, match[1])
+	return date, err == nil
+}
+
+// StaleCheck reports the stanza which just ended if the latest date in its updated or reviewed comments
+// is older than StaleAfter. Stanzas without those comments are not reported.
+func (l *Linter) StaleCheck() (m []string) {
+	if (l.State.Title == "" && l.State.URL == "") || l.State.ReviewedOn.IsZero() || time.Since(l.State.ReviewedOn) <= l.StaleAfter {
+		return m
+	}
+	m = append(m, fmt.Sprintf("Stanza %q was last updated or reviewed on %v, more than %v days ago (L9008)",
+		cmp.Or(l.State.Title, l.State.URL), l.State.ReviewedOn.Format(time.DateOnly), int(l.StaleAfter.Hours()/24)))
+	return m
+}
@@ -0,0 +1,58 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReviewDate(t *testing.T) {
+	var tests = []struct {
+		comment string
+		date    string
+	}{
+		{"# Updated: 2024-05-01", "2024-05-01"},
+		{"# reviewed 20240501", "2024-05-01"},
+		{"#Last Reviewed - 2023-01-31 by jsmith", "2023-01-31"},
+		{"# Updated: 2024-13-01", ""},
+		{"# Created: 2024-05-01", ""},
+		{"# Source - https://help.oclc.org/", ""},
+	}
+	for _, tt := range tests {
+		date, ok := ReviewDate(tt.comment)
+		if ok != (tt.date != "") || (ok && date.Format(time.DateOnly) != tt.date) {
+			t.Fatalf("incorrect date %v, %v for %q", date, ok, tt.comment)
+		}
+	}
+}
+
+func TestStaleCheck(t *testing.T) {
+	linter := Linter{StaleAfter: 365 * 24 * time.Hour}
+	recent := time.Now().AddDate(0, -1, 0).Format(time.DateOnly)
+	var messages []string
+	for i, line := range []string{
+		"# Updated: 2001-01-01",
+		"# Reviewed: " + recent,
+		"Title Recent",
+		"URL https://recent.example.com",
+		"",
+		"# Updated: 2001-01-01",
+		"Title Old",
+		"URL https://old.example.com",
+		"",
+		"Title Undated",
+		"URL https://undated.example.com",
+		"",
+	} {
+		messages = append(messages, Messages(linter.ProcessLineAt(line, fmt.Sprintf("config.txt:%v", i+1)))...)
+	}
+	expected := []string{"Stanza \"Old\" was last updated or reviewed on 2001-01-01, more than 365 days ago (L9008)"}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q", messages)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"strings"
+)
+
+// A StartingPoint is the proxied starting point URL of a stanza,
+// the link which sends users through EZproxy to the stanza's URL.
+type StartingPoint struct {
+	File          string
+	Line          int
+	Title         string
+	URL           string
+	StartingPoint string
+}
+
+// StartingPointPrefix returns the prefix which is put in front of a URL to proxy it.
+// A prefix which is only the EZproxy server's URL, like "https://proxy.example.edu",
+// has "/login?url=" added to it.
+func StartingPointPrefix(proxyPrefix string) string {
+	if strings.Contains(proxyPrefix, "?") {
+		return proxyPrefix
+	}
+	return strings.TrimRight(proxyPrefix, "/") + "/login?url="
+}
+
+// StartingPoints returns the proxied starting point URL for each stanza with a URL directive, in config order.
+// Hidden stanzas are included, and stanzas with an unparseable URL directive are skipped.
+func StartingPoints(stanzas [][]ResolvedLine, proxyPrefix string) (found []StartingPoint) {
+	prefix := StartingPointPrefix(proxyPrefix)
+	for _, stanza := range stanzas {
+		for _, line := range stanza {
+			if !line.Known || line.Directive != URL {
+				continue
+			}
+			u, err := ParseURLDirective(line.Line)
+			if err != nil {
+				continue
+			}
+			file, lineNum := SplitAt(line.At)
+			found = append(found, StartingPoint{File: file, Line: lineNum, Title: strings.TrimPrefix(stanzaTitle(stanza), "-Hide "), URL: u.URL, StartingPoint: prefix + u.URL})
+			break
+		}
+	}
+	return found
+}
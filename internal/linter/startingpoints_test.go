@@ -0,0 +1,48 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStartingPointPrefix(t *testing.T) {
+	var tests = []struct {
+		proxyPrefix string
+		expected    string
+	}{
+		{"https://proxy.example.edu", "https://proxy.example.edu/login?url="},
+		{"https://proxy.example.edu/", "https://proxy.example.edu/login?url="},
+		{"https://proxy.example.edu/login?url=", "https://proxy.example.edu/login?url="},
+		{"https://login.proxy.example.edu/login?qurl=", "https://login.proxy.example.edu/login?qurl="},
+	}
+	for _, tt := range tests {
+		if prefix := StartingPointPrefix(tt.proxyPrefix); prefix != tt.expected {
+			t.Fatalf("incorrect prefix %q instead of %q for %q", prefix, tt.expected, tt.proxyPrefix)
+		}
+	}
+}
+
+func TestStartingPoints(t *testing.T) {
+	stanzas := ResolvedStanzas(resolveLines(
+		"Title JSTOR",
+		"URL https://www.jstor.org",
+		"URL https://www.jstor.org/second",
+		"",
+		"Title -Hide Hidden",
+		"U -Refresh hidden https://hidden.example.com/start",
+		"",
+		"Title No URL",
+		"H https://host.example.com",
+	))
+	expected := []StartingPoint{
+		{File: "config.txt", Line: 2, Title: "JSTOR", URL: "https://www.jstor.org", StartingPoint: "https://proxy.example.edu/login?url=https://www.jstor.org"},
+		{File: "config.txt", Line: 6, Title: "Hidden", URL: "https://hidden.example.com/start", StartingPoint: "https://proxy.example.edu/login?url=https://hidden.example.com/start"},
+	}
+	if found := StartingPoints(stanzas, "https://proxy.example.edu"); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("incorrect starting points %+v instead of %+v", found, expected)
+	}
+}
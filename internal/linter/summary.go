@@ -0,0 +1,85 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// A Summary counts the findings of a run, so periodic audits can track the health of a config over time.
+// Issues doesn't count informational findings, like the issue count printed at the end of a run.
+type Summary struct {
+	Time       time.Time
+	Version    string
+	Files      []string
+	Issues     int
+	Severities map[Severity]int
+	Codes      map[string]int
+	Errors     []ProcessingError
+}
+
+// A SummaryWriter counts the findings and errors in a Summary, and passes them on to another FindingWriter.
+type SummaryWriter struct {
+	Writer  FindingWriter
+	Summary Summary
+}
+
+// NewSummaryWriter returns a SummaryWriter which passes the findings and errors on to w.
+func NewSummaryWriter(w FindingWriter) *SummaryWriter {
+	return &SummaryWriter{Writer: w, Summary: Summary{Files: []string{}, Severities: map[Severity]int{}, Codes: map[string]int{}, Errors: []ProcessingError{}}}
+}
+
+// WriteFindings counts the findings, then writes them to the wrapped writer.
+func (w *SummaryWriter) WriteFindings(findings []Finding) error {
+	for _, f := range findings {
+		if f.Severity != SeverityInfo {
+			w.Summary.Issues++
+		}
+		w.Summary.Severities[f.Severity]++
+		w.Summary.Codes[f.Code]++
+	}
+	return w.Writer.WriteFindings(findings)
+}
+
+// WriteError records the error, then writes it to the wrapped writer.
+func (w *SummaryWriter) WriteError(e ProcessingError) error {
+	w.Summary.Errors = append(w.Summary.Errors, e)
+	return w.Writer.WriteError(e)
+}
+
+// Close closes the wrapped writer.
+func (w *SummaryWriter) Close() error {
+	return w.Writer.Close()
+}
+
+// WriteSummary writes the summary to path, replacing the summary of the last run.
+func WriteSummary(path string, s Summary) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0600)
+}
+
+// AppendHistory adds the summary to the end of the history file at path, as one line of JSON,
+// so each run adds a line and the file can be read as JSON Lines.
+func AppendHistory(path string, s Summary) (err error) {
+	content, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = f.Write(append(content, '\n'))
+	return err
+}
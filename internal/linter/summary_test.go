@@ -0,0 +1,69 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSummaryWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(path, []byte("Title Example\nFooBar\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := &Linter{Format: FormatJSON, Output: io.Discard}
+	w := NewSummaryWriter(linter.FindingWriter())
+	linter.Writer = w
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	linter.ReportError("missing.txt", os.ErrNotExist)
+	expected := Summary{
+		Files:      []string{},
+		Issues:     2,
+		Severities: map[Severity]int{SeverityWarning: 2},
+		Codes:      map[string]int{"L4003": 1, "L9001": 1},
+		Errors:     []ProcessingError{{File: "missing.txt", Message: os.ErrNotExist.Error()}},
+	}
+	if !reflect.DeepEqual(w.Summary, expected) {
+		t.Fatalf("incorrect summary %+v instead of %+v", w.Summary, expected)
+	}
+	// The findings are passed on to the wrapped writer.
+	if len(linter.Report.Findings) != 2 || len(linter.Report.Errors) != 1 {
+		t.Fatalf("incorrect report %+v", linter.Report)
+	}
+}
+
+func TestAppendHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	for i := range 3 {
+		if err := AppendHistory(path, Summary{Issues: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var issues []int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Summary
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		issues = append(issues, s.Issues)
+	}
+	if !reflect.DeepEqual(issues, []int{0, 1, 2}) {
+		t.Fatalf("incorrect history %v", issues)
+	}
+}
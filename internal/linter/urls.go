@@ -0,0 +1,96 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A URLDirective stores the parts of a URL directive line.
+// OCLC documentation:
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_1
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_2
+// https://help.oclc.org/Library_Management/EZproxy/Configure_resources/URL_version_3
+type URLDirective struct {
+	Refresh     bool
+	Redirect    bool
+	Append      bool
+	Encoded     bool
+	RewriteHost bool
+	Form        string
+	Name        string
+	URL         string
+}
+
+// ParseURLDirective parses a URL directive line. Qualifiers are accepted in any order,
+// and the errors explain which documented form the line does not match.
+func ParseURLDirective(line string) (u URLDirective, err error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || (!strings.EqualFold(fields[0], "URL") && !strings.EqualFold(fields[0], "U")) {
+		return u, errors.New("line does not start with \"URL\" or \"U\"")
+	}
+	var args []string
+	seen := map[string]bool{}
+	for _, field := range fields[1:] {
+		// Qualifiers come before the name and URL.
+		if !strings.HasPrefix(field, "-") || len(args) > 0 {
+			args = append(args, field)
+			continue
+		}
+		qualifier, value, hasValue := strings.Cut(strings.ToLower(field), "=")
+		if seen[qualifier] {
+			return u, fmt.Errorf("%q qualifier is repeated", field)
+		}
+		seen[qualifier] = true
+		switch qualifier {
+		case "-refresh":
+			u.Refresh = true
+		case "-redirect":
+			u.Redirect = true
+		case "-append":
+			u.Append = true
+		case "-encoded":
+			u.Encoded = true
+		case "-rewritehost":
+			u.RewriteHost = true
+		case "-form":
+			if !hasValue || value == "" || strings.ContainsFunc(value, func(r rune) bool { return r < 'a' || r > 'z' }) {
+				return u, errors.New("-Form requires a method, like -Form=post")
+			}
+			u.Form = value
+			continue
+		default:
+			return u, fmt.Errorf("%q is not a known qualifier", field)
+		}
+		if hasValue {
+			return u, fmt.Errorf("%q qualifier does not take a value", field)
+		}
+	}
+	switch {
+	case u.Append && !u.Encoded:
+		return u, errors.New("-Append requires -Encoded")
+	case u.Encoded && !u.Append:
+		return u, errors.New("-Encoded requires -Append")
+	case u.RewriteHost && u.Form == "":
+		return u, errors.New("-RewriteHost requires -Form")
+	case u.Form != "" && (u.Refresh || u.Redirect || u.Append):
+		return u, errors.New("-Form can not be used with -Refresh, -Redirect, or -Append")
+	}
+	qualified := len(seen) > 0
+	switch {
+	case len(args) == 0:
+		return u, errors.New("missing the starting point URL")
+	case len(args) == 1 && qualified:
+		return u, errors.New("qualifiers require a name before the starting point URL")
+	case len(args) == 1:
+		u.URL = args[0]
+	case len(args) == 2:
+		u.Name, u.URL = args[0], args[1]
+	default:
+		return u, fmt.Errorf("unexpected arguments %q", strings.Join(args[:len(args)-2], " "))
+	}
+	return u, nil
+}
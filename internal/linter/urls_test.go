@@ -0,0 +1,48 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"testing"
+)
+
+func TestParseURLDirective(t *testing.T) {
+	var tests = []struct {
+		line     string
+		expected URLDirective
+		err      string
+	}{
+		{"URL https://www.somedb.com", URLDirective{URL: "https://www.somedb.com"}, ""},
+		{"url  somedb\thttps://www.somedb.com", URLDirective{Name: "somedb", URL: "https://www.somedb.com"}, ""},
+		{"U -Encoded -Append -Redirect otherdb https://www.otherdb.com/search?q=",
+			URLDirective{Redirect: true, Append: true, Encoded: true, Name: "otherdb", URL: "https://www.otherdb.com/search?q="}, ""},
+		{"URL -RewriteHost -Form=POST somedb https://www.somedb.com/login.asp",
+			URLDirective{RewriteHost: true, Form: "post", Name: "somedb", URL: "https://www.somedb.com/login.asp"}, ""},
+		{"URL", URLDirective{}, "missing the starting point URL"},
+		{"URL -Append otherdb https://www.otherdb.com", URLDirective{}, "-Append requires -Encoded"},
+		{"URL -Encoded otherdb https://www.otherdb.com", URLDirective{}, "-Encoded requires -Append"},
+		{"URL -RewriteHost somedb https://www.somedb.com", URLDirective{}, "-RewriteHost requires -Form"},
+		{"URL -Form somedb https://www.somedb.com", URLDirective{}, "-Form requires a method, like -Form=post"},
+		{"URL -Form=post -Refresh somedb https://www.somedb.com", URLDirective{}, "-Form can not be used with -Refresh, -Redirect, or -Append"},
+		{"URL -Refresh -Refresh somedb https://www.somedb.com", URLDirective{}, "\"-Refresh\" qualifier is repeated"},
+		{"URL -Refresh=true somedb https://www.somedb.com", URLDirective{}, "\"-Refresh=true\" qualifier does not take a value"},
+		{"URL -Reload somedb https://www.somedb.com", URLDirective{}, "\"-Reload\" is not a known qualifier"},
+		{"URL -Refresh https://www.somedb.com", URLDirective{}, "qualifiers require a name before the starting point URL"},
+		{"URL some db https://www.somedb.com", URLDirective{}, "unexpected arguments \"some\""},
+	}
+
+	for _, tt := range tests {
+		u, err := ParseURLDirective(tt.line)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("ParseURLDirective() fails on %q, wanted error %q, got %v.\n", tt.line, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || u != tt.expected {
+			t.Fatalf("ParseURLDirective() fails on %q, wanted %+v, got %+v, %v.\n", tt.line, tt.expected, u, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// PlaceholderRegex matches the template placeholders in generated configs, like "{{INSTITUTION_DOMAIN}}" or "${INSTITUTION_DOMAIN}".
+// The first or second submatch is the name of the variable.
+var PlaceholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReadValues reads the values of template variables from a JSON object, like {"INSTITUTION_DOMAIN": "example.edu"}.
+// Values can't have line breaks, so the lines of a rendered config keep their line numbers.
+func ReadValues(path string) (values map[string]string, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("unable to read values %v: %w", path, err)
+	}
+	for name, value := range values {
+		if !variableNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("%q in values %v isn't a valid variable name", name, path)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("the value of %v in values %v can't have a line break", name, path)
+		}
+	}
+	if values == nil {
+		values = map[string]string{}
+	}
+	return values, nil
+}
+
+// placeholderName returns the name of the variable in a placeholder.
+func placeholderName(placeholder string) string {
+	match := PlaceholderRegex.FindStringSubmatch(placeholder)
+	if match[1] != "" {
+		return match[1]
+	}
+	return match[2]
+}
+
+// Render replaces the placeholders in the content with their values.
+// Placeholders without a value are left as they are, so they can be reported.
+func Render(content []byte, values map[string]string) []byte {
+	return PlaceholderRegex.ReplaceAllFunc(content, func(placeholder []byte) []byte {
+		if value, ok := values[placeholderName(string(placeholder))]; ok {
+			return []byte(value)
+		}
+		return placeholder
+	})
+}
+
+// PlaceholderCheck reports the placeholders left in a rendered line, which don't have a value.
+func (l *Linter) PlaceholderCheck(line string) (m []string) {
+	if l.Values == nil || strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return m
+	}
+	for _, placeholder := range PlaceholderRegex.FindAllString(line, -1) {
+		m = append(m, fmt.Sprintf("Template placeholder %v doesn't have a value in the values file (L9014)", placeholder))
+	}
+	return m
+}
+
+// renderFile renders the content of a file if the linter has values for template variables.
+func (l *Linter) renderFile(content []byte) []byte {
+	if l.Values == nil || !bytes.Contains(content, []byte("{")) {
+		return content
+	}
+	return Render(content, l.Values)
+}
@@ -0,0 +1,89 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadValues(t *testing.T) {
+	dir := t.TempDir()
+	var tests = []struct {
+		content string
+		valid   bool
+	}{
+		{`{"INSTITUTION_DOMAIN": "example.edu"}`, true},
+		{`{}`, true},
+		{`{"INSTITUTION-DOMAIN": "example.edu"}`, false},
+		{`{"NOTE": "two\nlines"}`, false},
+		{`{"PORT": 443}`, false},
+		{`not json`, false},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "values.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadValues(path); (err == nil) != tt.valid {
+			t.Fatalf("test %v: unexpected error value %v", i, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	values := map[string]string{"DOMAIN": "example.edu", "EMPTY": ""}
+	var tests = []struct {
+		content  string
+		expected string
+	}{
+		{"URL https://{{DOMAIN}}/start", "URL https://example.edu/start"},
+		{"URL https://{{ DOMAIN }}/start", "URL https://example.edu/start"},
+		{"Domain ${DOMAIN}", "Domain example.edu"},
+		{"Host ${MISSING}{{EMPTY}}", "Host ${MISSING}"},
+		{"Find {x}", "Find {x}"},
+	}
+	for _, tt := range tests {
+		if rendered := string(Render([]byte(tt.content), values)); rendered != tt.expected {
+			t.Fatalf("incorrect rendering %q instead of %q for %q", rendered, tt.expected, tt.content)
+		}
+	}
+}
+
+func TestPlaceholderCheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	content := "Title Example\nURL https://{{DOMAIN}}/start\ndomain {{DOMAIN}}\n# Host {{COMMENTED}}\nHost ${MISSING}.example.edu\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linter := &Linter{Format: FormatJSON, Output: io.Discard, Fix: true, DirectiveCase: true, Values: map[string]string{"DOMAIN": "example.edu"}}
+	if _, err := linter.ProcessFile(path); err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, f := range linter.Report.Findings {
+		messages = append(messages, f.Message)
+	}
+	expected := []string{
+		"\"domain\" directive does not have the right letter casing. It should be replaced by \"Domain\" (L5001)",
+		"Template placeholder ${MISSING} doesn't have a value in the values file (L9014)",
+		"Unable to parse URL, might be malformed: parse \"http://${MISSING}.example.edu\": invalid character \"{\" in host name (L3005)",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+	// The template is not rewritten with the values.
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != content {
+		t.Fatalf("template was rewritten to %q", written)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Vendors returns the domains of vendor platforms which are commonly proxied by several stanzas,
+// one for each product, when OCLC provides a consolidated stanza for the whole platform.
+func Vendors() map[string][]string {
+	return map[string][]string{
+		"Clarivate": {"clarivate.com", "webofknowledge.com", "webofscience.com"},
+		"EBSCO":     {"ebsco.com", "ebscohost.com"},
+		"Elsevier":  {"elsevier.com", "engineeringvillage.com", "sciencedirect.com", "scopus.com"},
+		"ProQuest":  {"proquest.com"},
+	}
+}
+
+// HostVendor returns the vendor whose platform the host is part of, or an empty string if the host isn't known.
+func HostVendor(host string) string {
+	for vendor, domains := range Vendors() {
+		for _, domain := range domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return vendor
+			}
+		}
+	}
+	return ""
+}
+
+// VendorChecks reports on the stanza which just ended if it proxies part of a vendor platform which
+// an earlier stanza also proxies. The stanzas can usually be replaced by OCLC's consolidated stanza for the vendor.
+// Each vendor is reported once for each stanza, with the location of the first stanza which proxied it.
+// Hosts in the SharedOrigins setting are skipped.
+func (l *Linter) VendorChecks() (m []string) {
+	var hosts []HostLine
+	if u, err := url.Parse(l.State.URLOrigin); err == nil && l.State.URLOrigin != "" {
+		hosts = append(hosts, HostLine{Directive: URL, Scheme: u.Scheme, Host: strings.ToLower(u.Hostname()), Port: u.Port(), At: l.State.URLAt})
+	}
+	hosts = append(hosts, l.State.HostLines...)
+	reported := map[string]bool{}
+	for _, h := range hosts {
+		vendor := HostVendor(h.Host)
+		shared := l.Config.SharedHost(h.Host) || (h.Scheme != "" && l.Config.SharedOrigin(hostLineOrigin(h)))
+		if vendor == "" || reported[vendor] || shared {
+			continue
+		}
+		reported[vendor] = true
+		seenAt, seen := l.PreviousVendors.Seen(l.SeenGroup(), vendor)
+		if !seen {
+			l.PreviousVendors.Add(l.SeenGroup(), vendor, h.At)
+			continue
+		}
+		m = append(m, fmt.Sprintf("Stanza %q proxies the %v platform, like the stanza at %q. Consider replacing them "+
+			"with OCLC's consolidated %v stanza (L2014)", cmp.Or(l.State.Title, l.State.URL), vendor, seenAt, vendor))
+	}
+	return m
+}
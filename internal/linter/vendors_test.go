@@ -0,0 +1,52 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestHostVendor(t *testing.T) {
+	for host, expected := range map[string]string{
+		"www.sciencedirect.com":     "Elsevier",
+		"scopus.com":                "Elsevier",
+		"search.ebscohost.com":      "EBSCO",
+		"www.webofscience.com":      "Clarivate",
+		"notproquest.com":           "",
+		"www.jstor.org":             "",
+		"ebookcentral.proquest.com": "ProQuest",
+	} {
+		if vendor := HostVendor(host); vendor != expected {
+			t.Fatalf("incorrect vendor %q instead of %q for %q", vendor, expected, host)
+		}
+	}
+}
+
+func TestVendorChecks(t *testing.T) {
+	lines := []string{
+		"Title ScienceDirect", "URL https://www.sciencedirect.com", "",
+		"Title JSTOR", "URL https://www.jstor.org", "",
+		"Title Scopus", "URL https://www.scopus.com", "Domain elsevier.com", "",
+		"Title Engineering Village", "URL https://www.engineeringvillage.com", "",
+	}
+	expected := []string{
+		"Stanza \"Scopus\" proxies the Elsevier platform, like the stanza at \"test:2\". Consider replacing them with OCLC's consolidated Elsevier stanza (L2014)",
+		"Stanza \"Engineering Village\" proxies the Elsevier platform, like the stanza at \"test:2\". Consider replacing them with OCLC's consolidated Elsevier stanza (L2014)",
+	}
+	linter := Linter{Pedantic: true}
+	var messages []string
+	for i, line := range lines {
+		for _, message := range Messages(linter.ProcessLineAt(line, "test:"+strconv.Itoa(i+1))) {
+			if MessageCode(message) == "L2014" {
+				messages = append(messages, message)
+			}
+		}
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("incorrect messages %q instead of %q", messages, expected)
+	}
+}
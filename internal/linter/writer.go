@@ -0,0 +1,132 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// A FindingWriter receives the findings and errors as the linter finds them.
+// The linter has writers for the text, JSON, and SARIF formats, and programs which use the linter
+// can set the linter's Writer to send findings somewhere else, like a database or a message queue.
+type FindingWriter interface {
+	// WriteFindings writes the findings for one line. The findings apply to the whole stanza
+	// or file which ended at their location when their Text is empty.
+	WriteFindings(findings []Finding) error
+	// WriteError writes an error which stopped the linter from processing a file.
+	WriteError(e ProcessingError) error
+	// Close writes anything the writer holds until every file has been processed.
+	Close() error
+}
+
+// A TextWriter writes findings as text, as they are found. The findings for a line are printed after the line,
+// and security issues are printed more prominently than other findings.
+// If Documentation is set, the link to the documentation for the line's directive is printed after its findings.
+type TextWriter struct {
+	Output        io.Writer
+	Documentation bool
+}
+
+// WriteFindings prints the findings for one line.
+func (w *TextWriter) WriteFindings(findings []Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	f := findings[0]
+	at := f.File
+	if f.Line > 0 {
+		at = fmt.Sprintf("%v:%v", f.File, f.Line)
+	}
+	if f.Security {
+		for _, f := range findings {
+			if _, err := fmt.Fprintf(w.Output, "%v: %v\n", at, color.New(color.FgRed, color.Bold).Sprintf("⚠ %v%v", f.Message, f.Repeats())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var messages []string
+	for _, f := range findings {
+		messages = append(messages, f.Message+f.Repeats())
+	}
+	var err error
+	if f.Text == "" {
+		_, err = fmt.Fprintf(w.Output, "%v: %v\n", at, color.YellowString(fmt.Sprintf("↑ %v", strings.Join(messages, ", "))))
+	} else {
+		_, err = fmt.Fprintf(w.Output, "%v: %v %v\n", at, f.Text, color.YellowString(fmt.Sprintf("← %v", strings.Join(messages, ", "))))
+	}
+	if err == nil && w.Documentation && f.Documentation != "" {
+		_, err = fmt.Fprintf(w.Output, "%v: %v\n", at, color.CyanString(fmt.Sprintf("See %v", f.Documentation)))
+	}
+	return err
+}
+
+// WriteError prints an error which stopped the linter from processing a file.
+func (w *TextWriter) WriteError(e ProcessingError) error {
+	_, err := fmt.Fprintf(w.Output, "%v: %v\n", e.File, color.RedString(fmt.Sprintf("Error processing file: %v", e.Message)))
+	return err
+}
+
+// Close does nothing, since the text format is printed as files are processed.
+func (w *TextWriter) Close() error {
+	return nil
+}
+
+// A ReportWriter collects the findings and errors in a Report,
+// and writes the report in the JSON or SARIF format when it is closed.
+type ReportWriter struct {
+	Format   string
+	Output   io.Writer
+	Report   *Report
+	Metadata *Metadata
+}
+
+// WriteFindings adds the findings to the report.
+func (w *ReportWriter) WriteFindings(findings []Finding) error {
+	w.Report.Findings = append(w.Report.Findings, findings...)
+	return nil
+}
+
+// WriteError adds the error to the report.
+func (w *ReportWriter) WriteError(e ProcessingError) error {
+	w.Report.Errors = append(w.Report.Errors, e)
+	return nil
+}
+
+// Close writes the report.
+func (w *ReportWriter) Close() error {
+	if w.Metadata != nil {
+		w.Metadata.Finish()
+	}
+	// Use empty lists instead of nulls, to make the output easier to consume.
+	r := Report{Metadata: w.Metadata, Findings: []Finding{}, Errors: []ProcessingError{}}
+	r.Findings = append(r.Findings, w.Report.Findings...)
+	r.Errors = append(r.Errors, w.Report.Errors...)
+	var report any = r
+	if w.Format == FormatSARIF {
+		report = r.SARIF()
+	}
+	encoder := json.NewEncoder(w.Output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// FindingWriter returns the writer findings are sent to. If the Writer isn't set,
+// a writer for the linter's Format is made, which writes to the linter's Output.
+// In verbose mode, the text format links to the documentation for each directive with findings.
+func (l *Linter) FindingWriter() FindingWriter {
+	if l.Writer == nil {
+		if l.Structured() {
+			l.Writer = &ReportWriter{Format: l.Format, Output: l.Output, Report: &l.Report, Metadata: l.Metadata}
+		} else {
+			l.Writer = &TextWriter{Output: l.Output, Documentation: l.Verbose}
+		}
+	}
+	return l.Writer
+}
@@ -0,0 +1,94 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// recordingWriter is a FindingWriter which keeps what it receives.
+type recordingWriter struct {
+	lines  [][]Finding
+	errors []ProcessingError
+	closed bool
+}
+
+func (w *recordingWriter) WriteFindings(findings []Finding) error {
+	w.lines = append(w.lines, findings)
+	return nil
+}
+
+func (w *recordingWriter) WriteError(e ProcessingError) error {
+	w.errors = append(w.errors, e)
+	return nil
+}
+
+func (w *recordingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestFindingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(path, []byte("Title Example\nURL https://www.example.com\nH https://www.example.com:80\nUnknownDirective\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writer := &recordingWriter{}
+	linter := Linter{Writer: writer}
+	count, err := linter.ProcessFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter.ReportError("missing.txt", errors.New("file not found"))
+	if err := linter.WriteReport(); err != nil {
+		t.Fatal(err)
+	}
+	findings := 0
+	for _, line := range writer.lines {
+		findings += len(line)
+		for _, f := range line {
+			if f.File != path || f.Stanza != "Example" || f.Code == "" {
+				t.Fatalf("incorrect finding %+v", f)
+			}
+		}
+	}
+	if findings != count || len(writer.lines) != 2 || len(writer.errors) != 1 || !writer.closed {
+		t.Fatalf("writer received %v findings on %v lines, %v errors, and closed %v, instead of %v findings on 2 lines, 1 error, and closed",
+			findings, len(writer.lines), len(writer.errors), writer.closed, count)
+	}
+}
+
+func TestTextWriter(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	var output bytes.Buffer
+	w := &TextWriter{Output: &output}
+	writes := []error{
+		w.WriteFindings([]Finding{{File: "config.txt", Line: 2, Text: "URL x", Message: "First (L9001)"}, {File: "config.txt", Line: 2, Text: "URL x", Message: "Second (L2001)"}}),
+		w.WriteFindings([]Finding{{File: "config.txt", Line: 4, Message: "Stanza (L4001)"}}),
+		w.WriteFindings([]Finding{{File: "config.txt", Line: 1, Message: "Threat (L9006)", Security: true}}),
+		w.WriteFindings(nil),
+		w.WriteError(ProcessingError{File: "missing.txt", Message: "file not found"}),
+		w.Close(),
+	}
+	if err := errors.Join(writes...); err != nil {
+		t.Fatal(err)
+	}
+	expected := "config.txt:2: URL x ← First (L9001), Second (L2001)\n" +
+		"config.txt:4: ↑ Stanza (L4001)\n" +
+		"config.txt:1: ⚠ Threat (L9006)\n" +
+		"missing.txt: Error processing file: file not found\n"
+	if output.String() != expected {
+		t.Fatalf("incorrect output %q instead of %q", output.String(), expected)
+	}
+}
@@ -5,11 +5,18 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"maps"
+	"net/http"
 	"os"
 	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cu-library/ezproxy-config-lint/internal/linter"
 )
@@ -21,19 +28,64 @@ const (
 	Error              // Linting was unsuccessful.
 )
 
+// A headerFlag collects the headers from repeated -header flags.
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	var headers []string
+	for name, values := range h {
+		for _, value := range values {
+			headers = append(headers, name+": "+value)
+		}
+	}
+	return strings.Join(headers, ", ")
+}
+
+func (h headerFlag) Set(header string) error {
+	name, value, found := strings.Cut(header, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header %q should be in the form \"Name: value\"", header)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
 // A version flag, which should be overwritten when building using ldflags.
 var version = "devel"
 
 func main() {
-	annotate := flag.Bool("annotate", false, "Print all lines, not just lines that create warnings.")
-	verbose := flag.Bool("verbose", false, "Print internal state before each line is processed.")
-	additionalPHEChecks := flag.Bool("phe", false, "Perform additional checks on ProxyHostnameEdit directives.")
-	directiveCase := flag.Bool("case", false, "Report on directives having the wrong case.")
-	https := flag.Bool("https", false, "Report on URL directives which do not use the HTTPS scheme.")
-	origins := flag.Bool("origins", false, "Report on duplicate origins in H or HJ directives within a stanza.")
-	source := flag.Bool("source", true, "Use source comments to check against OCLC stanzas.")
-	whitespace := flag.Bool("whitespace", false, "Report on trailing space or tab characters.")
-	followIncludeFile := flag.Bool("follow-includefile", true, "Also process files referenced by IncludeFile directives.")
+	// Subcommands are given before the options, like "ezproxy-config-lint snapshot config.txt".
+	command := ""
+	if len(os.Args) > 1 && slices.Contains(commands(), os.Args[1]) {
+		command = os.Args[1]
+		os.Args = slices.Delete(os.Args, 1, 2)
+	}
+
+	// The options used to make a Linter, which each root of a manifest can set.
+	options := linterOptions{}
+	options.addFlags(flag.CommandLine)
+	progress := flag.Bool("progress", true, "Print a status line to standard error every few seconds during long runs, when standard error is a terminal.")
+	profile := flag.Bool("profile", false, "Print the time spent on each file and in each section of the linter to standard error.")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile of the run to this file, for use with \"go tool pprof\".")
+	proxyPrefix := flag.String("proxy-prefix", "", "The EZproxy server's URL, like \"https://proxy.example.edu\", which the starting-points command puts in front of each stanza's URL.")
+	format := flag.String("format", linter.FormatText, "The output format, one of "+strings.Join(linter.Formats(), ", ")+".")
+	headers := headerFlag{}
+	flag.Var(headers, "header", "An extra header sent with network requests, like \"From: admin@library.example.edu\". Can be repeated.")
+	reportFile := flag.String("report-file", "", "Write a summary of the run's findings, with the time of the run, to this file, for periodic audits.")
+	appendHistory := flag.String("append-history", "", "Add a line with a summary of the run's findings, with the time of the run, to this JSON Lines file, to track trends over time.")
+	manifestFile := flag.String("manifest", "", "A JSON file listing config roots, each with its own files and options, which are linted in one run.")
+	configFile := flag.String("config", "", "A JSON file with detailed settings, like a template for stanza header comments.")
+	cacheDir := flag.String("cache", "", "Cache the issues found in files which do not include other files in this directory, "+
+		"and skip those files in later runs if they and the files before them have not changed.")
+	exitCodeIssues := flag.Int("exit-code-issues", Failure, "The exit code used when issues are found.")
+	exitCodeError := flag.Int("exit-code-error", Error, "The exit code used when the linter experiences an error and can not continue.")
+	snapshotFile := flag.String("snapshot-file", "snapshot.json", "The file the snapshot command records the current issues in.")
+	against := flag.String("against", "snapshot.json", "The snapshot file the check and gate commands compare the current issues against.")
+	maxNewErrors := flag.Int("max-new-errors", 0, "The most new errors, compared to the -against snapshot, which pass the gate command. Use -1 for no limit.")
+	maxNewWarnings := flag.Int("max-new-warnings", linter.NoLimit, "The most new warnings, compared to the -against snapshot, which pass the gate command. Use -1 for no limit.")
+	showSuppressed := flag.Bool("show-suppressed", false, "List the issues the check command does not report because they are in the snapshot file.")
+	originIndex := flag.String("origin-index", "", "Write an index of the origins claimed by each stanza to this file, as CSV if the file name ends in \".csv\", and JSON otherwise.")
+	rulesJSON := flag.Bool("rules-json", false, "Print the code, title, category, severity, and fix availability of every check as JSON, then exit.")
 	includeFileDirectory := flag.String("includefile-directory", "", "The directory from which the IncludeFile paths will be resolved. "+
 		"By default, IncludeFile paths are resolved from the parent directory of each of the file arguments, unless they are absolute paths.")
 	flag.Usage = func() {
@@ -41,6 +93,19 @@ func main() {
 		fmt.Fprintf(flag.CommandLine.Output(), "  Version %v\n", version)
 		fmt.Fprintf(flag.CommandLine.Output(), "  Compiled with %v\n", runtime.Version())
 		fmt.Fprint(flag.CommandLine.Output(), "Usage:\n  ezproxy-config-lint [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint snapshot [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint check -against snapshot.json [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint gate -against snapshot.json [-max-new-errors n] [-max-new-warnings n] [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint explain [options] <file> <line|title>\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint grep [options] <directive> <pattern> <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint affects [options] <hostname> <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint match [options] <url> <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint starting-points -proxy-prefix <url> [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint https-report [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint source-report [options] <file>...\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint drift-report [options] <-manifest manifest.json | <file> <file>...>\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint revert <-backup-dir dir | backup>\n")
+		fmt.Fprint(flag.CommandLine.Output(), "  ezproxy-config-lint -manifest manifest.json [options]\n")
 		fmt.Fprint(flag.CommandLine.Output(), "Options:\n")
 		flag.PrintDefaults()
 	}
@@ -51,51 +116,364 @@ func main() {
 	// Set the logger to not include timestamp.
 	log.SetFlags(0)
 
-	// Create a Linter struct to hold configuration options.
-	linter := &linter.Linter{
-		Annotate:             *annotate,
-		Verbose:              *verbose,
-		AdditionalPHEChecks:  *additionalPHEChecks,
-		DirectiveCase:        *directiveCase,
-		HTTPS:                *https,
-		Origins:              *origins,
-		Source:               *source,
-		Whitespace:           *whitespace,
-		FollowIncludeFile:    *followIncludeFile,
-		IncludeFileDirectory: *includeFileDirectory,
-		Output:               os.Stdout,
-	}
-
-	warningCount := 0
-
-	for _, arg := range flag.Args() {
-		fileWarningCount, err := linter.ProcessFile(arg)
+	for _, code := range []int{*exitCodeIssues, *exitCodeError} {
+		if code < 0 || code > 255 {
+			log.Printf("Exit code %v is not valid, should be between 0 and 255", code)
+			exit(Error)
+		}
+	}
+
+	// The gate command's thresholds, made before the linter package is shadowed.
+	gateThresholds := linter.GateThresholds{MaxNewErrors: *maxNewErrors, MaxNewWarnings: *maxNewWarnings}
+	if err := gateThresholds.Validate(); err != nil {
+		log.Printf("Invalid gate threshold: %v", err)
+		exit(*exitCodeError)
+	}
+
+	if !slices.Contains(linter.Formats(), *format) {
+		log.Printf("Unknown output format %q, should be one of %v", *format, strings.Join(linter.Formats(), ", "))
+		exit(*exitCodeError)
+	}
+
+	if err := options.validate(*format); err != nil {
+		log.Printf("Invalid options: %v", err)
+		exit(*exitCodeError)
+	}
+
+	// Print the rule registry for other tools, like docs generators, then exit.
+	if *rulesJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(linter.Rules()); err != nil {
+			log.Printf("Error printing rules: %v", err)
+			exit(*exitCodeError)
+		}
+		return
+	}
+
+	// Print a stanza as EZproxy would see it, then exit.
+	if command == "explain" {
+		if flag.NArg() < 2 {
+			log.Printf("The explain command needs a file and a line number or title, like \"explain config.txt 12\"")
+			exit(*exitCodeError)
+		}
+		explainStanza(flag.Arg(0), strings.Join(flag.Args()[1:], " "), *includeFileDirectory, *exitCodeError)
+		return
+	}
+
+	// Print the lines with a directive whose arguments match a pattern, then exit.
+	if command == "grep" {
+		if flag.NArg() < 3 {
+			log.Printf("The grep command needs a directive, a pattern, and files, like \"grep Domain *.elsevier.com config.txt\"")
+			exit(*exitCodeError)
+		}
+		searchFiles(flag.Arg(0), flag.Arg(1), flag.Args()[2:], *includeFileDirectory, *format, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	// Print the stanzas with lines which match a hostname, then exit.
+	if command == "affects" {
+		if flag.NArg() < 2 {
+			log.Printf("The affects command needs a hostname and files, like \"affects www.sciencedirect.com config.txt\"")
+			exit(*exitCodeError)
+		}
+		reportAffectedStanzas(flag.Arg(0), flag.Args()[1:], *includeFileDirectory, *format, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	// Print the stanza which would proxy a URL, then exit.
+	if command == "match" {
+		if flag.NArg() < 2 {
+			log.Printf("The match command needs a URL and files, like \"match https://www.jstor.org/stable/123 config.txt\"")
+			exit(*exitCodeError)
+		}
+		matchURL(flag.Arg(0), flag.Args()[1:], *includeFileDirectory, *format, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	// Print the proxied starting point URL of each stanza, then exit.
+	if command == "starting-points" {
+		if *proxyPrefix == "" {
+			log.Printf("The starting-points command needs the EZproxy server's URL, like \"-proxy-prefix https://proxy.example.edu\"")
+			exit(*exitCodeError)
+		}
+		listStartingPoints(*proxyPrefix, flag.Args(), *includeFileDirectory, *format, *exitCodeError)
+		return
+	}
+
+	// Print the stanzas which don't use HTTPS starting point URLs, then exit.
+	if command == "https-report" {
+		reportHTTPStanzas(flag.Args(), *includeFileDirectory, *format, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	// Print the stanzas which don't have Source comments, then exit.
+	if command == "source-report" {
+		reportUnsourcedStanzas(flag.Args(), *includeFileDirectory, *format, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	// Print the stanzas which differ between the tenants of a consortium, then exit.
+	if command == "drift-report" {
+		if *manifestFile == "" && flag.NArg() < 2 {
+			log.Printf("The drift-report command needs a manifest or at least two files, like \"drift-report main/config.txt law/config.txt\"")
+			exit(*exitCodeError)
+		}
+		reportDrift(*manifestFile, flag.Args(), *includeFileDirectory, *format, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	// Restore the files rewritten by a run of -fix, then exit.
+	if command == "revert" {
+		if options.backupDir == "" && flag.NArg() != 1 {
+			log.Printf("The revert command needs the backup directory or a backup, like \"revert -backup-dir backups\"")
+			exit(*exitCodeError)
+		}
+		revertBackup(options.backupDir, flag.Arg(0), *exitCodeError)
+		return
+	}
+
+	// Read the settings which are too detailed for flags.
+	config := linter.Config{}
+	if *configFile != "" {
+		var err error
+		config, err = linter.ReadConfig(*configFile)
 		if err != nil {
-			log.Printf("Error processing %v: %v", arg, err)
-			os.Exit(Error)
-		}
-		warningCount += fileWarningCount
-		// ProcessFile() recursively processes files referenced
-		// by IncludeFile directives.
-		// If includeFileDirectory is not set by a CLI option,
-		// ProcessFile() will set the linter's IncludeFileDirectory
-		// to the parent directory of the first file is processes.
-		// That is done because IncludeFile directives are processed
-		// as though they were in the file that was processed first.
-		// There might be multiple files passed as CLI arguments,
-		// which might not be in the same parent directory.
-		// The IncludeFileDirectory is reset here so that it does not
-		// potentially remain set to the parent directory of the first
-		// filePath in the argument list.
-		linter.IncludeFileDirectory = *includeFileDirectory
+			log.Printf("Error reading config: %v", err)
+			exit(*exitCodeError)
+		}
+	}
+
+	// The snapshot and check commands collect the issues instead of printing them.
+	outputFormat := *format
+	if command != "" {
+		outputFormat = linter.FormatJSON
+		options.annotate = false
 	}
 
+	// Time the files and sections of the linter, and write a CPU profile, if asked.
+	var timings *linter.Profile
+	if *profile {
+		timings = linter.NewProfile()
+	}
+	if *cpuProfile != "" {
+		startCPUProfile(*cpuProfile, *exitCodeError)
+	}
+
+	// Let users know long runs haven't hung. Scripts, like CI jobs and the gate command, don't get a status line.
+	var status *linter.Progress
+	if *progress && command != "gate" && isTerminal(os.Stderr) {
+		status = linter.NewProgress(os.Stderr)
+	}
+
+	// Map every origin to the stanza which claims it, for questions from staff about why a site is proxied.
+	if *originIndex != "" {
+		writeOriginIndex(*originIndex, flag.Args(), *includeFileDirectory, *exitCodeError)
+	}
+
+	// Make a Linter struct to hold configuration options. Each root of a manifest has its own linter,
+	// made with the root's options.
+	newLinter := func(options linterOptions, config linter.Config, includeFileDirectory string) (*linter.Linter, error) {
+		// An empty fail fast severity means processing does not stop at the first issue.
+		failFastAt := linter.Severity("")
+		if options.failFast {
+			failFastAt = linter.Severity(options.failFastSeverity)
+		}
+
+		// Describe the run in the structured output formats.
+		metadata := linter.NewMetadata("ezproxy-config-lint", version, flag.CommandLine, config)
+		maps.Copy(metadata.Flags, options.set)
+
+		// Render templated configs before linting them.
+		var values map[string]string
+		if options.valuesFile != "" {
+			var err error
+			values, err = linter.ReadValues(options.valuesFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading values: %w", err)
+			}
+		}
+
+		// Skip unchanged files using the cache.
+		var cache *linter.Cache
+		if *cacheDir != "" {
+			var err error
+			cache, err = linter.NewCache(*cacheDir, metadata)
+			if err != nil {
+				return nil, fmt.Errorf("error creating cache: %w", err)
+			}
+		}
+
+		l := &linter.Linter{
+			Annotate:             options.annotate,
+			Highlight:            options.highlight,
+			Verbose:              options.verbose,
+			AdditionalPHEChecks:  options.additionalPHEChecks,
+			DirectiveCase:        options.directiveCase,
+			LabelStyle:           options.labelStyle,
+			HTTPS:                options.https,
+			Origins:              options.origins,
+			Source:               options.source,
+			SourceStrict:         options.sourceStrict,
+			SourceCompare:        options.sourceCompare,
+			Whitespace:           options.whitespace,
+			FileReferences:       options.fileReferences,
+			ServerConfig:         options.serverConfig,
+			RedundantHosts:       options.redundantHosts,
+			CrossSchemeOrigins:   options.crossSchemeOrigins,
+			ImplicitBoundaries:   options.implicitBoundaries,
+			DomainHosts:          options.domainHosts,
+			SkeletonStanzas:      options.skeletonStanzas,
+			ServerHostname:       options.serverHostname,
+			GroupScoped:          options.groupScoped,
+			Pedantic:             options.pedantic,
+			MaxStanzaHosts:       options.maxStanzaHosts,
+			MaxDescriptionLength: options.maxDescriptionLength,
+			MaxFileStanzas:       options.maxFileStanzas,
+			MaxFileLines:         options.maxFileLines,
+			OneStanzaPerFile:     options.oneStanzaPerFile,
+			Fix:                  options.fix,
+			Diff:                 options.diff,
+			BackupDir:            options.backupDir,
+			Format:               outputFormat,
+			FailFast:             failFastAt,
+			MinCategory:          linter.Category(options.minCategory),
+			Timeout:              options.timeout,
+			Retries:              options.retries,
+			UserAgent:            options.userAgent,
+			Headers:              http.Header(headers),
+			CommunityRepo:        options.communityRepo,
+			Config:               config,
+			Values:               values,
+			StaleAfter:           time.Duration(options.staleDays) * 24 * time.Hour,
+			FollowIncludeFile:    options.followIncludeFile,
+			IncludeFileDirectory: includeFileDirectory,
+			Metadata:             metadata,
+			Profile:              timings,
+			Cache:                cache,
+			Progress:             status,
+			Output:               os.Stdout,
+		}
+		// Snapshots compare each finding, so the snapshot, check, and gate commands don't collapse findings.
+		if options.collapse && command != "snapshot" && command != "check" && command != "gate" {
+			l.Writer = linter.NewCollapseWriter(l.FindingWriter())
+		}
+		// Count the findings for the periodic audit files, before they are collapsed.
+		if *reportFile != "" || *appendHistory != "" {
+			l.Writer = linter.NewSummaryWriter(l.FindingWriter())
+		}
+		return l, nil
+	}
+
+	// Lint each root of the manifest with its own options, then exit.
+	if *manifestFile != "" {
+		if command != "" || flag.NArg() > 0 {
+			log.Printf("The -manifest option lists the files to lint, and can't be used with a command or files")
+			exit(*exitCodeError)
+		}
+		if *reportFile != "" || *appendHistory != "" {
+			log.Printf("The -report-file and -append-history options can't be used with the -manifest option")
+			exit(*exitCodeError)
+		}
+		warningCount, failed := lintManifest(*manifestFile, newLinter, options, config, outputFormat, *exitCodeError)
+		pprof.StopCPUProfile()
+		timings.Write(os.Stderr)
+		if failed {
+			exit(*exitCodeError)
+		}
+		if warningCount > 0 {
+			exit(*exitCodeIssues)
+		}
+		return
+	}
+
+	linter, err := newLinter(options, config, *includeFileDirectory)
+	if err != nil {
+		log.Printf("Error creating linter: %v", err)
+		exit(*exitCodeError)
+	}
+
+	warningCount, failedFile, err := lintFiles(linter, flag.Args(), *includeFileDirectory)
+	if err != nil {
+		if command != "" {
+			log.Printf("Error processing file: %v", err)
+			exit(*exitCodeError)
+		}
+		linter.ReportError(failedFile, err)
+		writeReport(linter, *exitCodeError)
+		writeSummary(linter, *reportFile, *appendHistory, flag.Args(), *exitCodeError)
+		exit(*exitCodeError)
+	}
+
+	// Record the findings for periodic audits, whichever way they are reported.
+	writeSummary(linter, *reportFile, *appendHistory, flag.Args(), *exitCodeError)
+
+	pprof.StopCPUProfile()
+	timings.Write(os.Stderr)
+
+	switch command {
+	case "snapshot":
+		recordSnapshot(linter, *snapshotFile, *exitCodeError)
+		return
+	case "check":
+		checkSnapshot(linter, *against, *showSuppressed, *exitCodeIssues, *exitCodeError)
+		return
+	case "gate":
+		runGate(linter, *against, gateThresholds, *exitCodeIssues, *exitCodeError)
+		return
+	}
+
+	writeReport(linter, *exitCodeError)
+
 	if warningCount > 0 {
+		if linter.Structured() {
+			exit(*exitCodeIssues)
+		}
 		if warningCount == 1 {
 			fmt.Printf("\n%v issue found.\n", warningCount)
 		} else {
 			fmt.Printf("\n%v issues found.\n", warningCount)
 		}
-		os.Exit(Failure)
+		exit(*exitCodeIssues)
+	}
+}
+
+// isTerminal reports whether f is a terminal, rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// exit stops the CPU profile, if one is being written, so the profile is complete, then exits with the code.
+// It is used instead of os.Exit, so no exit path leaves the profile incomplete.
+func exit(code int) {
+	pprof.StopCPUProfile()
+	os.Exit(code)
+}
+
+// writeReport writes the findings and errors when a structured output format is used.
+// If the report can't be written, the program exits with exitCodeError.
+func writeReport(l *linter.Linter, exitCodeError int) {
+	if err := l.WriteReport(); err != nil {
+		log.Printf("Error writing report: %v", err)
+		exit(exitCodeError)
+	}
+}
+
+// categoryNames returns the names of the categories, for the -min-category flag.
+func categoryNames() []string {
+	var names []string
+	for _, c := range linter.Categories() {
+		names = append(names, string(c))
+	}
+	return names
+}
+
+// severityNames returns the names of the severities, for the -fail-fast-severity flag.
+func severityNames() []string {
+	var names []string
+	for _, s := range linter.Severities() {
+		names = append(names, string(s))
 	}
+	return names
 }
@@ -0,0 +1,156 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cu-library/ezproxy-config-lint/internal/linter"
+)
+
+// linterOptions are the command line options used to make a Linter.
+// Each root of a manifest can set its own, on top of the options given on the command line.
+type linterOptions struct {
+	annotate             bool
+	highlight            bool
+	verbose              bool
+	additionalPHEChecks  bool
+	directiveCase        bool
+	labelStyle           string
+	https                bool
+	origins              bool
+	source               bool
+	sourceCompare        bool
+	sourceStrict         bool
+	whitespace           bool
+	fileReferences       bool
+	serverConfig         bool
+	groupScoped          bool
+	skeletonStanzas      bool
+	domainHosts          bool
+	implicitBoundaries   bool
+	crossSchemeOrigins   bool
+	redundantHosts       bool
+	serverHostname       string
+	pedantic             bool
+	maxStanzaHosts       int
+	maxDescriptionLength int
+	maxFileStanzas       int
+	maxFileLines         int
+	oneStanzaPerFile     bool
+	fix                  bool
+	diff                 bool
+	collapse             bool
+	backupDir            string
+	timeout              time.Duration
+	retries              int
+	userAgent            string
+	staleDays            int
+	valuesFile           string
+	communityRepo        string
+	failFast             bool
+	failFastSeverity     string
+	minCategory          string
+	followIncludeFile    bool
+	// set are the options a manifest root set, with their values, for the run's metadata.
+	set map[string]string
+}
+
+// addFlags defines the command line options used to make a Linter in the flag set.
+func (o *linterOptions) addFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.annotate, "annotate", false, "Print all lines, not just lines that create warnings.")
+	fs.BoolVar(&o.highlight, "highlight", false, "With -annotate, colorize directive labels, expand abbreviated labels, and mark the end of each stanza.")
+	fs.BoolVar(&o.verbose, "verbose", false, "Print internal state before each line is processed, and links to the documentation for directives with issues.")
+	fs.BoolVar(&o.additionalPHEChecks, "phe", false, "Perform additional checks on ProxyHostnameEdit directives.")
+	fs.BoolVar(&o.directiveCase, "case", false, "Report on directives having the wrong case.")
+	fs.StringVar(&o.labelStyle, "label-style", "", "Report on directives with abbreviated labels, like HJ, which do not use this label style, one of "+
+		strings.Join(linter.LabelStyles(), ", ")+". With -fix, the labels are replaced.")
+	fs.BoolVar(&o.https, "https", false, "Report on URL directives, and H or HJ directives with https variants, which do not use the HTTPS scheme.")
+	fs.BoolVar(&o.origins, "origins", false, "Report on duplicate origins in H or HJ directives within a stanza, and point reports of origins seen in other stanzas to the first stanza.")
+	fs.BoolVar(&o.source, "source", true, "Use source comments to check against OCLC stanzas.")
+	fs.BoolVar(&o.sourceCompare, "source-compare", false, "Report stanzas whose lines differ from the stanza on their Source page, ignoring comments, whitespace, and the order of lines.")
+	fs.BoolVar(&o.sourceStrict, "source-strict", false, "Report Source pages which couldn't be fetched or read as errors, instead of informational findings.")
+	fs.BoolVar(&o.whitespace, "whitespace", false, "Report on trailing space or tab characters.")
+	fs.BoolVar(&o.fileReferences, "files", false, "Report on directives which reference local files that do not exist.")
+	fs.BoolVar(&o.serverConfig, "server-config", false, "Check that the file is a complete config.txt, with essential server directives before the first stanza, and a RunAs directive when listening on privileged ports.")
+	fs.BoolVar(&o.groupScoped, "group-scoped", false, "Only report duplicate titles and origins from earlier stanzas in the same Group.")
+	fs.BoolVar(&o.skeletonStanzas, "skeleton-stanzas", false, "Report on stanzas which only have Title and URL directives.")
+	fs.BoolVar(&o.domainHosts, "domain-hosts", false, "Suggest replacing D or DJ directives with H or HJ directives for the hostnames the stanza uses.")
+	fs.BoolVar(&o.implicitBoundaries, "implicit-boundaries", false, "Also end a stanza at a header comment like \"# --- JSTOR ---\" or a Title directive after a URL directive, for configs without blank lines between stanzas.")
+	fs.BoolVar(&o.crossSchemeOrigins, "cross-scheme-origins", false, "Report origins which differ from an origin in another stanza only by their scheme, like http://www.jstor.org and https://www.jstor.org.")
+	fs.BoolVar(&o.redundantHosts, "redundant-hosts", false, "Report on H, HJ, D, or DJ directives made redundant by other directives in the same stanza.")
+	fs.StringVar(&o.serverHostname, "server-hostname", "", "Report on stanzas which proxy this hostname of the EZproxy server, in addition to the hostname in the Name directive.")
+	fs.BoolVar(&o.pedantic, "pedantic", false, "Report on pedantic style issues, like URLs which are not normalized.")
+	fs.IntVar(&o.maxStanzaHosts, "max-stanza-hosts", 50, "With -pedantic, report stanzas with more than this many H, HJ, D, or DJ directives. Zero disables the check.")
+	fs.IntVar(&o.maxDescriptionLength, "max-description-length", 255, "With -pedantic, report Description directives longer than this many characters. Zero disables the check.")
+	fs.IntVar(&o.maxFileStanzas, "max-file-stanzas", 0, "With -pedantic, report files with more than this many stanzas. Zero disables the check.")
+	fs.IntVar(&o.maxFileLines, "max-file-lines", 0, "With -pedantic, report files with more than this many lines. Zero disables the check.")
+	fs.BoolVar(&o.oneStanzaPerFile, "one-stanza-per-file", false, "Report files read with IncludeFile which don't have exactly one stanza, "+
+		"or whose name doesn't match the slug of the stanza's title.")
+	fs.BoolVar(&o.fix, "fix", false, "Fix issues where possible, rewriting the files in place.")
+	fs.BoolVar(&o.diff, "diff", false, "With -fix, print the fixes as a unified diff for review instead of rewriting the files.")
+	fs.BoolVar(&o.collapse, "collapse", false, "Collapse repeated findings in a file with the same code and message into one finding, with a count and the list of lines.")
+	fs.StringVar(&o.backupDir, "backup-dir", "", "With -fix, copy each file to a timestamped backup in this directory before rewriting it, so the fixes can be undone with the revert command.")
+	fs.DurationVar(&o.timeout, "timeout", linter.OCLCHTTPTimeout, "The timeout for each network request, like fetching Source pages. "+
+		"Proxies are set with the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.")
+	fs.IntVar(&o.retries, "retries", 0, "The number of times to retry network requests which fail.")
+	fs.StringVar(&o.userAgent, "user-agent", linter.DefaultUserAgent+"/"+version, "The User-Agent header sent with network requests.")
+	fs.IntVar(&o.staleDays, "stale-days", 0, "Report stanzas whose latest \"# Updated:\" or \"# Reviewed:\" comment is older than this many days. Zero disables the check.")
+	fs.StringVar(&o.valuesFile, "values", "", "A JSON file with the values of template placeholders like {{VAR}} or ${VAR}, which are replaced before linting. Files are not fixed with -fix.")
+	fs.StringVar(&o.communityRepo, "community-repo", "", "Compare stanzas to the matching stanzas in a community stanza repository, "+
+		"which can be the URL of a Git repository or a local directory.")
+	fs.BoolVar(&o.failFast, "fail-fast", false, "Stop processing at the first issue at or above the -fail-fast-severity.")
+	fs.StringVar(&o.failFastSeverity, "fail-fast-severity", string(linter.SeverityWarning), "The severity of issues which stop processing when -fail-fast is used, one of "+
+		strings.Join(severityNames(), ", ")+".")
+	fs.StringVar(&o.minCategory, "min-category", "", "Only report issues in this category or a more important one, one of "+
+		strings.Join(categoryNames(), ", ")+", from least to most important.")
+	fs.BoolVar(&o.followIncludeFile, "follow-includefile", true, "Also process files referenced by IncludeFile directives.")
+}
+
+// validate checks the options which only have some valid values, and the options which only work together.
+func (o linterOptions) validate(format string) error {
+	if o.diff && (format != linter.FormatText || !o.fix) {
+		return fmt.Errorf("the -diff option can only be used with -fix and the %q output format", linter.FormatText)
+	}
+	if o.labelStyle != "" && !slices.Contains(linter.LabelStyles(), o.labelStyle) {
+		return fmt.Errorf("unknown label style %q, should be one of %v", o.labelStyle, strings.Join(linter.LabelStyles(), ", "))
+	}
+	if o.minCategory != "" && !slices.Contains(categoryNames(), o.minCategory) {
+		return fmt.Errorf("unknown category %q, should be one of %v", o.minCategory, strings.Join(categoryNames(), ", "))
+	}
+	if !slices.Contains(severityNames(), o.failFastSeverity) {
+		return fmt.Errorf("unknown severity %q, should be one of %v", o.failFastSeverity, strings.Join(severityNames(), ", "))
+	}
+	return nil
+}
+
+// forRoot returns the options of a manifest root, which are the options given on the command line,
+// with the root's options on top. Only the options used to make a Linter can be set for a root, since
+// the others, like -format or -cpuprofile, are read once for the whole run.
+func (o linterOptions) forRoot(options map[string]string, format string) (linterOptions, error) {
+	fs := flag.NewFlagSet("root", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	root := linterOptions{}
+	root.addFlags(fs)
+	// The flags point to the fields of root, so they start from the command line's values.
+	root = o
+	root.set = map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		if fs.Lookup(name) == nil {
+			return root, fmt.Errorf("option %q can't be set for a root", name)
+		}
+		if err := fs.Set(name, options[name]); err != nil {
+			return root, fmt.Errorf("invalid value %q for option %q: %w", options[name], name, err)
+		}
+		root.set[name] = fs.Lookup(name).Value.String()
+	}
+	return root, root.validate(format)
+}
@@ -0,0 +1,62 @@
+// Copyright Carleton University Library All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/cu-library/ezproxy-config-lint/internal/linter"
+)
+
+func TestManifestRootOptions(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	options := linterOptions{}
+	options.addFlags(fs)
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	if expected := slices.Sorted(slices.Values(linter.ManifestRootOptions())); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("the options used to make a linter %q don't match the manifest root options %q", names, expected)
+	}
+}
+
+func TestForRoot(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	options := linterOptions{}
+	options.addFlags(fs)
+	if err := fs.Parse([]string{"-https", "-max-stanza-hosts", "30"}); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := options.forRoot(map[string]string{"pedantic": "true", "max-stanza-hosts": "20"}, linter.FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !root.https || !root.pedantic || root.maxStanzaHosts != 20 {
+		t.Fatalf("incorrect root options %+v", root)
+	}
+	if options.pedantic || options.maxStanzaHosts != 30 {
+		t.Fatalf("the command line options were changed by the root's options: %+v", options)
+	}
+	if expected := map[string]string{"pedantic": "true", "max-stanza-hosts": "20"}; !reflect.DeepEqual(root.set, expected) {
+		t.Fatalf("incorrect set options %q instead of %q", root.set, expected)
+	}
+
+	for _, rootOptions := range []map[string]string{
+		{"format": "json"},
+		{"cpuprofile": "cpu.out"},
+		{"max-stanza-hosts": "many"},
+		{"label-style": "shouting"},
+		{"diff": "true"},
+	} {
+		if _, err := options.forRoot(rootOptions, linter.FormatText); err == nil {
+			t.Fatalf("expected an error for the root options %q", rootOptions)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"flag"
 	"io"
 	"os"
@@ -20,12 +21,17 @@ func NewLinter() *linter.Linter {
 }
 
 type testOpts struct {
-	Name    string
-	Case    bool
-	Fail    bool
-	HTTPS   bool
-	Origins bool
-	PHE     bool
+	Name      string
+	Dir       string // The directory in testdata, if it isn't the Name.
+	Golden    string // The suffix of the golden fixtures, if it isn't ".golden".
+	Case      bool
+	Fail      bool
+	HTTPS     bool
+	Origins   bool
+	PHE       bool
+	Pedantic  bool
+	Redundant bool
+	Skeleton  bool
 }
 
 func TestDataFiles(t *testing.T) {
@@ -35,7 +41,12 @@ func TestDataFiles(t *testing.T) {
 		{Name: "invalid_case", Fail: true, Case: true},
 		{Name: "invalid_https", Fail: true, HTTPS: true},
 		{Name: "invalid_origins", Fail: true, Origins: true},
+		{Name: "invalid_cross_stanza_origins", Fail: true},
+		{Name: "invalid_cross_stanza_origins_with_origins", Dir: "invalid_cross_stanza_origins", Golden: ".origins.golden", Fail: true, Origins: true},
 		{Name: "invalid_phe", Fail: true, PHE: true},
+		{Name: "invalid_redundant_hosts", Fail: true, Redundant: true},
+		{Name: "invalid_pedantic", Fail: true, Pedantic: true},
+		{Name: "invalid_skeleton", Fail: true, Skeleton: true},
 	}
 
 	// Disable colors for these tests.
@@ -52,7 +63,7 @@ func TestDataFiles(t *testing.T) {
 }
 
 func runDataFileTest(t *testing.T, o testOpts) {
-	root := filepath.Join("testdata", o.Name)
+	root := filepath.Join("testdata", cmp.Or(o.Dir, o.Name))
 	dirContent, err := filepath.Glob(filepath.Join(root, "*.txt"))
 	if err != nil {
 		panic(err)
@@ -64,6 +75,9 @@ func runDataFileTest(t *testing.T, o testOpts) {
 		l.HTTPS = o.HTTPS
 		l.Origins = o.Origins
 		l.AdditionalPHEChecks = o.PHE
+		l.RedundantHosts = o.Redundant
+		l.Pedantic = o.Pedantic
+		l.SkeletonStanzas = o.Skeleton
 
 		buf := bytes.NewBuffer(nil)
 		l.Output = buf
@@ -71,7 +85,7 @@ func runDataFileTest(t *testing.T, o testOpts) {
 		warningCount, err := l.ProcessFile(f)
 
 		if o.Fail {
-			golden := f + ".golden"
+			golden := f + cmp.Or(o.Golden, ".golden")
 			if *update {
 				err := os.WriteFile(golden, buf.Bytes(), 0644) //nolint:gosec
 				if err != nil {